@@ -0,0 +1,5 @@
+// Package paymentsv1 holds the gRPC contract of the payments API, generated
+// from payments.proto. Internal services import it for the client stubs.
+package paymentsv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative api/payments/v1/payments.proto
@@ -1,28 +1,73 @@
 package main
 
 import (
+	"bufio"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/auth"
 	"github.com/lmtani/rinha-de-backend-2025/internal/admin/handler"
+	"github.com/lmtani/rinha-de-backend-2025/internal/processorspec"
 )
 
 func main() {
+	hashPassword := flag.Bool("hash-password", false, "read a password from stdin and print a user:hash line for ADMIN_PASSWORD_FILE")
+	flag.Parse()
+
+	if *hashPassword {
+		printPasswordHash()
+		return
+	}
+
 	// Get configuration from environment variables
-	defaultProcessorURL := os.Getenv("PROCESSOR_DEFAULT_URL")
-	if defaultProcessorURL == "" {
-		defaultProcessorURL = "http://localhost:8001"
+	processors, err := loadProcessors()
+	if err != nil {
+		log.Fatalf("PROCESSORS: %v", err)
 	}
 
-	fallbackProcessorURL := os.Getenv("PROCESSOR_FALLBACK_URL")
-	if fallbackProcessorURL == "" {
-		fallbackProcessorURL = "http://localhost:8002"
+	apiURL := os.Getenv("API_URL")
+	if apiURL == "" {
+		apiURL = "http://localhost:9999"
 	}
 
 	adminToken := os.Getenv("ADMIN_TOKEN")
 	if adminToken == "" {
-		adminToken = "123" // Default token
+		log.Fatal("ADMIN_TOKEN (the processors' admin token) is required")
+	}
+
+	credentials, err := loadCredentials()
+	if err != nil {
+		log.Fatalf("Failed to load admin credentials: %v", err)
+	}
+
+	apiTokens, err := auth.ParseAPITokens(os.Getenv("ADMIN_API_TOKENS"))
+	if err != nil {
+		log.Fatalf("ADMIN_API_TOKENS: %v", err)
+	}
+
+	sessionTTL := 8 * time.Hour
+	if v := os.Getenv("ADMIN_SESSION_TTL"); v != "" {
+		if sessionTTL, err = time.ParseDuration(v); err != nil || sessionTTL <= 0 {
+			log.Fatalf("ADMIN_SESSION_TTL: %q is not a valid positive duration", v)
+		}
+	}
+
+	secureCookie := false
+	if v := os.Getenv("ADMIN_SECURE_COOKIE"); v != "" {
+		if secureCookie, err = strconv.ParseBool(v); err != nil {
+			log.Fatalf("ADMIN_SECURE_COOKIE: %q is not a valid bool", v)
+		}
+	}
+
+	scenariosDir := os.Getenv("CHAOS_SCENARIOS_DIR")
+	if scenariosDir == "" {
+		scenariosDir = "scenarios"
 	}
 
 	port := os.Getenv("ADMIN_PORT")
@@ -37,18 +82,105 @@ func main() {
 	r.Use(gin.Recovery())
 
 	// Create admin handler
-	adminHandler := handler.NewAdminHandler(defaultProcessorURL, fallbackProcessorURL, adminToken)
+	authenticator := auth.NewAuthenticator(credentials, auth.NewSessionStore(sessionTTL), secureCookie)
+	adminHandler, err := handler.NewAdminHandler(handler.Config{
+		Processors:      processors,
+		APIURL:          apiURL,
+		APIControlToken: os.Getenv("API_CONTROL_TOKEN"),
+		APIKey:          os.Getenv("API_KEY"),
+		Token:           adminToken,
+		ScenariosDir:    scenariosDir,
+		Auth:            authenticator,
+		APITokens:       apiTokens,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create admin handler: %v", err)
+	}
 
 	// Register routes
 	adminHandler.RegisterRoutes(r)
 
 	// Start server
 	log.Printf("Starting admin server on %s", port)
-	log.Printf("Default Processor: %s", defaultProcessorURL)
-	log.Printf("Fallback Processor: %s", fallbackProcessorURL)
-	log.Printf("Admin Token: %s", adminToken)
+	for _, p := range processors {
+		log.Printf("Processor %s: %s", p.Name, p.URL)
+	}
+	log.Printf("Payments API: %s", apiURL)
+	log.Printf("Admin users: %d, API tokens: %d", credentials.Len(), len(apiTokens))
 
 	if err := r.Run(port); err != nil {
 		log.Fatal("Failed to start admin server:", err)
 	}
 }
+
+// loadProcessors reads the processors from PROCESSORS ("name=url,...") or,
+// when unset, from PROCESSOR_DEFAULT_URL and PROCESSOR_FALLBACK_URL
+func loadProcessors() ([]processorspec.Processor, error) {
+	if spec := os.Getenv("PROCESSORS"); spec != "" {
+		return processorspec.Parse(spec)
+	}
+
+	defaultURL := os.Getenv("PROCESSOR_DEFAULT_URL")
+	if defaultURL == "" {
+		defaultURL = "http://localhost:8001"
+	}
+	fallbackURL := os.Getenv("PROCESSOR_FALLBACK_URL")
+	if fallbackURL == "" {
+		fallbackURL = "http://localhost:8002"
+	}
+	return []processorspec.Processor{
+		{Name: "default", URL: defaultURL},
+		{Name: "fallback", URL: fallbackURL},
+	}, nil
+}
+
+// loadCredentials reads the admin users from ADMIN_PASSWORD_FILE and from
+// ADMIN_USER/ADMIN_PASSWORD. At least one user is required.
+func loadCredentials() (*auth.Credentials, error) {
+	credentials := auth.NewCredentials()
+
+	if path := os.Getenv("ADMIN_PASSWORD_FILE"); path != "" {
+		if err := auth.LoadPasswordFile(path, credentials); err != nil {
+			return nil, err
+		}
+	}
+
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		user := os.Getenv("ADMIN_USER")
+		if user == "" {
+			user = "admin"
+		}
+		if err := credentials.AddPassword(user, password); err != nil {
+			return nil, err
+		}
+	}
+
+	if credentials.Len() == 0 {
+		return nil, fmt.Errorf("set ADMIN_PASSWORD or ADMIN_PASSWORD_FILE")
+	}
+	return credentials, nil
+}
+
+// printPasswordHash prints a password file line for the password read from stdin
+func printPasswordHash() {
+	user := os.Getenv("ADMIN_USER")
+	if user == "" {
+		user = "admin"
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		log.Fatalf("Failed to read password: %v", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		log.Fatal("Password must not be empty")
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s:%s\n", user, hash)
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+)
+
+const usage = `Usage: adminctl [flags] <command> [args]
+
+Commands:
+  processors                      list processors and whether they respond
+  add      <name> <url> [token]   manage another processor (token defaults to the admin app's)
+  remove   <name>                 stop managing a processor
+  summary  [processor] [from to]  payments summary (processor defaults to all, times are RFC3339)
+  token    <processor> <token>    change the processor admin token
+  delay    <processor> <ms>       set the payment delay
+  failure  <processor> <bool>     toggle failure mode
+  purge    <processor>            delete the processor's payments
+  reset    [processor]            failure off, delay 0 and purge (defaults to all)
+
+<processor> is a processor name, e.g. default or fallback, or all.
+
+Flags:
+`
+
+func main() {
+	adminURL := flag.String("url", getEnv("ADMIN_URL", "http://localhost:8081"), "admin app base URL")
+	token := flag.String("token", os.Getenv("ADMIN_API_TOKEN"), "admin JSON API bearer token")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for the whole command")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	admin := client.NewAdminClient(*adminURL, *token)
+	out, err := run(ctx, admin, args[0], args[1:])
+	if out != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(out)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "adminctl: %v\n", err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("invalid usage, see adminctl -h")
+
+// run executes a command and returns what to print
+func run(ctx context.Context, admin *client.AdminClient, command string, args []string) (interface{}, error) {
+	switch command {
+	case "processors":
+		if len(args) != 0 {
+			return nil, errUsage
+		}
+		return listProcessors(ctx, admin)
+
+	case "add":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, errUsage
+		}
+		token := ""
+		if len(args) == 3 {
+			token = args[2]
+		}
+		if err := admin.AddProcessor(ctx, args[0], args[1], token); err != nil {
+			return nil, err
+		}
+		return listProcessors(ctx, admin)
+
+	case "remove":
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		if err := admin.RemoveProcessor(ctx, args[0]); err != nil {
+			return nil, err
+		}
+		return listProcessors(ctx, admin)
+
+	case "summary":
+		name := "all"
+		if len(args) == 1 || len(args) == 3 {
+			name = args[0]
+			args = args[1:]
+		}
+		var from, to *time.Time
+		if len(args) == 2 {
+			f, err := time.Parse(time.RFC3339, args[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid from: %w", err)
+			}
+			t, err := time.Parse(time.RFC3339, args[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid to: %w", err)
+			}
+			from, to = &f, &t
+		} else if len(args) != 0 {
+			return nil, errUsage
+		}
+		return result(admin.Summary(ctx, name, from, to))
+
+	case "token":
+		if len(args) != 2 {
+			return nil, errUsage
+		}
+		return result(admin.SetToken(ctx, args[0], args[1]))
+
+	case "delay":
+		if len(args) != 2 {
+			return nil, errUsage
+		}
+		delay, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid delay %q: %w", args[1], err)
+		}
+		return result(admin.SetDelay(ctx, args[0], delay))
+
+	case "failure":
+		if len(args) != 2 {
+			return nil, errUsage
+		}
+		failure, err := strconv.ParseBool(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid failure %q: %w", args[1], err)
+		}
+		return result(admin.SetFailure(ctx, args[0], failure))
+
+	case "purge":
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		return result(admin.Purge(ctx, args[0]))
+
+	case "reset":
+		name := "all"
+		if len(args) == 1 {
+			name = args[0]
+		} else if len(args) != 0 {
+			return nil, errUsage
+		}
+		return reset(ctx, admin, name)
+
+	default:
+		return nil, errUsage
+	}
+}
+
+// listProcessors returns the processor list, or nil when it failed
+func listProcessors(ctx context.Context, admin *client.AdminClient) (interface{}, error) {
+	processors, err := admin.Processors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return processors, nil
+}
+
+// reset turns failure off, sets the delay to 0 and purges payments, stopping
+// at the first step that fails
+func reset(ctx context.Context, admin *client.AdminClient, name string) (interface{}, error) {
+	steps := []func() (*client.AdminResponse, error){
+		func() (*client.AdminResponse, error) { return admin.SetFailure(ctx, name, false) },
+		func() (*client.AdminResponse, error) { return admin.SetDelay(ctx, name, 0) },
+		func() (*client.AdminResponse, error) { return admin.Purge(ctx, name) },
+	}
+
+	var results []client.AdminResult
+	for _, step := range steps {
+		response, err := step()
+		if response != nil {
+			results = append(results, response.Results...)
+		}
+		if err != nil {
+			return client.AdminResponse{Results: results}, err
+		}
+	}
+	return client.AdminResponse{Results: results}, nil
+}
+
+// result adapts an action's return values, keeping partial results printable
+func result(response *client.AdminResponse, err error) (interface{}, error) {
+	if response == nil {
+		return nil, err
+	}
+	return response, err
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -2,26 +2,70 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/app"
+	"github.com/lmtani/rinha-de-backend-2025/internal/config"
 )
 
 func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration (secrets redacted) and exit")
+	flag.Parse()
+
+	// Load and validate configuration
+	cfg, err := config.LoadFile(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(cfg, flag.Args()[1:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if *printConfig {
+		out, err := cfg.YAML()
+		if err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		os.Stdout.Write(out)
+		return
+	}
+
 	// Create dependency injection container
-	container := NewContainer()
+	container, err := app.NewContainer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize application: %v", err)
+	}
 
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Start background services
-	container.Start(ctx)
+	if err := container.Start(ctx); err != nil {
+		log.Fatalf("Failed to start background services: %v", err)
+	}
 
 	// Setup graceful shutdown
 	setupGracefulShutdown(cancel, container)
 
+	// Start gRPC server next to the HTTP server
+	if container.GRPCServer != nil {
+		go func() {
+			if err := container.GRPCServer.Start(); err != nil {
+				log.Fatal("Failed to start gRPC server:", err)
+			}
+		}()
+	}
+
 	// Start HTTP server
 	log.Printf("Starting payment service on %s", container.Config.Server.Port)
 	if err := container.HTTPServer.Start(); err != nil {
@@ -29,7 +73,7 @@ func main() {
 	}
 }
 
-func setupGracefulShutdown(cancel context.CancelFunc, container *Container) {
+func setupGracefulShutdown(cancel context.CancelFunc, container *app.Container) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
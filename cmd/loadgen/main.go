@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+	"github.com/lmtani/rinha-de-backend-2025/internal/loadgen"
+	"github.com/lmtani/rinha-de-backend-2025/internal/processorspec"
+)
+
+// eventFlags collects repeated -event flags
+type eventFlags []string
+
+func (e *eventFlags) String() string     { return strings.Join(*e, ",") }
+func (e *eventFlags) Set(v string) error { *e = append(*e, v); return nil }
+
+func main() {
+	var events eventFlags
+
+	target := flag.String("target", "http://localhost:9999", "base URL of the payments API")
+	apiKey := flag.String("api-key", getEnv("API_KEY", ""), "API key sent as X-API-Key when the API requires authentication")
+	stagesSpec := flag.String("stages", "10s:50,30s:200,10s:0", "comma separated duration:rate ramp stages")
+	maxInFlight := flag.Int("max-in-flight", 256, "maximum concurrent payment requests")
+	timeout := flag.Duration("timeout", 5*time.Second, "timeout for each HTTP request")
+	pollInterval := flag.Duration("poll-interval", time.Second, "summary polling interval during the run (0 disables)")
+	settle := flag.Duration("settle", 30*time.Second, "how long to wait for the final summary to match")
+	amount := flag.Float64("amount", 19.90, "amount of synthesized payments")
+	replay := flag.String("replay", "", "newline delimited JSON file of payments to replay (amounts are reused, correlation IDs are regenerated)")
+	defaultURL := flag.String("default-processor", getEnv("PROCESSOR_DEFAULT_URL", "http://localhost:8001"), "default processor URL for -event toggles")
+	fallbackURL := flag.String("fallback-processor", getEnv("PROCESSOR_FALLBACK_URL", "http://localhost:8002"), "fallback processor URL for -event toggles")
+	processorsSpec := flag.String("processors", getEnv("PROCESSORS", ""), "comma separated name=url processors for -event toggles, instead of -default-processor and -fallback-processor")
+	adminToken := flag.String("admin-token", getEnv("ADMIN_TOKEN", ""), "processor admin token for -event toggles")
+	adminURL := flag.String("admin-url", getEnv("ADMIN_URL", ""), "apply -event toggles through the admin app's JSON API at this URL instead of calling the processors")
+	adminAPIToken := flag.String("admin-api-token", getEnv("ADMIN_API_TOKEN", ""), "admin JSON API bearer token, used with -admin-url")
+	flag.Var(&events, "event", "processor toggle as offset:processor:key=value, e.g. 10s:default:failure=true (repeatable)")
+	flag.Parse()
+
+	stages, err := loadgen.ParseStages(*stagesSpec)
+	if err != nil {
+		log.Fatalf("Invalid stages: %v", err)
+	}
+
+	cfg := loadgen.Config{
+		TargetURL:    *target,
+		APIKey:       *apiKey,
+		Stages:       stages,
+		MaxInFlight:  *maxInFlight,
+		Timeout:      *timeout,
+		PollInterval: *pollInterval,
+		SettleTime:   *settle,
+		Amount:       *amount,
+	}
+
+	if *replay != "" {
+		cfg.Replay, err = loadgen.LoadReplayFile(*replay)
+		if err != nil {
+			log.Fatalf("Failed to load replay file: %v", err)
+		}
+	}
+
+	processors := []processorspec.Processor{
+		{Name: "default", URL: *defaultURL},
+		{Name: "fallback", URL: *fallbackURL},
+	}
+	if *processorsSpec != "" {
+		if processors, err = processorspec.Parse(*processorsSpec); err != nil {
+			log.Fatalf("Invalid processors: %v", err)
+		}
+	}
+	names := make([]string, len(processors))
+	for i, p := range processors {
+		names[i] = p.Name
+	}
+
+	for _, spec := range events {
+		event, err := loadgen.ParseEvent(spec, names)
+		if err != nil {
+			log.Fatalf("Invalid event: %v", err)
+		}
+		cfg.Events = append(cfg.Events, event)
+	}
+
+	switch {
+	case len(cfg.Events) == 0:
+	case *adminURL != "":
+		admin := client.NewAdminClient(*adminURL, *adminAPIToken)
+		cfg.Processors = make(map[string]loadgen.Processor, len(processors))
+		for _, p := range processors {
+			cfg.Processors[p.Name] = admin.Processor(p.Name)
+		}
+	case *adminToken == "":
+		log.Fatal("-admin-token (or ADMIN_TOKEN) or -admin-url is required when -event is used")
+	default:
+		cfg.Processors = make(map[string]loadgen.Processor, len(processors))
+		for _, p := range processors {
+			cfg.Processors[p.Name] = client.NewProcessorClient(p.URL, *adminToken)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	log.Printf("Running load against %s for %s", cfg.TargetURL, loadgen.TotalDuration(stages))
+	report, err := loadgen.NewRunner(cfg).Run(ctx)
+	if err != nil {
+		log.Fatalf("Load generation failed: %v", err)
+	}
+
+	report.Print(os.Stdout)
+	if !report.SummaryCheck.Matches() {
+		fmt.Fprintln(os.Stderr, "final summary does not match accepted payments")
+		os.Exit(1)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,164 @@
+package grpc_server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	paymentsv1 "github.com/lmtani/rinha-de-backend-2025/api/payments/v1"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// batchStatuses maps the batch item statuses to their gRPC enum
+var batchStatuses = map[domain.BatchItemStatus]paymentsv1.PaymentResult_Status{
+	domain.BatchItemAccepted:  paymentsv1.PaymentResult_STATUS_ACCEPTED,
+	domain.BatchItemDuplicate: paymentsv1.PaymentResult_STATUS_DUPLICATE,
+	domain.BatchItemInvalid:   paymentsv1.PaymentResult_STATUS_INVALID,
+	domain.BatchItemFailed:    paymentsv1.PaymentResult_STATUS_FAILED,
+}
+
+// SubmitPayment queues one payment. It goes through the batch path, which
+// tells duplicates apart from invalid payments and unavailable queues.
+func (s *Server) SubmitPayment(ctx context.Context, req *paymentsv1.SubmitPaymentRequest) (*paymentsv1.SubmitPaymentResponse, error) {
+	if req.GetPayment() == nil {
+		return nil, status.Error(codes.InvalidArgument, "payment is required")
+	}
+
+	results, err := s.requestPayment.ExecuteBatch(ctx, []domain.Payment{toPayment(ctx, req.GetPayment())})
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	switch result := results[0]; result.Status {
+	case domain.BatchItemAccepted:
+		return &paymentsv1.SubmitPaymentResponse{}, nil
+	case domain.BatchItemDuplicate:
+		return nil, status.Errorf(codes.AlreadyExists, "payment %s was already requested", result.CorrelationId)
+	case domain.BatchItemInvalid:
+		return nil, status.Error(codes.InvalidArgument, result.Error)
+	default:
+		return nil, status.Error(codes.Unavailable, result.Error)
+	}
+}
+
+// SubmitPayments queues a batch of payments, with a result per payment
+func (s *Server) SubmitPayments(ctx context.Context, req *paymentsv1.SubmitPaymentsRequest) (*paymentsv1.SubmitPaymentsResponse, error) {
+	payments := make([]domain.Payment, len(req.GetPayments()))
+	for i, payment := range req.GetPayments() {
+		payments[i] = toPayment(ctx, payment)
+	}
+
+	results, err := s.requestPayment.ExecuteBatch(ctx, payments)
+	if errors.Is(err, usecase.ErrBatchTooLarge) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	resp := &paymentsv1.SubmitPaymentsResponse{Results: make([]*paymentsv1.PaymentResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = &paymentsv1.PaymentResult{
+			CorrelationId: result.CorrelationId,
+			Status:        batchStatuses[result.Status],
+			Error:         result.Error,
+		}
+	}
+	return resp, nil
+}
+
+// GetSummary returns the processed payments per processor, optionally
+// bounded by from and to
+func (s *Server) GetSummary(ctx context.Context, req *paymentsv1.GetSummaryRequest) (*paymentsv1.GetSummaryResponse, error) {
+	from, err := optionalTime(req.GetFrom(), "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := optionalTime(req.GetTo(), "to")
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.auditPayments.Execute(ctx, from, to)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &paymentsv1.GetSummaryResponse{Channels: make(map[string]*paymentsv1.ChannelSummary)}
+	for channel, stats := range summary.Channels() {
+		channelSummary := &paymentsv1.ChannelSummary{
+			TotalRequests: int64(stats.TotalRequests),
+			TotalAmount:   stats.TotalAmount,
+		}
+		for currency, currencyStats := range stats.ByCurrency {
+			if channelSummary.ByCurrency == nil {
+				channelSummary.ByCurrency = make(map[string]*paymentsv1.CurrencySummary)
+			}
+			channelSummary.ByCurrency[currency.String()] = &paymentsv1.CurrencySummary{
+				TotalRequests: int64(currencyStats.TotalRequests),
+				TotalAmount:   currencyStats.TotalAmount,
+			}
+		}
+		resp.Channels[channel.String()] = channelSummary
+	}
+	return resp, nil
+}
+
+// GetPaymentStatus tells whether a payment is pending, processed or dead-lettered
+func (s *Server) GetPaymentStatus(ctx context.Context, req *paymentsv1.GetPaymentStatusRequest) (*paymentsv1.GetPaymentStatusResponse, error) {
+	if req.GetCorrelationId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "correlation ID is required")
+	}
+
+	paymentStatus, record, err := s.auditPayments.Status(ctx, req.GetCorrelationId())
+	if errors.Is(err, usecase.ErrPaymentNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	switch paymentStatus {
+	case domain.PaymentPending:
+		return &paymentsv1.GetPaymentStatusResponse{Status: paymentsv1.GetPaymentStatusResponse_STATUS_PENDING}, nil
+	case domain.PaymentDeadLettered:
+		return &paymentsv1.GetPaymentStatusResponse{Status: paymentsv1.GetPaymentStatusResponse_STATUS_DEAD_LETTERED}, nil
+	}
+	return &paymentsv1.GetPaymentStatusResponse{
+		Status: paymentsv1.GetPaymentStatusResponse_STATUS_PROCESSED,
+		Payment: &paymentsv1.Payment{
+			CorrelationId: record.CorrelationId,
+			Amount:        record.Amount,
+			Currency:      record.CurrencyOrDefault().String(),
+		},
+		Channel:     record.Channel.String(),
+		ProcessedAt: timestamppb.New(record.ProcessedAt),
+	}, nil
+}
+
+// toPayment converts a gRPC payment, requested by the authenticated client
+func toPayment(ctx context.Context, payment *paymentsv1.Payment) domain.Payment {
+	return domain.Payment{
+		CorrelationId: payment.GetCorrelationId(),
+		Amount:        payment.GetAmount(),
+		Currency:      domain.Currency(payment.GetCurrency()),
+		Client:        clientFrom(ctx),
+		CallbackURL:   payment.GetCallbackUrl(),
+	}
+}
+
+// optionalTime converts an optional timestamp, nil when unset
+func optionalTime(ts *timestamppb.Timestamp, name string) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid '%s' timestamp: %v", name, err)
+	}
+	t := ts.AsTime()
+	return &t, nil
+}
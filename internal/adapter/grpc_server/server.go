@@ -0,0 +1,152 @@
+package grpc_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strconv"
+
+	paymentsv1 "github.com/lmtani/rinha-de-backend-2025/api/payments/v1"
+	"github.com/lmtani/rinha-de-backend-2025/internal/config"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyMetadata carries the client's API key, like the X-API-Key header
+const apiKeyMetadata = "x-api-key"
+
+// clientKey is the context key holding the authenticated client name
+type clientKey struct{}
+
+// Server serves the payments API over gRPC with the use cases of the HTTP server
+type Server struct {
+	paymentsv1.UnimplementedPaymentServiceServer
+
+	requestPayment *usecase.RequestPaymentUseCase
+	auditPayments  *usecase.AuditPaymentsUseCase
+	limitRate      *usecase.RateLimitUseCase
+	authenticate   *usecase.AuthenticateUseCase
+	server         *grpc.Server
+	config         *config.Config
+}
+
+// NewServer creates a new gRPC server
+func NewServer(
+	requestPayment *usecase.RequestPaymentUseCase,
+	auditPayments *usecase.AuditPaymentsUseCase,
+	limitRate *usecase.RateLimitUseCase,
+	authenticate *usecase.AuthenticateUseCase,
+	cfg *config.Config,
+) *Server {
+	s := &Server{
+		requestPayment: requestPayment,
+		auditPayments:  auditPayments,
+		limitRate:      limitRate,
+		authenticate:   authenticate,
+		config:         cfg,
+	}
+
+	s.server = grpc.NewServer(grpc.ChainUnaryInterceptor(s.requireAPIKey, s.rateLimit))
+	paymentsv1.RegisterPaymentServiceServer(s.server, s)
+	return s
+}
+
+// Start listens on the gRPC port and serves until Stop is called
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", s.config.GRPC.Port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.GRPC.Port, err)
+	}
+
+	log.Printf("gRPC server listening on %s", s.config.GRPC.Port)
+	return s.Serve(lis)
+}
+
+// Serve serves gRPC calls on lis until Stop is called
+func (s *Server) Serve(lis net.Listener) error {
+	if err := s.server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Stop stops accepting calls and waits for the running ones to finish
+func (s *Server) Stop() {
+	s.server.GracefulStop()
+}
+
+// requireAPIKey authenticates the client by API key when authentication is
+// enabled and stores the client name in the context. Calls cannot be
+// signed, so they are rejected when signatures are required.
+func (s *Server) requireAPIKey(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !s.authenticate.Enabled() {
+		return handler(ctx, req)
+	}
+
+	var secret string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(apiKeyMetadata); len(values) > 0 {
+			secret = values[0]
+		}
+	}
+
+	key, err := s.authenticate.Authenticate(ctx, secret, usecase.AuthRequest{})
+	if errors.Is(err, usecase.ErrUnauthenticated) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		fmt.Printf("[%s] Authentication unavailable: %v\n", s.config.Server.InstanceID, err)
+		return nil, status.Error(codes.Unavailable, "authentication unavailable")
+	}
+
+	return handler(context.WithValue(ctx, clientKey{}, key.Client), req)
+}
+
+// rateLimit takes a token for the caller of the submit methods and rejects
+// the call with RESOURCE_EXHAUSTED when the bucket is empty. Limiter
+// failures let the call through.
+func (s *Server) rateLimit(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	limited := info.FullMethod == paymentsv1.PaymentService_SubmitPayment_FullMethodName ||
+		info.FullMethod == paymentsv1.PaymentService_SubmitPayments_FullMethodName
+	if !limited || !s.limitRate.Enabled() {
+		return handler(ctx, req)
+	}
+
+	decision, err := s.limitRate.Allow(ctx, clientFrom(ctx), peerIP(ctx))
+	if err != nil {
+		fmt.Printf("[%s] Rate limiter unavailable, allowing request: %v\n", s.config.Server.InstanceID, err)
+		return handler(ctx, req)
+	}
+	if !decision.Allowed {
+		retryAfter := strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds())))
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+		return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %ss", retryAfter)
+	}
+	return handler(ctx, req)
+}
+
+// clientFrom returns the authenticated client name, empty when anonymous
+func clientFrom(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// peerIP returns the IP address of the caller
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -2,43 +2,64 @@ package http_client
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
 	"github.com/sony/gobreaker"
 )
 
 // CircuitBreakerAdapter wraps the gobreaker library to implement our CircuitBreaker port
 type CircuitBreakerAdapter struct {
-	cb *gobreaker.CircuitBreaker
+	name     string
+	mu       sync.RWMutex
+	cb       *gobreaker.CircuitBreaker
+	settings domain.CircuitBreakerSettings
 }
 
 // NewCircuitBreakerAdapter creates a new circuit breaker adapter
 func NewCircuitBreakerAdapter(name string, maxRequests uint32, interval, timeout time.Duration, failureRatio float64, minRequests uint32) *CircuitBreakerAdapter {
-	settings := gobreaker.Settings{
+	settings := domain.CircuitBreakerSettings{
+		MaxRequests:  maxRequests,
+		Interval:     interval,
+		Timeout:      timeout,
+		FailureRatio: failureRatio,
+		MinRequests:  minRequests,
+	}
+
+	return &CircuitBreakerAdapter{
+		name:     name,
+		cb:       newGoBreaker(name, settings),
+		settings: settings,
+	}
+}
+
+func newGoBreaker(name string, s domain.CircuitBreakerSettings) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
 		Name:        name,
-		MaxRequests: maxRequests,
-		Interval:    interval,
-		Timeout:     timeout,
+		MaxRequests: s.MaxRequests,
+		Interval:    s.Interval,
+		Timeout:     s.Timeout,
 		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			if counts.Requests < minRequests {
+			if counts.Requests < s.MinRequests {
 				return false
 			}
 			ratio := float64(counts.TotalFailures) / float64(counts.Requests)
-			return ratio >= failureRatio
+			return ratio >= s.FailureRatio
 		},
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			fmt.Printf("Circuit breaker %s changed from %v to %v\n", name, from, to)
 		},
-	}
-
-	return &CircuitBreakerAdapter{
-		cb: gobreaker.NewCircuitBreaker(settings),
-	}
+	})
 }
 
 // Execute executes the given function with circuit breaker protection
 func (c *CircuitBreakerAdapter) Execute(fn func() error) error {
-	_, err := c.cb.Execute(func() (interface{}, error) {
+	c.mu.RLock()
+	cb := c.cb
+	c.mu.RUnlock()
+
+	_, err := cb.Execute(func() (interface{}, error) {
 		return nil, fn()
 	})
 	return err
@@ -46,5 +67,22 @@ func (c *CircuitBreakerAdapter) Execute(fn func() error) error {
 
 // State returns the current state of the circuit breaker
 func (c *CircuitBreakerAdapter) State() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.cb.State().String()
 }
+
+// ApplySettings replaces the breaker when its thresholds change. The new
+// breaker starts closed with empty counts.
+func (c *CircuitBreakerAdapter) ApplySettings(settings domain.ResilienceSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if settings.CircuitBreaker == c.settings {
+		return
+	}
+
+	c.settings = settings.CircuitBreaker
+	c.cb = newGoBreaker(c.name, c.settings)
+	fmt.Printf("Circuit breaker %s reconfigured: %+v\n", c.name, c.settings)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
@@ -15,16 +16,22 @@ import (
 type PaymentProcessorClient struct {
 	baseURL string
 	client  *http.Client
+	timeout atomic.Int64 // per-request timeout in nanoseconds, applied through the request context
 }
 
 // NewPaymentProcessorClient creates a new HTTP payment processor client
 func NewPaymentProcessorClient(baseURL string, timeout time.Duration) *PaymentProcessorClient {
-	return &PaymentProcessorClient{
+	p := &PaymentProcessorClient{
 		baseURL: baseURL,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  &http.Client{},
 	}
+	p.timeout.Store(int64(timeout))
+	return p
+}
+
+// ApplySettings updates the per-request timeout
+func (p *PaymentProcessorClient) ApplySettings(settings domain.ResilienceSettings) {
+	p.timeout.Store(int64(settings.ProcessorTimeout))
 }
 
 // ProcessPayment sends a payment request to the external processor
@@ -35,12 +42,22 @@ func (p *PaymentProcessorClient) ProcessPayment(ctx context.Context, payment dom
 		"correlationId": payment.CorrelationId,
 		"amount":        payment.Amount,
 	}
+	// Processors that predate currencies keep receiving the same body
+	if payment.Currency != "" {
+		paymentData["currency"] = payment.Currency
+	}
 
 	paymentJSON, err := json.Marshal(paymentData)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payment data: %w", err)
 	}
 
+	if timeout := time.Duration(p.timeout.Load()); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(paymentJSON))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -0,0 +1,113 @@
+package http_client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// nonPublicPrefixes are the IPv4 ranges netip does not report as private
+// or special: "this network" and the carrier-grade NAT space
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// WebhookClient implements the WebhookSender port using HTTP
+type WebhookClient struct {
+	client *http.Client
+	// restricted sends the deliveries to caller-chosen URLs, refusing to
+	// connect to anything but public addresses
+	restricted *http.Client
+}
+
+// NewWebhookClient creates a new HTTP webhook client. Redirects are not
+// followed, so a receiver cannot bounce the signed body elsewhere. Unless
+// allowPrivate is set, restricted deliveries may only reach public addresses.
+func NewWebhookClient(timeout time.Duration, allowPrivate bool) *WebhookClient {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		// Checked on the resolved address of every connection, so DNS
+		// cannot point an allowed host at an internal one
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("refusing to connect to %s: %w", address, err)
+			}
+			if !publicAddress(addrPort.Addr()) {
+				return fmt.Errorf("refusing to connect to non-public address %s", addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	noRedirects := func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &WebhookClient{
+		client: &http.Client{
+			Timeout:       timeout,
+			CheckRedirect: noRedirects,
+		},
+		restricted: &http.Client{
+			Timeout:       timeout,
+			CheckRedirect: noRedirects,
+			// No proxy, the dialer must see the receiver's address
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+}
+
+// publicAddress reports whether addr is routable on the internet
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Send posts the signed body of a delivery to its URL
+func (w *WebhookClient) Send(ctx context.Context, delivery domain.WebhookDelivery, body []byte, signature string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", delivery.ID)
+	req.Header.Set("X-Webhook-Event", string(delivery.Event.Type))
+	req.Header.Set("X-Webhook-Signature", signature)
+
+	client := w.client
+	if delivery.Restricted {
+		client = w.restricted
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	return resp.StatusCode, nil
+}
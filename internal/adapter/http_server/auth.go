@@ -0,0 +1,107 @@
+package http_server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+)
+
+const (
+	// apiKeyHeader carries the client's API key
+	apiKeyHeader = "X-API-Key"
+	// signatureHeader carries the optional request signature
+	signatureHeader = "X-Signature"
+	// clientKey is the gin context key holding the authenticated client name
+	clientKey = "client"
+	// maxSignedBodyBytes bounds the body read to verify a signature
+	maxSignedBodyBytes = 1 << 20
+)
+
+// requireAPIKey authenticates the client by API key and, when signed, by
+// request signature, and stores the client name in the context
+func (s *Server) requireAPIKey(c *gin.Context) {
+	req := usecase.AuthRequest{
+		Method:     c.Request.Method,
+		RequestURI: c.Request.RequestURI,
+		Signature:  c.GetHeader(signatureHeader),
+	}
+
+	if s.authenticate.NeedsBody(req.Signature) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodyBytes))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "failed to read body: " + err.Error()})
+			return
+		}
+		req.Body = body
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	key, err := s.authenticate.Authenticate(c.Request.Context(), c.GetHeader(apiKeyHeader), req)
+	if errors.Is(err, usecase.ErrUnauthenticated) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		fmt.Printf("[%s] Authentication unavailable: %v\n", s.config.Server.InstanceID, err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "authentication unavailable"})
+		return
+	}
+
+	c.Set(clientKey, key.Client)
+	c.Next()
+}
+
+// apiKeyRotation issues a new key for client; the current keys keep working
+// for grace
+type apiKeyRotation struct {
+	Client string `json:"client"`
+	Grace  string `json:"grace"`
+}
+
+func (s *Server) handleListAPIKeys(c *gin.Context) {
+	keys, err := s.authenticate.List(c.Request.Context())
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+func (s *Server) handleRotateAPIKey(c *gin.Context) {
+	var req apiKeyRotation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		return
+	}
+
+	var grace time.Duration
+	if req.Grace != "" {
+		var err error
+		if grace, err = time.ParseDuration(req.Grace); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'grace', expected a duration such as 1h"})
+			return
+		}
+	}
+
+	secret, key, err := s.authenticate.Rotate(c.Request.Context(), req.Client, grace, c.GetString(operatorKey))
+	if err != nil {
+		apiKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"key": secret, "apiKey": key})
+}
+
+// apiKeyError writes the status matching an API key management error
+func apiKeyError(c *gin.Context, err error) {
+	if errors.Is(err, usecase.ErrInvalidRotation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,113 @@
+package http_server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+)
+
+// maxBatchBodyBytes bounds the body of a batch request
+const maxBatchBodyBytes = 4 << 20
+
+// decodeBatch reads a JSON array or an NDJSON stream of payments. Items that
+// are valid JSON but not a payment are returned as nil with their error, so
+// only malformed JSON fails the whole batch.
+func decodeBatch(body io.Reader, contentType string) ([]json.RawMessage, error) {
+	reader := bufio.NewReader(body)
+	dec := json.NewDecoder(reader)
+
+	array := false
+	if !strings.Contains(contentType, "ndjson") && !strings.Contains(contentType, "jsonl") {
+		// Plain JSON bodies may still be NDJSON, tell them apart by the first byte
+		for {
+			b, err := reader.Peek(1)
+			if err != nil {
+				break
+			}
+			if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+				_, _ = reader.ReadByte()
+				continue
+			}
+			array = b[0] == '['
+			break
+		}
+	}
+
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	var items []json.RawMessage
+	for (array && dec.More()) || !array {
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			if !array && errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(items) == usecase.MaxBatchSize {
+			return nil, usecase.ErrBatchTooLarge
+		}
+		items = append(items, item)
+	}
+
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func (s *Server) handleRequestPaymentBatch(c *gin.Context) {
+	items, err := decodeBatch(http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodyBytes), c.ContentType())
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, usecase.ErrBatchTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("a batch body may be at most %d bytes", tooLarge.Limit)})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		return
+	}
+
+	// Items that are not payments are reported without reaching the use case
+	results := make([]domain.BatchItemResult, len(items))
+	var payments []domain.Payment
+	var indexes []int
+	for i, item := range items {
+		var payment domain.Payment
+		if err := json.Unmarshal(item, &payment); err != nil {
+			results[i] = domain.BatchItemResult{Status: domain.BatchItemInvalid, Error: "Invalid JSON: " + err.Error()}
+			continue
+		}
+		payment.Client = c.GetString(clientKey)
+		payments = append(payments, payment)
+		indexes = append(indexes, i)
+	}
+
+	queued, err := s.requestPayment.ExecuteBatch(c.Request.Context(), payments)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	for j, i := range indexes {
+		results[i] = queued[j]
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,197 @@
+package http_server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+)
+
+// operatorKey is the gin context key holding the authenticated operator name
+const operatorKey = "operator"
+
+// circuitBreakerJSON is the JSON representation of circuit breaker settings
+type circuitBreakerJSON struct {
+	MaxRequests  uint32  `json:"maxRequests"`
+	Interval     string  `json:"interval"`
+	Timeout      string  `json:"timeout"`
+	FailureRatio float64 `json:"failureRatio"`
+	MinRequests  uint32  `json:"minRequests"`
+}
+
+// settingsJSON is the JSON representation of resilience settings, with
+// durations written like "5s"
+type settingsJSON struct {
+	CircuitBreaker    circuitBreakerJSON `json:"circuitBreaker"`
+	ProcessorTimeout  string             `json:"processorTimeout"`
+	WorkerConcurrency int                `json:"workerConcurrency"`
+}
+
+// settingsChangeJSON is the JSON representation of an audit log entry
+type settingsChangeJSON struct {
+	Version   int64        `json:"version"`
+	ChangedBy string       `json:"changedBy"`
+	ChangedAt time.Time    `json:"changedAt"`
+	Instance  string       `json:"instance"`
+	Fields    []string     `json:"fields"`
+	Before    settingsJSON `json:"before"`
+	After     settingsJSON `json:"after"`
+}
+
+// settingsUpdateRequest is a partial update, omitted fields are left unchanged
+type settingsUpdateRequest struct {
+	CircuitBreaker *struct {
+		MaxRequests  *uint32  `json:"maxRequests"`
+		Interval     *string  `json:"interval"`
+		Timeout      *string  `json:"timeout"`
+		FailureRatio *float64 `json:"failureRatio"`
+		MinRequests  *uint32  `json:"minRequests"`
+	} `json:"circuitBreaker"`
+	ProcessorTimeout  *string `json:"processorTimeout"`
+	WorkerConcurrency *int    `json:"workerConcurrency"`
+}
+
+func toSettingsJSON(s domain.ResilienceSettings) settingsJSON {
+	return settingsJSON{
+		CircuitBreaker: circuitBreakerJSON{
+			MaxRequests:  s.CircuitBreaker.MaxRequests,
+			Interval:     s.CircuitBreaker.Interval.String(),
+			Timeout:      s.CircuitBreaker.Timeout.String(),
+			FailureRatio: s.CircuitBreaker.FailureRatio,
+			MinRequests:  s.CircuitBreaker.MinRequests,
+		},
+		ProcessorTimeout:  s.ProcessorTimeout.String(),
+		WorkerConcurrency: s.WorkerConcurrency,
+	}
+}
+
+func toSettingsChangeJSON(c domain.SettingsChange) settingsChangeJSON {
+	return settingsChangeJSON{
+		Version:   c.Version,
+		ChangedBy: c.ChangedBy,
+		ChangedAt: c.ChangedAt,
+		Instance:  c.Instance,
+		Fields:    c.Fields,
+		Before:    toSettingsJSON(c.Before),
+		After:     toSettingsJSON(c.After),
+	}
+}
+
+// toUpdate converts the request into a domain update, parsing durations
+func (r settingsUpdateRequest) toUpdate() (domain.SettingsUpdate, error) {
+	var update domain.SettingsUpdate
+	var err error
+
+	parse := func(name string, value *string) *time.Duration {
+		if value == nil || err != nil {
+			return nil
+		}
+		d, parseErr := time.ParseDuration(*value)
+		if parseErr != nil {
+			err = fmt.Errorf("invalid %s %q: use a unit such as 500ms or 5s", name, *value)
+			return nil
+		}
+		return &d
+	}
+
+	if cb := r.CircuitBreaker; cb != nil {
+		update.CircuitBreakerMaxRequests = cb.MaxRequests
+		update.CircuitBreakerInterval = parse("circuitBreaker.interval", cb.Interval)
+		update.CircuitBreakerTimeout = parse("circuitBreaker.timeout", cb.Timeout)
+		update.CircuitBreakerFailureRatio = cb.FailureRatio
+		update.CircuitBreakerMinRequests = cb.MinRequests
+	}
+	update.ProcessorTimeout = parse("processorTimeout", r.ProcessorTimeout)
+	update.WorkerConcurrency = r.WorkerConcurrency
+
+	return update, err
+}
+
+// requireControlToken authenticates the request with one of the configured
+// control tokens and stores the operator name in the context
+func (s *Server) requireControlToken(c *gin.Context) {
+	if len(s.config.Control.Tokens) == 0 {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "control endpoints are disabled"})
+		return
+	}
+
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+		return
+	}
+
+	for name, expected := range s.config.Control.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			c.Set(operatorKey, name)
+			c.Next()
+			return
+		}
+	}
+
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid bearer token"})
+}
+
+func (s *Server) handleGetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"instance": s.config.Server.InstanceID,
+		"settings": toSettingsJSON(s.updateSettings.Current()),
+	})
+}
+
+func (s *Server) handleUpdateSettings(c *gin.Context) {
+	var req settingsUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		return
+	}
+
+	update, err := req.toUpdate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change, err := s.updateSettings.Execute(c.Request.Context(), update, c.GetString(operatorKey))
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidSettings) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Applied locally but could not be broadcast to the other instances
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "change": toSettingsChangeJSON(change)})
+		return
+	}
+
+	c.JSON(http.StatusOK, toSettingsChangeJSON(change))
+}
+
+func (s *Server) handleSettingsHistory(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'limit', expected a positive integer"})
+			return
+		}
+	}
+
+	changes, err := s.updateSettings.History(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	history := make([]settingsChangeJSON, 0, len(changes))
+	for _, change := range changes {
+		history = append(history, toSettingsChangeJSON(change))
+	}
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,163 @@
+package http_server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+)
+
+// exportColumns is the header of CSV exports
+var exportColumns = []string{"correlationId", "channel", "amount", "currency", "client", "processedAt"}
+
+// recordWriter writes the records of an export in one format
+type recordWriter interface {
+	header() error
+	write(record domain.PaymentRecord) error
+	flush() error
+}
+
+// csvRecordWriter writes one CSV row per record, after a header row
+type csvRecordWriter struct {
+	w *csv.Writer
+}
+
+func (e csvRecordWriter) header() error {
+	return e.w.Write(exportColumns)
+}
+
+func (e csvRecordWriter) write(record domain.PaymentRecord) error {
+	return e.w.Write([]string{
+		record.CorrelationId,
+		record.Channel.String(),
+		strconv.FormatFloat(record.Amount, 'f', 2, 64),
+		record.CurrencyOrDefault().String(),
+		record.Client,
+		record.ProcessedAt.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+func (e csvRecordWriter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonRecordWriter writes one JSON object per line
+type ndjsonRecordWriter struct {
+	enc *json.Encoder
+	w   gin.ResponseWriter
+}
+
+func (e ndjsonRecordWriter) header() error {
+	return nil
+}
+
+func (e ndjsonRecordWriter) write(record domain.PaymentRecord) error {
+	return e.enc.Encode(struct {
+		CorrelationId string                  `json:"correlationId"`
+		Channel       domain.ProcessorChannel `json:"channel"`
+		Amount        float64                 `json:"amount"`
+		Currency      domain.Currency         `json:"currency"`
+		Client        string                  `json:"client,omitempty"`
+		ProcessedAt   time.Time               `json:"processedAt"`
+	}{record.CorrelationId, record.Channel, record.Amount, record.CurrencyOrDefault(), record.Client, record.ProcessedAt.UTC()})
+}
+
+func (e ndjsonRecordWriter) flush() error {
+	e.w.Flush()
+	return nil
+}
+
+// optionalTimestamp parses an optional RFC3339 query parameter
+func optionalTimestamp(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid '%s' timestamp, expected ISO8601 UTC (RFC3339)", name)
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// handleExportPayments streams every payment recorded in the range, as CSV
+// or NDJSON. Authenticated clients only get their own payments. Once the
+// first record is sent, errors can only cut the export short.
+func (s *Server) handleExportPayments(c *gin.Context) {
+	filter := domain.PaymentRecordFilter{Client: c.GetString(clientKey)}
+	var err error
+	if filter.From, err = optionalTimestamp(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.To, err = optionalTimestamp(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Channel = domain.ProcessorChannel(c.Query("channel"))
+
+	var out recordWriter
+	var contentType, filename string
+	switch format := c.DefaultQuery("format", "csv"); format {
+	case "csv":
+		out, contentType, filename = csvRecordWriter{csv.NewWriter(c.Writer)}, "text/csv", "payments.csv"
+	case "ndjson":
+		out, contentType, filename = ndjsonRecordWriter{json.NewEncoder(c.Writer), c.Writer}, "application/x-ndjson", "payments.ndjson"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown format %q, expected csv or ndjson", format)})
+		return
+	}
+
+	// The response is only started with the first record, so failures to
+	// start the export still get an error status
+	started := false
+	start := func() error {
+		started = true
+		// The export outlives the server write timeout
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+			fmt.Printf("[%s] Failed to clear the write deadline of a payments export: %v\n", s.config.Server.InstanceID, err)
+		}
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Status(http.StatusOK)
+		return out.header()
+	}
+
+	err = s.auditPayments.Export(c.Request.Context(), filter, func(record domain.PaymentRecord) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return out.write(record)
+	})
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRange) && !started:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil && !started:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		fmt.Printf("[%s] Payments export cut short: %v\n", s.config.Server.InstanceID, err)
+		return
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			return
+		}
+	}
+	if err := out.flush(); err != nil {
+		fmt.Printf("[%s] Failed to finish payments export: %v\n", s.config.Server.InstanceID, err)
+	}
+}
@@ -0,0 +1,138 @@
+package http_server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+)
+
+// queueStatsJSON is the JSON representation of queue stats
+type queueStatsJSON struct {
+	Queue            domain.QueueName `json:"queue"`
+	Depth            int              `json:"depth"`
+	OldestEnqueuedAt *time.Time       `json:"oldestEnqueuedAt,omitempty"`
+	OldestAgeSeconds float64          `json:"oldestAgeSeconds"`
+}
+
+// queueMoveRequest moves the listed payments, or all of them when
+// correlationIds is empty
+type queueMoveRequest struct {
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	CorrelationIDs []string `json:"correlationIds"`
+}
+
+// queueError writes the status matching a queue management error
+func queueError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrQueueManagementUnsupported):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidQueueRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// queueParam parses the :queue path parameter
+func queueParam(c *gin.Context) (domain.QueueName, bool) {
+	queue, err := domain.ParseQueueName(c.Param("queue"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return queue, true
+}
+
+func (s *Server) handleQueueStats(c *gin.Context) {
+	stats, err := s.manageQueue.Stats()
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	now := time.Now()
+	queues := make([]queueStatsJSON, 0, len(stats))
+	for _, st := range stats {
+		queues = append(queues, queueStatsJSON{
+			Queue:            st.Queue,
+			Depth:            st.Depth,
+			OldestEnqueuedAt: st.OldestEnqueuedAt,
+			OldestAgeSeconds: st.OldestAge(now).Seconds(),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"queues": queues})
+}
+
+func (s *Server) handleQueueItems(c *gin.Context) {
+	queue, ok := queueParam(c)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'offset', expected an integer"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'limit', expected an integer"})
+		return
+	}
+
+	items, err := s.manageQueue.List(queue, offset, limit)
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queue": queue, "offset": offset, "limit": limit, "items": items})
+}
+
+func (s *Server) handleQueueMove(c *gin.Context) {
+	var req queueMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		return
+	}
+
+	from, err := domain.ParseQueueName(req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := domain.ParseQueueName(req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	moved, err := s.manageQueue.Move(from, to, req.CorrelationIDs, c.GetString(operatorKey))
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"moved": moved})
+}
+
+func (s *Server) handleQueuePurge(c *gin.Context) {
+	queue, ok := queueParam(c)
+	if !ok {
+		return
+	}
+
+	purged, err := s.manageQueue.Purge(queue, c.GetString(operatorKey))
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
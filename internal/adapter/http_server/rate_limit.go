@@ -0,0 +1,39 @@
+package http_server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimit takes a token for the caller and rejects the request with 429
+// when the bucket is empty. Limiter failures let the request through.
+func (s *Server) rateLimit(c *gin.Context) {
+	decision, err := s.limitRate.Allow(c.Request.Context(), c.GetString(clientKey), c.ClientIP())
+	if err != nil {
+		fmt.Printf("[%s] Rate limiter unavailable, allowing request: %v\n", s.config.Server.InstanceID, err)
+		c.Next()
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	header.Set("RateLimit-Reset", seconds(decision.Reset))
+
+	if !decision.Allowed {
+		header.Set("Retry-After", seconds(decision.RetryAfter))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		return
+	}
+	c.Next()
+}
+
+// seconds formats a duration as whole seconds, rounded up
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -1,6 +1,7 @@
 package http_server
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"time"
@@ -15,15 +16,27 @@ import (
 type Server struct {
 	requestPayment *usecase.RequestPaymentUseCase
 	auditPayments  *usecase.AuditPaymentsUseCase
+	updateSettings *usecase.UpdateSettingsUseCase
+	manageQueue    *usecase.ManageQueueUseCase
+	limitRate      *usecase.RateLimitUseCase
+	authenticate   *usecase.AuthenticateUseCase
+	webhooks       *usecase.DeliverWebhooksUseCase
+	streamPayments *usecase.StreamPaymentsUseCase
 	engine         *gin.Engine
-	config         *config.ServerConfig
+	config         *config.Config
 }
 
 // NewServer creates a new HTTP server instance
 func NewServer(
 	requestPayment *usecase.RequestPaymentUseCase,
 	auditPayments *usecase.AuditPaymentsUseCase,
-	cfg *config.ServerConfig,
+	updateSettings *usecase.UpdateSettingsUseCase,
+	manageQueue *usecase.ManageQueueUseCase,
+	limitRate *usecase.RateLimitUseCase,
+	authenticate *usecase.AuthenticateUseCase,
+	webhooks *usecase.DeliverWebhooksUseCase,
+	streamPayments *usecase.StreamPaymentsUseCase,
+	cfg *config.Config,
 ) *Server {
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
@@ -33,6 +46,12 @@ func NewServer(
 	server := &Server{
 		requestPayment: requestPayment,
 		auditPayments:  auditPayments,
+		updateSettings: updateSettings,
+		manageQueue:    manageQueue,
+		limitRate:      limitRate,
+		authenticate:   authenticate,
+		webhooks:       webhooks,
+		streamPayments: streamPayments,
 		engine:         engine,
 		config:         cfg,
 	}
@@ -43,24 +62,67 @@ func NewServer(
 
 // registerRoutes sets up the HTTP routes
 func (s *Server) registerRoutes() {
-	s.engine.POST("/payments", s.handleRequestPayment)
-	s.engine.GET("/payments-summary", s.handleAuditPayments)
+	// Payments endpoints, authenticated when API keys are enabled
+	api := s.engine.Group("")
+	if s.authenticate.Enabled() {
+		api.Use(s.requireAPIKey)
+	}
+
+	// A batch takes a single token, like one payment
+	var limit []gin.HandlerFunc
+	if s.limitRate.Enabled() {
+		limit = []gin.HandlerFunc{s.rateLimit}
+	}
+	api.POST("/payments", append(limit, s.handleRequestPayment)...)
+	api.POST("/payments/batch", append(limit, s.handleRequestPaymentBatch)...)
+	api.GET("/payments/stream", s.handleStreamPayments)
+	api.GET("/payments/export", s.handleExportPayments)
+	api.GET("/payments-summary", s.handleAuditPayments)
+	api.GET("/payments-summary/timeseries", s.handleTimeseries)
 	s.engine.GET("/health", s.handleHealth)
+
+	effectiveConfig := s.engine.Group("/internal/config", s.requireControlToken)
+	effectiveConfig.GET("", s.handleEffectiveConfig)
+
+	control := s.engine.Group("/internal/settings", s.requireControlToken)
+	control.GET("", s.handleGetSettings)
+	control.PUT("", s.handleUpdateSettings)
+	control.GET("/history", s.handleSettingsHistory)
+
+	queue := s.engine.Group("/internal/queue", s.requireControlToken)
+	queue.GET("", s.handleQueueStats)
+	queue.GET("/:queue/items", s.handleQueueItems)
+	queue.POST("/move", s.handleQueueMove)
+	queue.DELETE("/:queue", s.handleQueuePurge)
+
+	apiKeys := s.engine.Group("/internal/api-keys", s.requireControlToken)
+	apiKeys.GET("", s.handleListAPIKeys)
+	apiKeys.POST("/rotate", s.handleRotateAPIKey)
+
+	webhooks := s.engine.Group("/internal/webhooks", s.requireControlToken)
+	webhooks.GET("", s.handleListWebhooks)
+	webhooks.GET("/:id", s.handleGetWebhook)
+	webhooks.POST("/:id/redeliver", s.handleRedeliverWebhook)
 }
 
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	srv := &http.Server{
-		Addr:              s.config.Port,
+		Addr:              s.config.Server.Port,
 		Handler:           s.engine,
-		ReadHeaderTimeout: s.config.ReadTimeout,
-		WriteTimeout:      s.config.WriteTimeout,
+		ReadHeaderTimeout: s.config.Server.ReadTimeout,
+		WriteTimeout:      s.config.Server.WriteTimeout,
 	}
 
-	log.Printf("HTTP server listening on %s", s.config.Port)
+	log.Printf("HTTP server listening on %s", s.config.Server.Port)
 	return srv.ListenAndServe()
 }
 
+// Handler returns the HTTP handler with all routes registered
+func (s *Server) Handler() http.Handler {
+	return s.engine
+}
+
 func (s *Server) handleRequestPayment(c *gin.Context) {
 	var payment domain.Payment
 	if err := c.ShouldBindJSON(&payment); err != nil {
@@ -68,6 +130,8 @@ func (s *Server) handleRequestPayment(c *gin.Context) {
 		return
 	}
 
+	payment.Client = c.GetString(clientKey)
+
 	// fmt.Printf("[%s] HTTP Request received for payment: %s\n", os.Getenv("INSTANCE_ID"), payment.CorrelationId)
 
 	if err := s.requestPayment.Execute(c.Request.Context(), payment); err != nil {
@@ -113,6 +177,53 @@ func (s *Server) handleAuditPayments(c *gin.Context) {
 	c.JSON(http.StatusOK, summary)
 }
 
+// handleTimeseries returns the summary split into buckets. from is required,
+// to defaults to now and bucket to 1s.
+func (s *Server) handleTimeseries(c *gin.Context) {
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from' timestamp, expected ISO8601 UTC (RFC3339)"})
+		return
+	}
+
+	to := time.Now().UTC()
+	if toStr := c.Query("to"); toStr != "" {
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to' timestamp, expected ISO8601 UTC (RFC3339)"})
+			return
+		}
+	}
+
+	bucket, err := domain.ParseTimeseriesBucket(c.DefaultQuery("bucket", string(domain.BucketSecond)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := s.auditPayments.Timeseries(c.Request.Context(), from, to, bucket)
+	if errors.Is(err, usecase.ErrInvalidRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
 func (s *Server) handleHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
+
+// handleEffectiveConfig returns the configuration in use with secrets redacted
+func (s *Server) handleEffectiveConfig(c *gin.Context) {
+	effective, err := s.config.Effective()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, effective)
+}
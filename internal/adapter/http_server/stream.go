@@ -0,0 +1,110 @@
+package http_server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// streamFilter builds the event filter from the repeatable or
+// comma-separated 'channel' query parameter. Authenticated clients only see
+// the events of their own payments.
+func streamFilter(c *gin.Context) domain.PaymentEventFilter {
+	filter := domain.PaymentEventFilter{Client: c.GetString(clientKey)}
+	for _, value := range c.QueryArray("channel") {
+		for _, channel := range strings.Split(value, ",") {
+			if channel = strings.TrimSpace(channel); channel != "" {
+				filter.Channels = append(filter.Channels, domain.ProcessorChannel(channel))
+			}
+		}
+	}
+	return filter
+}
+
+// writeSSE writes one Server-Sent Event and flushes it to the client
+func writeSSE(c *gin.Context, id, event string, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(c.Writer, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, body); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+func (s *Server) handleStreamPayments(c *gin.Context) {
+	if !s.streamPayments.Enabled() {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "the payment event stream is disabled"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	filter := streamFilter(c)
+	events, err := s.streamPayments.Subscribe(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The stream outlives the server write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		fmt.Printf("Failed to clear the write deadline of a payment stream: %v\n", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	sendSnapshot := func() error {
+		channels, err := s.streamPayments.Snapshot(filter)
+		if err != nil {
+			fmt.Printf("Failed to read the payment stream snapshot: %v\n", err)
+			return nil
+		}
+		return writeSSE(c, "", "summary", channels)
+	}
+
+	// Without snapshots the ticker channel stays nil and never fires
+	var snapshots <-chan time.Time
+	if s.streamPayments.HasSnapshots(filter) {
+		if err := sendSnapshot(); err != nil {
+			return
+		}
+		ticker := time.NewTicker(s.streamPayments.SnapshotInterval())
+		defer ticker.Stop()
+		snapshots = ticker.C
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeSSE(c, event.ID, string(event.Type), event); err != nil {
+				return
+			}
+		case <-snapshots:
+			if err := sendSnapshot(); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package http_server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+)
+
+// webhookError writes the status matching a webhook management error
+func webhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrWebhooksDisabled):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidWebhookRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (s *Server) handleListWebhooks(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'limit', expected an integer"})
+		return
+	}
+
+	deliveries, err := s.webhooks.List(c.Request.Context(), limit)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+func (s *Server) handleGetWebhook(c *gin.Context) {
+	delivery, err := s.webhooks.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+func (s *Server) handleRedeliverWebhook(c *gin.Context) {
+	delivery, err := s.webhooks.Redeliver(c.Request.Context(), c.Param("id"), c.GetString(operatorKey))
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
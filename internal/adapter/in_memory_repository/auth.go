@@ -0,0 +1,105 @@
+package in_memory_repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// InMemoryAPIKeyStore implements the APIKeyStore port for a single instance
+type InMemoryAPIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]domain.APIKey
+}
+
+// NewInMemoryAPIKeyStore creates a new in-memory API key store
+func NewInMemoryAPIKeyStore() *InMemoryAPIKeyStore {
+	return &InMemoryAPIKeyStore{keys: make(map[string]domain.APIKey)}
+}
+
+// Get returns the key with the given ID
+func (s *InMemoryAPIKeyStore) Get(_ context.Context, id string) (domain.APIKey, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+	return key, ok, nil
+}
+
+// List returns every key, oldest first
+func (s *InMemoryAPIKeyStore) List(_ context.Context) ([]domain.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]domain.APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// Add stores a key unless one with the same ID exists
+func (s *InMemoryAPIKeyStore) Add(_ context.Context, key domain.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.keys[key.ID]; !exists {
+		s.keys[key.ID] = key
+	}
+	return nil
+}
+
+// ExpireClient makes the keys of client stop working at the given time
+func (s *InMemoryAPIKeyStore) ExpireClient(_ context.Context, client string, at time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := 0
+	for id, key := range s.keys {
+		if key.Client != client || (key.ExpiresAt != nil && !key.ExpiresAt.After(at)) {
+			continue
+		}
+		key.ExpiresAt = &at
+		s.keys[id] = key
+		changed++
+	}
+	return changed, nil
+}
+
+// InMemoryReplayCache implements the ReplayCache port for a single instance
+type InMemoryReplayCache struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time // expiry by ID
+	lastSweep time.Time
+}
+
+// NewInMemoryReplayCache creates a new in-memory replay cache
+func NewInMemoryReplayCache() *InMemoryReplayCache {
+	return &InMemoryReplayCache{seen: make(map[string]time.Time), lastSweep: time.Now()}
+}
+
+// Remember records id for ttl and reports false if it was already recorded
+func (c *InMemoryReplayCache) Remember(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= time.Minute {
+		for seenID, expiry := range c.seen {
+			if !now.Before(expiry) {
+				delete(c.seen, seenID)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if expiry, ok := c.seen[id]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	c.seen[id] = now.Add(ttl)
+	return true, nil
+}
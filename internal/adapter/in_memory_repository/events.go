@@ -0,0 +1,62 @@
+package in_memory_repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// paymentEventBuffer is how many events a slow subscriber may lag behind
+// before events are dropped for it
+const paymentEventBuffer = 1024
+
+// InMemoryPaymentEventBus implements the PaymentEventBus port for a single process
+type InMemoryPaymentEventBus struct {
+	mu          sync.Mutex
+	subscribers []chan domain.PaymentEvent
+}
+
+// NewInMemoryPaymentEventBus creates a new in-process payment event bus
+func NewInMemoryPaymentEventBus() *InMemoryPaymentEventBus {
+	return &InMemoryPaymentEventBus{}
+}
+
+// Publish delivers the event to every current subscriber that keeps up
+func (b *InMemoryPaymentEventBus) Publish(_ context.Context, event domain.PaymentEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel of payment events, closed when ctx is done
+func (b *InMemoryPaymentEventBus) Subscribe(ctx context.Context) (<-chan domain.PaymentEvent, error) {
+	ch := make(chan domain.PaymentEvent, paymentEventBuffer)
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, ch)
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.subscribers {
+			if sub == ch {
+				b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}()
+
+	return ch, nil
+}
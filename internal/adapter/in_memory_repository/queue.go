@@ -2,54 +2,235 @@ package in_memory_repository
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
 )
 
-// InMemoryQueue implements the PaymentQueue port using Go channels
+// InMemoryQueue implements the PaymentQueue and QueueManager ports with
+// slices, handing payments to the workers through a channel
 type InMemoryQueue struct {
-	queue   chan domain.Payment
-	closed  bool
-	closeCh chan struct{}
+	mu     sync.Mutex
+	queues map[domain.QueueName][]domain.QueuedPayment
+	size   int
+	closed bool
+
+	ready chan struct{} // signalled when the main queue gets a payment
+	done  chan struct{}
+	out   chan domain.Payment
+	start sync.Once
 }
 
-// NewInMemoryQueue creates a new in-memory payment queue
+// NewInMemoryQueue creates a new in-memory payment queue holding up to
+// bufferSize pending payments
 func NewInMemoryQueue(bufferSize int) *InMemoryQueue {
 	return &InMemoryQueue{
-		queue:   make(chan domain.Payment, bufferSize),
-		closeCh: make(chan struct{}),
+		queues: map[domain.QueueName][]domain.QueuedPayment{
+			domain.MainQueue:       nil,
+			domain.DeadLetterQueue: nil,
+		},
+		size:  bufferSize,
+		ready: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+		out:   make(chan domain.Payment, 1),
 	}
 }
 
 // Send adds a payment to the queue
 func (q *InMemoryQueue) Send(payment domain.Payment) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
 	if q.closed {
 		return fmt.Errorf("queue is closed")
 	}
+	if len(q.queues[domain.MainQueue]) >= q.size {
+		return fmt.Errorf("queue is full")
+	}
 
-	select {
-	case q.queue <- payment:
-		return nil
-	case <-q.closeCh:
+	q.queues[domain.MainQueue] = append(q.queues[domain.MainQueue], domain.QueuedPayment{
+		Payment:    payment,
+		EnqueuedAt: time.Now().UTC(),
+	})
+	q.signal()
+	return nil
+}
+
+// SendBatch adds the payments to the queue, or none when they do not all fit
+func (q *InMemoryQueue) SendBatch(payments []domain.Payment) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
 		return fmt.Errorf("queue is closed")
-	default:
+	}
+	if len(q.queues[domain.MainQueue])+len(payments) > q.size {
 		return fmt.Errorf("queue is full")
 	}
+
+	now := time.Now().UTC()
+	for _, payment := range payments {
+		q.queues[domain.MainQueue] = append(q.queues[domain.MainQueue], domain.QueuedPayment{
+			Payment:    payment,
+			EnqueuedAt: now,
+		})
+	}
+	q.signal()
+	return nil
 }
 
-// Receive returns a channel to receive payments from the queue
+// signal wakes the receiver. Must be called with mu held.
+func (q *InMemoryQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Receive returns a channel to receive payments from the queue. A payment
+// already queued can be read as soon as it returns.
 func (q *InMemoryQueue) Receive() <-chan domain.Payment {
-	return q.queue
+	q.start.Do(func() {
+		if payment, ok := q.pop(); ok {
+			q.out <- payment
+		}
+		go q.pump()
+	})
+	return q.out
+}
+
+// pump hands payments from the main queue to the workers until closed
+func (q *InMemoryQueue) pump() {
+	defer close(q.out)
+
+	for {
+		payment, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.ready:
+				continue
+			case <-q.done:
+				return
+			}
+		}
+
+		select {
+		case q.out <- payment:
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// pop removes the oldest payment of the main queue
+func (q *InMemoryQueue) pop() (domain.Payment, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	main := q.queues[domain.MainQueue]
+	if len(main) == 0 {
+		return domain.Payment{}, false
+	}
+	q.queues[domain.MainQueue] = main[1:]
+	return main[0].Payment, true
+}
+
+// Ack is a no-op, payments leave the queue when they are received
+func (q *InMemoryQueue) Ack(domain.Payment) error {
+	return nil
+}
+
+// Stats returns the depth and oldest payment of a queue
+func (q *InMemoryQueue) Stats(queue domain.QueueName) (domain.QueueStats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := q.queues[queue]
+	stats := domain.QueueStats{Queue: queue, Depth: len(items)}
+	if len(items) > 0 {
+		oldest := items[0].EnqueuedAt
+		stats.OldestEnqueuedAt = &oldest
+	}
+	return stats, nil
+}
+
+// List returns up to limit payments of a queue starting at offset
+func (q *InMemoryQueue) List(queue domain.QueueName, offset, limit int) ([]domain.QueuedPayment, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := q.queues[queue]
+	if offset >= len(items) {
+		return []domain.QueuedPayment{}, nil
+	}
+	end := min(offset+limit, len(items))
+	return append([]domain.QueuedPayment(nil), items[offset:end]...), nil
+}
+
+// Move moves payments between queues, all of them when no IDs are given
+func (q *InMemoryQueue) Move(from, to domain.QueueName, correlationIDs []string) ([]domain.QueuedPayment, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	selected := make(map[string]bool, len(correlationIDs))
+	for _, id := range correlationIDs {
+		selected[id] = true
+	}
+
+	var kept, moved []domain.QueuedPayment
+	for _, item := range q.queues[from] {
+		if len(selected) == 0 || selected[item.CorrelationId] {
+			moved = append(moved, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+
+	q.queues[from] = kept
+	q.queues[to] = append(q.queues[to], moved...)
+	if to == domain.MainQueue && len(moved) > 0 {
+		q.signal()
+	}
+	return moved, nil
+}
+
+// Purge deletes every payment of a queue
+func (q *InMemoryQueue) Purge(queue domain.QueueName) ([]domain.QueuedPayment, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	purged := q.queues[queue]
+	q.queues[queue] = nil
+	return purged, nil
+}
+
+// DeadLetter adds a payment to the dead-letter queue
+func (q *InMemoryQueue) DeadLetter(payment domain.Payment) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queues[domain.DeadLetterQueue] = append(q.queues[domain.DeadLetterQueue], domain.QueuedPayment{
+		Payment:    payment,
+		EnqueuedAt: time.Now().UTC(),
+	})
+	return nil
 }
 
 // Close closes the queue
 func (q *InMemoryQueue) Close() error {
+	q.mu.Lock()
 	if q.closed {
+		q.mu.Unlock()
 		return nil
 	}
-
 	q.closed = true
-	close(q.closeCh)
-	close(q.queue)
+	close(q.done)
+	q.mu.Unlock()
+
+	// Without a receiver nobody else closes the channel
+	q.start.Do(func() {
+		close(q.out)
+	})
 	return nil
 }
@@ -0,0 +1,77 @@
+package in_memory_repository
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// rateLimiterSweepInterval is how often full buckets are forgotten
+const rateLimiterSweepInterval = time.Minute
+
+// InMemoryRateLimiter implements the RateLimiter port for a single instance
+type InMemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket is full again
+}
+
+// NewInMemoryRateLimiter creates a new in-memory rate limiter
+func NewInMemoryRateLimiter() *InMemoryRateLimiter {
+	return &InMemoryRateLimiter{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take takes one token from the bucket of key, creating it full
+func (l *InMemoryRateLimiter) Take(_ context.Context, key string, quota domain.RateLimitQuota) (domain.RateLimitDecision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(quota.Burst), last: now}
+		l.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.last).Seconds()
+	bucket.tokens = math.Min(float64(quota.Burst), bucket.tokens+elapsed*quota.Rate)
+	bucket.last = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+
+	decision := quota.Decide(allowed, bucket.tokens)
+	bucket.full = now.Add(decision.Reset)
+	return decision, nil
+}
+
+// sweep forgets buckets that have refilled, they would be recreated full.
+// Must be called with mu held.
+func (l *InMemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if !now.Before(bucket.full) {
+			delete(l.buckets, key)
+		}
+	}
+}
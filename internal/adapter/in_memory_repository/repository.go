@@ -1,6 +1,7 @@
 package in_memory_repository
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -10,11 +11,17 @@ import (
 // InMemoryRepository implements the PaymentRepository port using in-memory storage
 type InMemoryRepository struct {
 	mu       sync.RWMutex
-	channels map[string]*channelStats
+	channels map[channelKey]*channelStats
 	// keep a simple append-only log to support range queries for auditing
 	events []paymentEvent
 }
 
+// channelKey splits the totals by channel and currency
+type channelKey struct {
+	channel  domain.ProcessorChannel
+	currency domain.Currency
+}
+
 type channelStats struct {
 	totalRequests int
 	totalAmount   float64
@@ -24,64 +31,118 @@ type paymentEvent struct {
 	when          time.Time
 	correlationID string
 	channel       domain.ProcessorChannel
+	currency      domain.Currency
 	amount        float64
+	client        string
 }
 
 // NewInMemoryRepository creates a new in-memory payment repository
 func NewInMemoryRepository() *InMemoryRepository {
 	return &InMemoryRepository{
-		channels: map[string]*channelStats{
-			domain.DefaultProcessor.String():  {},
-			domain.FallbackProcessor.String(): {},
-		},
-		events: make([]paymentEvent, 0, 1024),
+		channels: make(map[channelKey]*channelStats),
+		events:   make([]paymentEvent, 0, 1024),
 	}
 }
 
 // Add records a payment in the specified channel
-func (r *InMemoryRepository) Add(correlationID string, channel domain.ProcessorChannel, amount float64) error {
+func (r *InMemoryRepository) Add(payment domain.Payment, channel domain.ProcessorChannel) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	channelKey := channel.String()
-	stats, ok := r.channels[channelKey]
+	key := channelKey{channel: channel, currency: payment.CurrencyOrDefault()}
+	stats, ok := r.channels[key]
 	if !ok {
 		stats = &channelStats{}
-		r.channels[channelKey] = stats
+		r.channels[key] = stats
 	}
 
 	stats.totalRequests++
-	stats.totalAmount += amount
+	stats.totalAmount += payment.Amount
 	// record event timestamped in UTC to align with API expectations
 	r.events = append(r.events, paymentEvent{
 		when:          time.Now().UTC(),
-		correlationID: correlationID,
+		correlationID: payment.CorrelationId,
 		channel:       channel,
-		amount:        amount,
+		currency:      key.currency,
+		amount:        payment.Amount,
+		client:        payment.Client,
 	})
 	return nil
 }
 
-// GetSummary returns a summary of all payment channels
-func (r *InMemoryRepository) GetSummary() (domain.PaymentsSummary, error) {
+// Find returns the latest record of a payment
+func (r *InMemoryRepository) Find(correlationID string) (domain.PaymentRecord, bool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	getStats := func(channel domain.ProcessorChannel) domain.PaymentsChannelStats {
-		stats, ok := r.channels[channel.String()]
-		if !ok || stats == nil {
-			return domain.PaymentsChannelStats{}
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if e := r.events[i]; e.correlationID == correlationID {
+			return e.record(), true, nil
+		}
+	}
+	return domain.PaymentRecord{}, false, nil
+}
+
+// exportPageSize is how many events Export copies per lock
+const exportPageSize = 1024
+
+// Export calls fn for the payments recorded before the export started that
+// match filter. Events are copied a page at a time so a slow reader never
+// holds the lock; the log is append-only, so pages never shift.
+func (r *InMemoryRepository) Export(ctx context.Context, filter domain.PaymentRecordFilter, fn func(domain.PaymentRecord) error) error {
+	r.mu.RLock()
+	total := len(r.events)
+	r.mu.RUnlock()
+
+	for next := 0; next < total; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := min(next+exportPageSize, total)
+		page := make([]domain.PaymentRecord, 0, end-next)
+		r.mu.RLock()
+		for _, e := range r.events[next:end] {
+			if record := e.record(); filter.Matches(record) {
+				page = append(page, record)
+			}
 		}
-		return domain.PaymentsChannelStats{
-			TotalRequests: stats.totalRequests,
-			TotalAmount:   stats.totalAmount,
+		r.mu.RUnlock()
+		next = end
+
+		for _, record := range page {
+			if err := fn(record); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
+}
 
-	return domain.PaymentsSummary{
-		Default:  getStats(domain.DefaultProcessor),
-		Fallback: getStats(domain.FallbackProcessor),
-	}, nil
+// record converts the event to a payment record
+func (e paymentEvent) record() domain.PaymentRecord {
+	return domain.PaymentRecord{
+		Payment: domain.Payment{
+			CorrelationId: e.correlationID,
+			Amount:        e.amount,
+			Currency:      e.currency,
+			Client:        e.client,
+		},
+		Channel:     e.channel,
+		ProcessedAt: e.when,
+	}
+}
+
+// GetSummary returns a summary of all payment channels
+func (r *InMemoryRepository) GetSummary() (domain.PaymentsSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var summary domain.PaymentsSummary
+	for key, stats := range r.channels {
+		summary.Record(key.channel, key.currency, stats.totalRequests, stats.totalAmount)
+	}
+	return summary, nil
 }
 
 // GetSummaryInRange returns a summary filtered by the provided time range.
@@ -103,31 +164,37 @@ func (r *InMemoryRepository) GetSummaryInRange(from, to *time.Time) (domain.Paym
 		end = time.Now().UTC()
 	}
 
-	var defReq int
-	var defAmt float64
-	var fbReq int
-	var fbAmt float64
-
+	var summary domain.PaymentsSummary
 	for _, e := range r.events {
 		if (e.when.Equal(start) || e.when.After(start)) && (e.when.Before(end) || e.when.Equal(end)) {
-			if e.channel == domain.DefaultProcessor {
-				defReq++
-				defAmt += e.amount
-			} else if e.channel == domain.FallbackProcessor {
-				fbReq++
-				fbAmt += e.amount
-			}
+			summary.Record(e.channel, e.currency, 1, e.amount)
 		}
 	}
+	return summary, nil
+}
 
-	return domain.PaymentsSummary{
-		Default: domain.PaymentsChannelStats{
-			TotalRequests: defReq,
-			TotalAmount:   defAmt,
-		},
-		Fallback: domain.PaymentsChannelStats{
-			TotalRequests: fbReq,
-			TotalAmount:   fbAmt,
-		},
-	}, nil
+// GetTimeseries returns one summary per bucket between from and to, inclusive
+func (r *InMemoryRepository) GetTimeseries(from, to time.Time, bucket domain.TimeseriesBucket) ([]domain.SummaryPoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	from, to = from.UTC(), to.UTC()
+	starts := bucket.Starts(from, to)
+	points := make([]domain.SummaryPoint, len(starts))
+	for i, start := range starts {
+		points[i].Start = start
+	}
+	if len(points) == 0 {
+		return points, nil
+	}
+
+	first := points[0].Start
+	for _, e := range r.events {
+		if e.when.Before(from) || e.when.After(to) {
+			continue
+		}
+		i := int(bucket.Truncate(e.when).Sub(first) / bucket.Duration())
+		points[i].Summary.Record(e.channel, e.currency, 1, e.amount)
+	}
+	return points, nil
 }
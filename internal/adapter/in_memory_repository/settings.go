@@ -0,0 +1,96 @@
+package in_memory_repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// InMemorySettingsBus implements the SettingsBus port for a single process
+type InMemorySettingsBus struct {
+	mu          sync.Mutex
+	subscribers []chan domain.SettingsChange
+}
+
+// NewInMemorySettingsBus creates a new in-process settings bus
+func NewInMemorySettingsBus() *InMemorySettingsBus {
+	return &InMemorySettingsBus{}
+}
+
+// Publish delivers the change to every current subscriber
+func (b *InMemorySettingsBus) Publish(ctx context.Context, change domain.SettingsChange) error {
+	b.mu.Lock()
+	subscribers := append([]chan domain.SettingsChange(nil), b.subscribers...)
+	b.mu.Unlock()
+
+	for _, ch := range subscribers {
+		select {
+		case ch <- change:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel of settings changes, closed when ctx is done
+func (b *InMemorySettingsBus) Subscribe(ctx context.Context) (<-chan domain.SettingsChange, error) {
+	ch := make(chan domain.SettingsChange, 16)
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, ch)
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.subscribers {
+			if sub == ch {
+				b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// InMemorySettingsAuditLog implements the SettingsAuditLog port in memory
+type InMemorySettingsAuditLog struct {
+	mu      sync.RWMutex
+	changes []domain.SettingsChange
+}
+
+// NewInMemorySettingsAuditLog creates a new in-memory settings audit log
+func NewInMemorySettingsAuditLog() *InMemorySettingsAuditLog {
+	return &InMemorySettingsAuditLog{}
+}
+
+// Record appends the change to the log, versioned by its position
+func (l *InMemorySettingsAuditLog) Record(ctx context.Context, change domain.SettingsChange) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	change.Version = int64(len(l.changes) + 1)
+	l.changes = append(l.changes, change)
+	return change.Version, nil
+}
+
+// List returns the most recent changes first
+func (l *InMemorySettingsAuditLog) List(ctx context.Context, limit int) ([]domain.SettingsChange, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if limit <= 0 || limit > len(l.changes) {
+		limit = len(l.changes)
+	}
+
+	changes := make([]domain.SettingsChange, 0, limit)
+	for i := len(l.changes) - 1; i >= 0 && len(changes) < limit; i-- {
+		changes = append(changes, l.changes[i])
+	}
+	return changes, nil
+}
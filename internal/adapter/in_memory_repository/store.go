@@ -1,9 +1,13 @@
 package in_memory_repository
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 // Stores uuid in memory and retrieves it if already present
 type InMemoryStore struct {
+	mu   sync.Mutex
 	data map[string]bool
 }
 
@@ -15,14 +19,55 @@ func NewInMemoryStore() *InMemoryStore {
 
 // Add stores a new uuid in memory. Returns an error if the uuid is already present
 func (s *InMemoryStore) Add(uuid string) error {
-	if s.Exists(uuid) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data[uuid] {
 		return fmt.Errorf("UUID %s already exists", uuid)
 	}
 	s.data[uuid] = true
 	return nil
 }
 
+// AddBatch stores the uuids not yet present and reports which ones were added
+func (s *InMemoryStore) AddBatch(uuids []string) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := make([]bool, len(uuids))
+	for i, uuid := range uuids {
+		if !s.data[uuid] {
+			s.data[uuid] = true
+			added[i] = true
+		}
+	}
+	return added, nil
+}
+
 func (s *InMemoryStore) Exists(uuid string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	_, exists := s.data[uuid]
 	return exists
 }
+
+// Remove deletes a uuid from the store. Removing an unknown uuid is not an error
+func (s *InMemoryStore) Remove(uuid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, uuid)
+	return nil
+}
+
+// RemoveBatch deletes the uuids from the store
+func (s *InMemoryStore) RemoveBatch(uuids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, uuid := range uuids {
+		delete(s.data, uuid)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package in_memory_repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// InMemoryWebhookLog implements the WebhookDeliveryLog port for a single
+// instance, keeping deliveries for the retention period
+type InMemoryWebhookLog struct {
+	mu         sync.Mutex
+	deliveries map[string]domain.WebhookDelivery
+	retention  time.Duration
+	lastSweep  time.Time
+}
+
+// NewInMemoryWebhookLog creates a new in-memory webhook delivery log
+func NewInMemoryWebhookLog(retention time.Duration) *InMemoryWebhookLog {
+	return &InMemoryWebhookLog{
+		deliveries: make(map[string]domain.WebhookDelivery),
+		retention:  retention,
+		lastSweep:  time.Now(),
+	}
+}
+
+// Save creates or replaces a delivery
+func (l *InMemoryWebhookLog) Save(_ context.Context, delivery domain.WebhookDelivery) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= time.Minute {
+		for id, d := range l.deliveries {
+			if now.Sub(d.CreatedAt) > l.retention {
+				delete(l.deliveries, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	l.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+// Get returns the delivery with the given ID
+func (l *InMemoryWebhookLog) Get(_ context.Context, id string) (domain.WebhookDelivery, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delivery, ok := l.deliveries[id]
+	return delivery, ok, nil
+}
+
+// List returns the most recent deliveries first
+func (l *InMemoryWebhookLog) List(_ context.Context, limit int) ([]domain.WebhookDelivery, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(l.deliveries))
+	for _, d := range l.deliveries {
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// Claim returns the pending deliveries due at now, earliest first, and
+// postpones them by lease
+func (l *InMemoryWebhookLog) Claim(_ context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var due []domain.WebhookDelivery
+	for _, d := range l.deliveries {
+		if d.Status == domain.WebhookPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leasedUntil := now.Add(lease).UTC()
+	for _, d := range due {
+		d.NextAttemptAt = &leasedUntil
+		l.deliveries[d.ID] = d
+	}
+	return due, nil
+}
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    correlation_id VARCHAR(100) NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_created_at ON payments(created_at);
+CREATE INDEX IF NOT EXISTS idx_payments_channel ON payments(channel);
+CREATE INDEX IF NOT EXISTS idx_payments_correlation_id ON payments(correlation_id);
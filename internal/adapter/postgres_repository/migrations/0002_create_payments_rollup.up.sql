@@ -0,0 +1,16 @@
+-- Per-second totals per channel, kept up to date by every insert, so range
+-- summaries only read raw rows for the partial seconds at the edges
+CREATE TABLE IF NOT EXISTS payments_rollup (
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    channel VARCHAR(50) NOT NULL,
+    total_requests BIGINT NOT NULL,
+    total_amount DECIMAL(18, 2) NOT NULL,
+    PRIMARY KEY (bucket, channel)
+);
+
+-- Backfill the rollup for payments recorded before it existed
+INSERT INTO payments_rollup (bucket, channel, total_requests, total_amount)
+SELECT date_trunc('second', created_at), channel, COUNT(*), SUM(amount)
+FROM payments
+GROUP BY 1, 2
+ON CONFLICT (bucket, channel) DO NOTHING;
@@ -0,0 +1,13 @@
+-- Pending payments for the postgres queue adapter. A row is leased to one
+-- instance while it is processed and deleted once completed.
+CREATE TABLE IF NOT EXISTS payment_queue (
+    id BIGSERIAL PRIMARY KEY,
+    correlation_id VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    enqueued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    leased_by TEXT,
+    leased_until TIMESTAMP WITH TIME ZONE,
+    attempts INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_queue_leased_until ON payment_queue(leased_until);
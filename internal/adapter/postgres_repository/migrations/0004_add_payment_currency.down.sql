@@ -0,0 +1,14 @@
+-- Without a currency column other currencies would be summed with BRL, so
+-- the rollback refuses to run while such payments are recorded
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM payments WHERE currency <> 'BRL') THEN
+        RAISE EXCEPTION 'payments in currencies other than BRL are recorded, remove them before rolling back';
+    END IF;
+END
+$$;
+
+ALTER TABLE payments_rollup DROP CONSTRAINT IF EXISTS payments_rollup_pkey;
+ALTER TABLE payments_rollup DROP COLUMN IF EXISTS currency;
+ALTER TABLE payments_rollup ADD PRIMARY KEY (bucket, channel);
+ALTER TABLE payments DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,7 @@
+-- Payments carry an ISO-4217 currency; rows recorded before it existed are BRL
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+
+-- The rollup keeps one row per currency so amounts are never summed across them
+ALTER TABLE payments_rollup ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+ALTER TABLE payments_rollup DROP CONSTRAINT IF EXISTS payments_rollup_pkey;
+ALTER TABLE payments_rollup ADD PRIMARY KEY (bucket, channel, currency);
@@ -0,0 +1,3 @@
+-- The authenticated API client that requested each payment, NULL when
+-- authentication was disabled
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS client VARCHAR(100);
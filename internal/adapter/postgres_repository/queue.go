@@ -0,0 +1,397 @@
+package postgres_repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+const (
+	// queueChannel is notified on every insert so idle instances wake up
+	queueChannel = "payment_queue"
+	// queuePollInterval bounds how long an expired lease waits without a notification
+	queuePollInterval = time.Second
+	// queueMaxHold caps how long a lease is renewed, so a row a worker gave up
+	// on without acknowledging it becomes available again
+	queueMaxHold = 2 * time.Minute
+)
+
+// errLeaseLost is returned when a row was leased to another consumer before
+// this one completed it
+var errLeaseLost = errors.New("queue lease expired before the payment was completed")
+
+// PostgresQueue implements the PaymentQueue port on the payment_queue table.
+// Rows are claimed one at a time with FOR UPDATE SKIP LOCKED and leased to
+// this instance, which extends the lease while the payment is in flight; a
+// lease that expires before the row is completed makes it available again.
+type PostgresQueue struct {
+	pool     *pgxpool.Pool
+	consumer string
+	lease    time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+	// inFlight holds the leased rows per correlation ID, oldest first
+	inFlight map[string][]leasedRow
+}
+
+// leasedRow is a queue row held by this instance
+type leasedRow struct {
+	id        int64
+	claimedAt time.Time
+}
+
+// NewPostgresQueue creates a queue that leases rows to consumer for lease
+func NewPostgresQueue(connectionString string, autoMigrate bool, consumer string, lease time.Duration) (*PostgresQueue, error) {
+	pool, err := openPool(connectionString, autoMigrate)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PostgresQueue{
+		pool:     pool,
+		consumer: consumer,
+		lease:    lease,
+		ctx:      ctx,
+		cancel:   cancel,
+		inFlight: make(map[string][]leasedRow),
+	}, nil
+}
+
+// Send inserts a payment and notifies the listening instances. A payment
+// leased to this instance is retried on its own row instead, so the row keeps
+// counting its attempts.
+func (q *PostgresQueue) Send(payment domain.Payment) error {
+	if q.ctx.Err() != nil {
+		return fmt.Errorf("queue is closed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	paymentData, err := json.Marshal(payment)
+	if err != nil {
+		return fmt.Errorf("failed to serialize payment: %w", err)
+	}
+
+	if id, ok := q.take(payment.CorrelationId); ok {
+		return q.release(ctx, id, paymentData)
+	}
+
+	_, err = q.pool.Exec(ctx, `
+		WITH inserted AS (
+			INSERT INTO payment_queue (correlation_id, payload) VALUES ($1, $2) RETURNING id
+		)
+		SELECT pg_notify($3, '') FROM inserted`,
+		payment.CorrelationId, paymentData, queueChannel)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue payment: %w", err)
+	}
+
+	return nil
+}
+
+// SendBatch inserts the payments in one statement and notifies the listening
+// instances
+func (q *PostgresQueue) SendBatch(payments []domain.Payment) error {
+	if q.ctx.Err() != nil {
+		return fmt.Errorf("queue is closed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	correlationIDs := make([]string, len(payments))
+	payloads := make([]string, len(payments))
+	for i, payment := range payments {
+		paymentData, err := json.Marshal(payment)
+		if err != nil {
+			return fmt.Errorf("failed to serialize payment: %w", err)
+		}
+		correlationIDs[i] = payment.CorrelationId
+		payloads[i] = string(paymentData)
+	}
+
+	_, err := q.pool.Exec(ctx, `
+		WITH inserted AS (
+			INSERT INTO payment_queue (correlation_id, payload)
+			SELECT correlation_id, payload::jsonb
+			FROM unnest($1::text[], $2::text[]) AS batch(correlation_id, payload)
+			RETURNING id
+		)
+		SELECT pg_notify($3, '') WHERE EXISTS (SELECT 1 FROM inserted)`,
+		correlationIDs, payloads, queueChannel)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue payments: %w", err)
+	}
+
+	return nil
+}
+
+// Receive returns a channel that delivers leased payments
+func (q *PostgresQueue) Receive() <-chan domain.Payment {
+	// Unbuffered, so rows are only leased when a worker is ready for them
+	paymentChan := make(chan domain.Payment)
+
+	go q.renewLeases()
+	go func() {
+		defer close(paymentChan)
+
+		for q.ctx.Err() == nil {
+			if err := q.listen(paymentChan); err != nil && q.ctx.Err() == nil {
+				fmt.Printf("Error reading Postgres queue: %v\n", err)
+				time.Sleep(queuePollInterval)
+			}
+		}
+	}()
+
+	return paymentChan
+}
+
+// listen claims payments until none are left, then waits for a notification
+// or the poll interval on a dedicated connection
+func (q *PostgresQueue) listen(paymentChan chan<- domain.Payment) error {
+	conn, err := q.pool.Acquire(q.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// The connection may still be listening, so do not return it to the pool
+	defer conn.Hijack().Close(context.Background())
+
+	if _, err := conn.Exec(q.ctx, "LISTEN "+queueChannel); err != nil {
+		return fmt.Errorf("failed to listen for payments: %w", err)
+	}
+
+	for q.ctx.Err() == nil {
+		claimed, err := q.claim(paymentChan)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			continue // there may be more waiting
+		}
+
+		waitCtx, cancel := context.WithTimeout(q.ctx, queuePollInterval)
+		_, err = conn.Conn().WaitForNotification(waitCtx)
+		cancel()
+		if err != nil && !pgconn.Timeout(err) && q.ctx.Err() == nil {
+			return fmt.Errorf("failed to wait for payments: %w", err)
+		}
+	}
+	return nil
+}
+
+// claim leases the oldest available row and hands it to the next ready
+// worker. Only one row waits for a worker at a time, and its lease is renewed
+// like the ones being processed.
+func (q *PostgresQueue) claim(paymentChan chan<- domain.Payment) (bool, error) {
+	ctx, cancel := context.WithTimeout(q.ctx, 3*time.Second)
+	defer cancel()
+
+	var id int64
+	var payload []byte
+	err := q.pool.QueryRow(ctx, `
+		UPDATE payment_queue SET
+			leased_by = $1,
+			leased_until = NOW() + $2::interval,
+			attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM payment_queue
+			WHERE leased_until IS NULL OR leased_until < NOW()
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload`,
+		q.consumer, q.lease).Scan(&id, &payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim payment: %w", err)
+	}
+
+	var payment domain.Payment
+	if err := json.Unmarshal(payload, &payment); err != nil {
+		return false, fmt.Errorf("failed to read claimed payment: %w", err)
+	}
+
+	q.mu.Lock()
+	q.inFlight[payment.CorrelationId] = append(q.inFlight[payment.CorrelationId], leasedRow{id: id, claimedAt: time.Now()})
+	q.mu.Unlock()
+
+	select {
+	case paymentChan <- payment:
+	case <-q.ctx.Done():
+	}
+	return true, nil
+}
+
+// renewLeases extends the leases of the rows held by this instance every
+// third of the lease, so slow payments are not handed out twice
+func (q *PostgresQueue) renewLeases() {
+	ticker := time.NewTicker(q.lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ids := q.leased()
+		if len(ids) == 0 {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(q.ctx, 3*time.Second)
+		_, err := q.pool.Exec(ctx, `
+			UPDATE payment_queue SET leased_until = NOW() + $3::interval
+			WHERE id = ANY($1) AND leased_by = $2`,
+			ids, q.consumer, q.lease)
+		cancel()
+		if err != nil && q.ctx.Err() == nil {
+			fmt.Printf("Error renewing Postgres queue leases: %v\n", err)
+		}
+	}
+}
+
+// leased returns the IDs of the rows held by this instance for less than
+// queueMaxHold
+func (q *PostgresQueue) leased() []int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var ids []int64
+	for _, rows := range q.inFlight {
+		for _, row := range rows {
+			if time.Since(row.claimedAt) < queueMaxHold {
+				ids = append(ids, row.id)
+			}
+		}
+	}
+	return ids
+}
+
+// take removes and returns the oldest leased row of a payment
+func (q *PostgresQueue) take(correlationID string) (int64, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	rows := q.inFlight[correlationID]
+	if len(rows) == 0 {
+		return 0, false
+	}
+	if len(rows) == 1 {
+		delete(q.inFlight, correlationID)
+	} else {
+		q.inFlight[correlationID] = rows[1:]
+	}
+	return rows[0].id, true
+}
+
+// release gives up the lease of a row, storing the payment as it is now, and
+// notifies the listening instances. A row leased to another consumer in the
+// meantime is left alone, as that consumer will process it.
+func (q *PostgresQueue) release(ctx context.Context, id int64, payload []byte) error {
+	_, err := q.pool.Exec(ctx, `
+		WITH released AS (
+			UPDATE payment_queue SET payload = $3, leased_by = NULL, leased_until = NULL
+			WHERE id = $1 AND leased_by = $2
+			RETURNING id
+		)
+		SELECT pg_notify($4, '') FROM released`,
+		id, q.consumer, payload, queueChannel)
+	if err != nil {
+		return fmt.Errorf("failed to release queue item: %w", err)
+	}
+	return nil
+}
+
+// Ack completes the oldest leased row of the payment. Payments already
+// completed by a CompletingRepository are ignored.
+func (q *PostgresQueue) Ack(payment domain.Payment) error {
+	id, ok := q.take(payment.CorrelationId)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return completeQueueItem(ctx, q.pool, id, q.consumer)
+}
+
+// completeQueueItem deletes a row while this consumer still holds its lease.
+// It returns errLeaseLost when the row was leased to another consumer.
+func completeQueueItem(ctx context.Context, db execer, id int64, consumer string) error {
+	tag, err := db.Exec(ctx, "DELETE FROM payment_queue WHERE id = $1 AND leased_by = $2", id, consumer)
+	if err != nil {
+		return fmt.Errorf("failed to complete queue item: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to complete queue item %d: %w", id, errLeaseLost)
+	}
+	return nil
+}
+
+// Close stops leasing rows and closes the pool. Leased rows become available
+// to other instances when their lease expires.
+func (q *PostgresQueue) Close() error {
+	if q.ctx.Err() != nil {
+		return nil
+	}
+
+	q.cancel()
+	q.pool.Close()
+	return nil
+}
+
+// CompletingRepository records a payment and completes its queue item in the
+// same transaction, so a crash can never leave a payment both recorded and
+// still queued
+type CompletingRepository struct {
+	*PostgresRepository
+	queue *PostgresQueue
+}
+
+// NewCompletingRepository wraps a repository to complete items of queue
+func NewCompletingRepository(repository *PostgresRepository, queue *PostgresQueue) *CompletingRepository {
+	return &CompletingRepository{PostgresRepository: repository, queue: queue}
+}
+
+// Add records the payment and deletes its leased queue row. When the lease
+// was lost the processor has still accepted the payment, so it is recorded on
+// its own and the row is left to the consumer now holding it.
+func (r *CompletingRepository) Add(payment domain.Payment, channel domain.ProcessorChannel) error {
+	id, ok := r.queue.take(payment.CorrelationId)
+	if !ok {
+		return r.PostgresRepository.Add(payment, channel)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := insertPayment(ctx, tx, payment, channel); err != nil {
+			return err
+		}
+		return completeQueueItem(ctx, tx, id, r.queue.consumer)
+	})
+	if errors.Is(err, errLeaseLost) {
+		fmt.Printf("Recording payment %s without completing its queue item: %v\n", payment.CorrelationId, err)
+		return r.PostgresRepository.Add(payment, channel)
+	}
+	return err
+}
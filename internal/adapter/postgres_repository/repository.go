@@ -2,9 +2,13 @@ package postgres_repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
 )
@@ -14,8 +18,19 @@ type PostgresRepository struct {
 	pool *pgxpool.Pool
 }
 
-// NewPostgresRepository creates a new PostgreSQL payment repository
-func NewPostgresRepository(connectionString string) (*PostgresRepository, error) {
+// NewPostgresRepository creates a new PostgreSQL payment repository. With
+// autoMigrate, pending schema migrations are applied before it is returned.
+func NewPostgresRepository(connectionString string, autoMigrate bool) (*PostgresRepository, error) {
+	pool, err := openPool(connectionString, autoMigrate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresRepository{pool: pool}, nil
+}
+
+// openPool connects to the database, applying pending migrations if asked to
+func openPool(connectionString string, autoMigrate bool) (*pgxpool.Pool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -27,13 +42,32 @@ func NewPostgresRepository(connectionString string) (*PostgresRepository, error)
 
 	// Check connection
 	if err = pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Initialize repository
-	repo := &PostgresRepository{pool: pool}
+	if autoMigrate {
+		migrator, err := NewMigrator(pool)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		for _, m := range applied {
+			fmt.Printf("Applied migration %d_%s\n", m.Version, m.Name)
+		}
+	}
+
+	return pool, nil
+}
 
-	return repo, nil
+// Migrator returns a migrator for the repository schema
+func (r *PostgresRepository) Migrator() (*Migrator, error) {
+	return NewMigrator(r.pool)
 }
 
 // Close closes the database connection pool
@@ -43,14 +77,34 @@ func (r *PostgresRepository) Close() {
 	}
 }
 
-// Add records a payment in the specified channel
-func (r *PostgresRepository) Add(correlationID string, channel domain.ProcessorChannel, amount float64) error {
+// Add records a payment in the specified channel and adds it to the
+// per-second rollup in the same statement
+func (r *PostgresRepository) Add(payment domain.Payment, channel domain.ProcessorChannel) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := r.pool.Exec(ctx,
-		"INSERT INTO payments (correlation_id, channel, amount) VALUES ($1, $2, $3)",
-		correlationID, channel.String(), amount)
+	return insertPayment(ctx, r.pool, payment, channel)
+}
+
+// execer is implemented by the pool and by transactions
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// insertPayment records a payment and updates its rollup bucket
+func insertPayment(ctx context.Context, db execer, payment domain.Payment, channel domain.ProcessorChannel) error {
+	_, err := db.Exec(ctx, `
+		WITH inserted AS (
+			INSERT INTO payments (correlation_id, channel, amount, currency, client)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+			RETURNING channel, amount, currency, created_at
+		)
+		INSERT INTO payments_rollup (bucket, channel, currency, total_requests, total_amount)
+		SELECT date_trunc('second', created_at), channel, currency, 1, amount FROM inserted
+		ON CONFLICT (bucket, channel, currency) DO UPDATE SET
+			total_requests = payments_rollup.total_requests + 1,
+			total_amount = payments_rollup.total_amount + EXCLUDED.total_amount`,
+		payment.CorrelationId, channel.String(), payment.Amount, payment.CurrencyOrDefault().String(), payment.Client)
 
 	if err != nil {
 		return fmt.Errorf("failed to insert payment record: %w", err)
@@ -59,45 +113,153 @@ func (r *PostgresRepository) Add(correlationID string, channel domain.ProcessorC
 	return nil
 }
 
+// Find returns the latest record of a payment
+func (r *PostgresRepository) Find(correlationID string) (domain.PaymentRecord, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+recordColumns+`
+		FROM payments WHERE correlation_id = $1
+		ORDER BY id DESC LIMIT 1`, correlationID)
+	record, err := scanRecord(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.PaymentRecord{}, false, nil
+	}
+	if err != nil {
+		return domain.PaymentRecord{}, false, fmt.Errorf("failed to find payment: %w", err)
+	}
+	return record, true, nil
+}
+
+// Export streams the matching payments from the query cursor, so they are
+// never all held in memory
+func (r *PostgresRepository) Export(ctx context.Context, filter domain.PaymentRecordFilter, fn func(domain.PaymentRecord) error) error {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"TRUE"}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= "+arg(filter.From.UTC()))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at <= "+arg(filter.To.UTC()))
+	}
+	if filter.Channel != "" {
+		conditions = append(conditions, "channel = "+arg(filter.Channel.String()))
+	}
+	if filter.Client != "" {
+		conditions = append(conditions, "client = "+arg(filter.Client))
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+recordColumns+`
+		FROM payments WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY created_at, id`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query payments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan payment: %w", err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read payments: %w", err)
+	}
+	return nil
+}
+
+// recordColumns are the payments columns read by scanRecord
+const recordColumns = "correlation_id, amount, currency, client, channel, created_at"
+
+// scanRecord reads a payment record selected with recordColumns
+func scanRecord(row pgx.Row) (domain.PaymentRecord, error) {
+	var (
+		record   domain.PaymentRecord
+		channel  string
+		currency string
+		client   *string
+	)
+	if err := row.Scan(&record.CorrelationId, &record.Amount, &currency, &client, &channel, &record.ProcessedAt); err != nil {
+		return domain.PaymentRecord{}, err
+	}
+
+	record.Currency = domain.Currency(currency)
+	record.Channel = domain.ProcessorChannel(channel)
+	record.ProcessedAt = record.ProcessedAt.UTC()
+	if client != nil {
+		record.Client = *client
+	}
+	return record, nil
+}
+
 // GetSummary returns a summary of all payment channels for all time
 func (r *PostgresRepository) GetSummary() (domain.PaymentsSummary, error) {
 	return r.GetSummaryInRange(nil, nil)
 }
 
-// GetSummaryInRange returns a summary filtered by the provided time range
+// GetSummaryInRange returns a summary filtered by the provided time range.
+// Whole seconds inside the range are read from the rollup table and only the
+// partial seconds at the edges from the raw payments.
 func (r *PostgresRepository) GetSummaryInRange(from, to *time.Time) (domain.PaymentsSummary, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	var query string
 	var args []interface{}
-	var argPosition int = 1
-
-	// Base query
-	query = `
-		SELECT 
-			channel, 
-			COUNT(*) as total_requests, 
-			SUM(amount) as total_amount 
-		FROM payments
-		WHERE 1=1
-	`
-
-	// Add time range filters if provided
-	if from != nil {
-		query += fmt.Sprintf(" AND created_at >= $%d", argPosition)
-		args = append(args, from.UTC())
-		argPosition++
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	if to != nil {
-		query += fmt.Sprintf(" AND created_at <= $%d", argPosition)
-		args = append(args, to.UTC())
-		argPosition++
-	}
+	lo, hi := domain.WholeSecondBounds(from, to)
+
+	var query string
+	if lo != nil && hi != nil && !lo.Before(*hi) {
+		// No whole second inside the range
+		query = "SELECT channel, currency, COUNT(*), SUM(amount) FROM payments WHERE created_at >= " +
+			arg(from.UTC()) + " AND created_at <= " + arg(to.UTC()) + " GROUP BY channel, currency"
+	} else {
+		buckets := "TRUE"
+		if lo != nil {
+			buckets = "bucket >= " + arg(*lo)
+		}
+		if hi != nil {
+			buckets += " AND bucket < " + arg(*hi)
+		}
 
-	// Group by channel
-	query += " GROUP BY channel"
+		edges := []string{"FALSE"}
+		if from != nil {
+			edges = append(edges, "(created_at >= "+arg(from.UTC())+" AND created_at < "+arg(*lo)+")")
+		}
+		if to != nil {
+			edges = append(edges, "(created_at >= "+arg(*hi)+" AND created_at <= "+arg(to.UTC())+")")
+		}
+
+		query = `
+			SELECT channel, currency, SUM(total_requests)::bigint, SUM(total_amount)
+			FROM (
+				SELECT channel, currency, total_requests, total_amount
+				FROM payments_rollup
+				WHERE ` + buckets + `
+				UNION ALL
+				SELECT channel, currency, COUNT(*), SUM(amount)
+				FROM payments
+				WHERE ` + strings.Join(edges, " OR ") + `
+				GROUP BY channel, currency
+			) AS parts
+			GROUP BY channel, currency
+		`
+	}
 
 	// Execute the query
 	rows, err := r.pool.Query(ctx, query, args...)
@@ -106,31 +268,20 @@ func (r *PostgresRepository) GetSummaryInRange(from, to *time.Time) (domain.Paym
 	}
 	defer rows.Close()
 
-	// Initialize summary with zero values
-	summary := domain.PaymentsSummary{
-		Default:  domain.PaymentsChannelStats{},
-		Fallback: domain.PaymentsChannelStats{},
-	}
+	// Default and fallback report zero values when they have no rows
+	var summary domain.PaymentsSummary
 
 	// Process results
 	for rows.Next() {
-		var channel string
+		var channel, currency string
 		var totalRequests int
 		var totalAmount float64
 
-		if err := rows.Scan(&channel, &totalRequests, &totalAmount); err != nil {
+		if err := rows.Scan(&channel, &currency, &totalRequests, &totalAmount); err != nil {
 			return domain.PaymentsSummary{}, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		// Update appropriate channel stats
-		switch channel {
-		case domain.DefaultProcessor.String():
-			summary.Default.TotalRequests = totalRequests
-			summary.Default.TotalAmount = totalAmount
-		case domain.FallbackProcessor.String():
-			summary.Fallback.TotalRequests = totalRequests
-			summary.Fallback.TotalAmount = totalAmount
-		}
+		summary.Record(domain.ProcessorChannel(channel), domain.Currency(currency), totalRequests, totalAmount)
 	}
 
 	// Check for errors from iterating over rows
@@ -140,3 +291,63 @@ func (r *PostgresRepository) GetSummaryInRange(from, to *time.Time) (domain.Paym
 
 	return summary, nil
 }
+
+// GetTimeseries returns one summary per bucket between from and to, inclusive.
+// Buckets come from generate_series so empty ones are reported as zero.
+func (r *PostgresRepository) GetTimeseries(from, to time.Time, bucket domain.TimeseriesBucket) ([]domain.SummaryPoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT
+			b.start,
+			p.channel,
+			p.currency,
+			COUNT(p.id) AS total_requests,
+			COALESCE(SUM(p.amount), 0) AS total_amount
+		FROM generate_series(
+			date_trunc($3, $1::timestamptz, 'UTC'),
+			date_trunc($3, $2::timestamptz, 'UTC'),
+			$4::interval
+		) AS b(start)
+		LEFT JOIN payments p
+			ON date_trunc($3, p.created_at, 'UTC') = b.start
+			AND p.created_at >= $1
+			AND p.created_at <= $2
+		GROUP BY b.start, p.channel, p.currency
+		ORDER BY b.start
+	`
+
+	rows, err := r.pool.Query(ctx, query, from.UTC(), to.UTC(), bucket.Unit(), bucket.Duration())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payments timeseries: %w", err)
+	}
+	defer rows.Close()
+
+	var points []domain.SummaryPoint
+	for rows.Next() {
+		var start time.Time
+		var channel, currency *string
+		var totalRequests int
+		var totalAmount float64
+
+		if err := rows.Scan(&start, &channel, &currency, &totalRequests, &totalAmount); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		start = start.UTC()
+		if len(points) == 0 || !points[len(points)-1].Start.Equal(start) {
+			points = append(points, domain.SummaryPoint{Start: start})
+		}
+		// Empty buckets come back as a single row without a channel
+		if channel != nil && currency != nil {
+			points[len(points)-1].Summary.Record(domain.ProcessorChannel(*channel), domain.Currency(*currency), totalRequests, totalAmount)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return points, nil
+}
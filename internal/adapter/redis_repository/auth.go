@@ -0,0 +1,184 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultAPIKeysKey   = "api_keys"
+	defaultReplayPrefix = "signature:"
+	apiKeyWatchRetries  = 5
+)
+
+// RedisAPIKeyStore implements the APIKeyStore port with a Redis hash of
+// keys by ID, so a key rotated on one instance works on all of them
+type RedisAPIKeyStore struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisAPIKeyStore creates a new Redis API key store
+func NewRedisAPIKeyStore(redisURL string, key string) (*RedisAPIKeyStore, error) {
+	client, err := newClient(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == "" {
+		key = defaultAPIKeysKey
+	}
+
+	return &RedisAPIKeyStore{client: client, key: key}, nil
+}
+
+// Get returns the key with the given ID
+func (s *RedisAPIKeyStore) Get(ctx context.Context, id string) (domain.APIKey, bool, error) {
+	data, err := s.client.HGet(ctx, s.key, id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return domain.APIKey{}, false, nil
+	}
+	if err != nil {
+		return domain.APIKey{}, false, fmt.Errorf("failed to read API key: %w", err)
+	}
+
+	var key domain.APIKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return domain.APIKey{}, false, fmt.Errorf("failed to deserialize API key: %w", err)
+	}
+	return key, true, nil
+}
+
+// List returns every key, oldest first
+func (s *RedisAPIKeyStore) List(ctx context.Context) ([]domain.APIKey, error) {
+	values, err := s.client.HGetAll(ctx, s.key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return decodeAPIKeys(values)
+}
+
+// Add stores a key unless one with the same ID exists
+func (s *RedisAPIKeyStore) Add(ctx context.Context, key domain.APIKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("failed to serialize API key: %w", err)
+	}
+	if err := s.client.HSetNX(ctx, s.key, key.ID, data).Err(); err != nil {
+		return fmt.Errorf("failed to store API key: %w", err)
+	}
+	return nil
+}
+
+// ExpireClient makes the keys of client stop working at the given time. The
+// hash is watched so concurrent rotations do not overwrite each other.
+func (s *RedisAPIKeyStore) ExpireClient(ctx context.Context, client string, at time.Time) (int, error) {
+	changed := 0
+	expire := func(tx *redis.Tx) error {
+		changed = 0
+		values, err := tx.HGetAll(ctx, s.key).Result()
+		if err != nil {
+			return err
+		}
+		keys, err := decodeAPIKeys(values)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		for _, key := range keys {
+			if key.Client != client || (key.ExpiresAt != nil && !key.ExpiresAt.After(at)) {
+				continue
+			}
+			key.ExpiresAt = &at
+			data, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			updates[key.ID] = data
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, s.key, updates)
+			return nil
+		})
+		changed = len(updates)
+		return err
+	}
+
+	for range apiKeyWatchRetries {
+		err := s.client.Watch(ctx, expire, s.key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to expire API keys: %w", err)
+		}
+		return changed, nil
+	}
+	return 0, fmt.Errorf("failed to expire API keys: too many concurrent changes")
+}
+
+// Close closes the Redis connection
+func (s *RedisAPIKeyStore) Close() error {
+	return s.client.Close()
+}
+
+// decodeAPIKeys decodes the hash values, oldest key first
+func decodeAPIKeys(values map[string]string) ([]domain.APIKey, error) {
+	keys := make([]domain.APIKey, 0, len(values))
+	for _, value := range values {
+		var key domain.APIKey
+		if err := json.Unmarshal([]byte(value), &key); err != nil {
+			return nil, fmt.Errorf("failed to deserialize API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// RedisReplayCache implements the ReplayCache port with expiring keys, so a
+// signature accepted by one instance is rejected by the others
+type RedisReplayCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisReplayCache creates a new Redis replay cache
+func NewRedisReplayCache(redisURL string, prefix string) (*RedisReplayCache, error) {
+	client, err := newClient(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix == "" {
+		prefix = defaultReplayPrefix
+	}
+
+	return &RedisReplayCache{client: client, prefix: prefix}, nil
+}
+
+// Remember records id for ttl and reports false if it was already recorded
+func (c *RedisReplayCache) Remember(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	fresh, err := c.client.SetNX(ctx, c.prefix+id, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to record signature: %w", err)
+	}
+	return fresh, nil
+}
+
+// Close closes the Redis connection
+func (c *RedisReplayCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,156 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultPaymentEventsChannel = "payment_events"
+	// paymentEventBuffer is how many events wait to be published before new
+	// ones are dropped
+	paymentEventBuffer = 4096
+	// paymentEventBatch bounds how many events are sent in one pipeline
+	paymentEventBatch = 128
+)
+
+// RedisPaymentEventBus implements the PaymentEventBus port using Redis
+// pub/sub. Events are published in the background, in pipelined batches, so
+// payments never wait for the stream.
+type RedisPaymentEventBus struct {
+	client  *redis.Client
+	channel string
+
+	pending chan domain.PaymentEvent
+	done    chan struct{}
+	closed  sync.Once
+	wg      sync.WaitGroup
+}
+
+// NewRedisPaymentEventBus creates a new Redis pub/sub payment event bus
+func NewRedisPaymentEventBus(redisURL string, channel string) (*RedisPaymentEventBus, error) {
+	client, err := newClient(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel == "" {
+		channel = defaultPaymentEventsChannel
+	}
+
+	b := &RedisPaymentEventBus{
+		client:  client,
+		channel: channel,
+		pending: make(chan domain.PaymentEvent, paymentEventBuffer),
+		done:    make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.publishPending()
+	return b, nil
+}
+
+// Publish queues the event for every subscribed instance, dropping it when
+// the queue is full
+func (b *RedisPaymentEventBus) Publish(_ context.Context, event domain.PaymentEvent) error {
+	select {
+	case b.pending <- event:
+		return nil
+	default:
+		return fmt.Errorf("payment event queue is full, dropped %s", event.Type)
+	}
+}
+
+// publishPending sends queued events until the bus is closed
+func (b *RedisPaymentEventBus) publishPending() {
+	defer b.wg.Done()
+
+	for {
+		var batch []domain.PaymentEvent
+		select {
+		case event := <-b.pending:
+			batch = append(batch, event)
+		case <-b.done:
+			return
+		}
+	fill:
+		for len(batch) < paymentEventBatch {
+			select {
+			case event := <-b.pending:
+				batch = append(batch, event)
+			default:
+				break fill
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		pipe := b.client.Pipeline()
+		for _, event := range batch {
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			pipe.Publish(ctx, b.channel, data)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			fmt.Printf("Failed to publish %d payment events: %v\n", len(batch), err)
+		}
+		cancel()
+	}
+}
+
+// Subscribe returns a channel of payment events, closed when ctx is done
+func (b *RedisPaymentEventBus) Subscribe(ctx context.Context) (<-chan domain.PaymentEvent, error) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+
+	// Wait for the subscription to be confirmed so no event published after
+	// Subscribe returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to payment events: %w", err)
+	}
+
+	events := make(chan domain.PaymentEvent, paymentEventBatch)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event domain.PaymentEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					fmt.Printf("Failed to deserialize payment event: %v\n", err)
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// Close stops publishing and closes the Redis connection
+func (b *RedisPaymentEventBus) Close() error {
+	b.closed.Do(func() { close(b.done) })
+	b.wg.Wait()
+	return b.client.Close()
+}
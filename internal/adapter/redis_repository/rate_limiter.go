@@ -0,0 +1,85 @@
+package redis_repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRateLimitPrefix = "ratelimit:"
+
+// RedisRateLimiter implements the RateLimiter port with one Redis hash per
+// bucket, so every instance draws from the same buckets
+type RedisRateLimiter struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisRateLimiter creates a new Redis rate limiter
+func NewRedisRateLimiter(redisURL string, prefix string) (*RedisRateLimiter, error) {
+	client, err := newClient(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix == "" {
+		prefix = defaultRateLimitPrefix
+	}
+
+	return &RedisRateLimiter{client: client, prefix: prefix}, nil
+}
+
+// takeScript refills the bucket with the Redis clock, so instances with
+// skewed clocks agree, takes a token if there is one and expires the bucket
+// once it would be full again. Tokens are returned as a string to keep the
+// fraction.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local tokens = burst
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+if bucket[1] then
+	tokens = math.min(burst, tonumber(bucket[1]) + math.max(0, now - tonumber(bucket[2])) * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// Take takes one token from the bucket of key, creating it full
+func (l *RedisRateLimiter) Take(ctx context.Context, key string, quota domain.RateLimitQuota) (domain.RateLimitDecision, error) {
+	result, err := takeScript.Run(ctx, l.client, []string{l.prefix + key}, quota.Rate, quota.Burst).Slice()
+	if err != nil {
+		return domain.RateLimitDecision{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if len(result) != 2 {
+		return domain.RateLimitDecision{}, fmt.Errorf("unexpected rate limit script result: %v", result)
+	}
+
+	allowed, _ := result[0].(int64)
+	raw, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return domain.RateLimitDecision{}, fmt.Errorf("invalid rate limit token count %q: %w", raw, err)
+	}
+
+	return quota.Decide(allowed == 1, tokens), nil
+}
+
+// Close closes the Redis connection
+func (l *RedisRateLimiter) Close() error {
+	return l.client.Close()
+}
@@ -3,6 +3,7 @@ package redis_repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,8 +13,10 @@ import (
 
 const (
 	defaultQueueKey = "payment_queue"
-	uuidPrefix      = "uuid:"
-	queueTimeout    = 5 * time.Second
+	// deadLetterSuffix names the dead-letter list after the queue key
+	deadLetterSuffix = ":dead"
+	uuidPrefix       = "uuid:"
+	queueTimeout     = 5 * time.Second
 )
 
 // RedisQueue implements the PaymentQueue port using Redis lists
@@ -59,8 +62,8 @@ func (q *RedisQueue) Send(payment domain.Payment) error {
 	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
 	defer cancel()
 
-	// Serialize the payment
-	paymentData, err := json.Marshal(payment)
+	// Serialize the payment with its enqueue time for queue inspection
+	paymentData, err := json.Marshal(domain.QueuedPayment{Payment: payment, EnqueuedAt: time.Now().UTC()})
 	if err != nil {
 		return fmt.Errorf("failed to serialize payment: %w", err)
 	}
@@ -73,6 +76,32 @@ func (q *RedisQueue) Send(payment domain.Payment) error {
 	return nil
 }
 
+// SendBatch adds the payments to the Redis list with a single RPUSH
+func (q *RedisQueue) SendBatch(payments []domain.Payment) error {
+	if q.closed {
+		return fmt.Errorf("queue is closed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	values := make([]interface{}, 0, len(payments))
+	for _, payment := range payments {
+		paymentData, err := json.Marshal(domain.QueuedPayment{Payment: payment, EnqueuedAt: now})
+		if err != nil {
+			return fmt.Errorf("failed to serialize payment: %w", err)
+		}
+		values = append(values, paymentData)
+	}
+
+	if err := q.client.RPush(ctx, q.queueKey, values...).Err(); err != nil {
+		return fmt.Errorf("failed to push payments to queue: %w", err)
+	}
+
+	return nil
+}
+
 // Receive returns a channel that delivers payments from the queue
 func (q *RedisQueue) Receive() <-chan domain.Payment {
 	// Use a buffered channel to reduce the chance of timeout
@@ -122,8 +151,7 @@ func (q *RedisQueue) Receive() <-chan domain.Payment {
 			case <-time.After(5 * time.Second):
 				// Timeout, put the payment back in the queue
 				fmt.Printf("Timed out sending to channel, requeueing payment: %s\n", payment.CorrelationId)
-				payloadBytes, _ := json.Marshal(payment)
-				q.client.RPush(context.Background(), q.queueKey, payloadBytes)
+				q.client.RPush(context.Background(), q.queueKey, result[1])
 			}
 		}
 	}()
@@ -131,6 +159,155 @@ func (q *RedisQueue) Receive() <-chan domain.Payment {
 	return paymentChan
 }
 
+// key returns the Redis list holding a queue
+func (q *RedisQueue) key(queue domain.QueueName) string {
+	if queue == domain.DeadLetterQueue {
+		return q.queueKey + deadLetterSuffix
+	}
+	return q.queueKey
+}
+
+// Stats returns the depth and oldest payment of a queue
+func (q *RedisQueue) Stats(queue domain.QueueName) (domain.QueueStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	pipe := q.client.Pipeline()
+	depth := pipe.LLen(ctx, q.key(queue))
+	head := pipe.LIndex(ctx, q.key(queue), 0)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return domain.QueueStats{}, fmt.Errorf("failed to read queue stats: %w", err)
+	}
+
+	stats := domain.QueueStats{Queue: queue, Depth: int(depth.Val())}
+	var oldest domain.QueuedPayment
+	if head.Err() == nil && json.Unmarshal([]byte(head.Val()), &oldest) == nil && !oldest.EnqueuedAt.IsZero() {
+		stats.OldestEnqueuedAt = &oldest.EnqueuedAt
+	}
+	return stats, nil
+}
+
+// List returns up to limit payments of a queue starting at offset
+func (q *RedisQueue) List(queue domain.QueueName, offset, limit int) ([]domain.QueuedPayment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	values, err := q.client.LRange(ctx, q.key(queue), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue: %w", err)
+	}
+
+	payments := make([]domain.QueuedPayment, 0, len(values))
+	for _, value := range values {
+		var payment domain.QueuedPayment
+		if err := json.Unmarshal([]byte(value), &payment); err != nil {
+			return nil, fmt.Errorf("failed to deserialize payment: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+	return payments, nil
+}
+
+// moveScript moves one exact entry between lists, unless a worker took it first
+var moveScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 1 then
+	redis.call('RPUSH', KEYS[2], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+// Move moves payments between queues, all of them when no IDs are given
+func (q *RedisQueue) Move(from, to domain.QueueName, correlationIDs []string) ([]domain.QueuedPayment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	source, target := q.key(from), q.key(to)
+	var moved []domain.QueuedPayment
+
+	if len(correlationIDs) == 0 {
+		for {
+			value, err := q.client.LMove(ctx, source, target, "LEFT", "RIGHT").Result()
+			if errors.Is(err, redis.Nil) {
+				return moved, nil
+			}
+			if err != nil {
+				return moved, fmt.Errorf("failed to move payment: %w", err)
+			}
+			var payment domain.QueuedPayment
+			if json.Unmarshal([]byte(value), &payment) == nil {
+				moved = append(moved, payment)
+			}
+		}
+	}
+
+	selected := make(map[string]bool, len(correlationIDs))
+	for _, id := range correlationIDs {
+		selected[id] = true
+	}
+
+	values, err := q.client.LRange(ctx, source, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue: %w", err)
+	}
+	for _, value := range values {
+		var payment domain.QueuedPayment
+		if json.Unmarshal([]byte(value), &payment) != nil || !selected[payment.CorrelationId] {
+			continue
+		}
+		ok, err := moveScript.Run(ctx, q.client, []string{source, target}, value).Int()
+		if err != nil {
+			return moved, fmt.Errorf("failed to move payment: %w", err)
+		}
+		if ok == 1 {
+			moved = append(moved, payment)
+		}
+	}
+	return moved, nil
+}
+
+// Purge deletes every payment of a queue
+func (q *RedisQueue) Purge(queue domain.QueueName) ([]domain.QueuedPayment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	pipe := q.client.TxPipeline()
+	values := pipe.LRange(ctx, q.key(queue), 0, -1)
+	pipe.Del(ctx, q.key(queue))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to purge queue: %w", err)
+	}
+
+	purged := make([]domain.QueuedPayment, 0, len(values.Val()))
+	for _, value := range values.Val() {
+		var payment domain.QueuedPayment
+		if json.Unmarshal([]byte(value), &payment) == nil {
+			purged = append(purged, payment)
+		}
+	}
+	return purged, nil
+}
+
+// DeadLetter adds a payment to the dead-letter list
+func (q *RedisQueue) DeadLetter(payment domain.Payment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	paymentData, err := json.Marshal(domain.QueuedPayment{Payment: payment, EnqueuedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to serialize payment: %w", err)
+	}
+	if err := q.client.RPush(ctx, q.key(domain.DeadLetterQueue), paymentData).Err(); err != nil {
+		return fmt.Errorf("failed to dead-letter payment: %w", err)
+	}
+	return nil
+}
+
+// Ack is a no-op, BLPOP removes payments from the list when they are received
+func (q *RedisQueue) Ack(domain.Payment) error {
+	return nil
+}
+
 // Close stops the queue and closes resources
 func (q *RedisQueue) Close() error {
 	if q.closed {
@@ -194,6 +371,43 @@ func (s *RedisStore) Add(uuid string) error {
 	return nil
 }
 
+// addBatchScript sets each key not yet present with a TTL of ARGV[1]
+// milliseconds and returns 1 for the keys it set. It runs atomically, so a
+// failed call never leaves part of the batch reserved.
+var addBatchScript = redis.NewScript(`
+local added = {}
+for i, key in ipairs(KEYS) do
+	if redis.call('SET', key, 1, 'NX', 'PX', ARGV[1]) then
+		added[i] = 1
+	else
+		added[i] = 0
+	end
+end
+return added
+`)
+
+// AddBatch adds the UUIDs with TTL in one atomic script and reports which
+// ones were added
+func (s *RedisStore) AddBatch(uuids []string) ([]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	keys := make([]string, len(uuids))
+	for i, uuid := range uuids {
+		keys[i] = uuidPrefix + uuid
+	}
+	result, err := addBatchScript.Run(ctx, s.client, keys, s.ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to store UUIDs: %w", err)
+	}
+
+	added := make([]bool, len(uuids))
+	for i, value := range result {
+		added[i] = value == 1
+	}
+	return added, nil
+}
+
 // Exists checks if a UUID exists in the store
 func (s *RedisStore) Exists(uuid string) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -209,6 +423,34 @@ func (s *RedisStore) Exists(uuid string) bool {
 	return exists > 0
 }
 
+// Remove deletes a UUID from the store
+func (s *RedisStore) Remove(uuid string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.client.Del(ctx, uuidPrefix+uuid).Err(); err != nil {
+		return fmt.Errorf("failed to remove UUID: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveBatch deletes the UUIDs from the store in one round trip
+func (s *RedisStore) RemoveBatch(uuids []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	keys := make([]string, len(uuids))
+	for i, uuid := range uuids {
+		keys[i] = uuidPrefix + uuid
+	}
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to remove UUIDs: %w", err)
+	}
+
+	return nil
+}
+
 // Close closes the Redis connection
 func (s *RedisStore) Close() error {
 	return s.client.Close()
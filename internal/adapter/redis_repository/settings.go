@@ -0,0 +1,206 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultSettingsChannel  = "settings_changes"
+	defaultSettingsAuditKey = "settings_audit"
+	maxSettingsAuditEntries = 1000
+	// maxSettingsRecordTries bounds how often recording a change is retried
+	// while other instances record theirs
+	maxSettingsRecordTries = 10
+)
+
+// RedisSettingsBus implements the SettingsBus port using Redis pub/sub
+type RedisSettingsBus struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisSettingsBus creates a new Redis pub/sub settings bus
+func NewRedisSettingsBus(redisURL string, channel string) (*RedisSettingsBus, error) {
+	client, err := newClient(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel == "" {
+		channel = defaultSettingsChannel
+	}
+
+	return &RedisSettingsBus{client: client, channel: channel}, nil
+}
+
+// Publish broadcasts a settings change to every subscribed instance
+func (b *RedisSettingsBus) Publish(ctx context.Context, change domain.SettingsChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to serialize settings change: %w", err)
+	}
+
+	if err := b.client.Publish(ctx, b.channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish settings change: %w", err)
+	}
+	return nil
+}
+
+// Subscribe returns a channel of settings changes, closed when ctx is done
+func (b *RedisSettingsBus) Subscribe(ctx context.Context) (<-chan domain.SettingsChange, error) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+
+	// Wait for the subscription to be confirmed so no change published after
+	// Subscribe returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to settings changes: %w", err)
+	}
+
+	changes := make(chan domain.SettingsChange)
+	go func() {
+		defer close(changes)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var change domain.SettingsChange
+				if err := json.Unmarshal([]byte(msg.Payload), &change); err != nil {
+					fmt.Printf("Failed to deserialize settings change: %v\n", err)
+					continue
+				}
+
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// Close closes the Redis connection
+func (b *RedisSettingsBus) Close() error {
+	return b.client.Close()
+}
+
+// RedisSettingsAuditLog implements the SettingsAuditLog port using a capped Redis list
+type RedisSettingsAuditLog struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisSettingsAuditLog creates a new Redis-backed settings audit log
+func NewRedisSettingsAuditLog(redisURL string, key string) (*RedisSettingsAuditLog, error) {
+	client, err := newClient(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == "" {
+		key = defaultSettingsAuditKey
+	}
+
+	return &RedisSettingsAuditLog{client: client, key: key}, nil
+}
+
+// Record prepends the change to the audit list under the next version,
+// keeping the newest entries only. The version is taken in the transaction
+// that pushes the change, so the list stays in version order.
+func (l *RedisSettingsAuditLog) Record(ctx context.Context, change domain.SettingsChange) (int64, error) {
+	versionKey := l.key + ":version"
+	record := func(tx *redis.Tx) error {
+		latest, err := tx.Get(ctx, versionKey).Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		change.Version = latest + 1
+
+		data, err := json.Marshal(change)
+		if err != nil {
+			return fmt.Errorf("failed to serialize settings change: %w", err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, versionKey, change.Version, 0)
+			pipe.LPush(ctx, l.key, data)
+			pipe.LTrim(ctx, l.key, 0, maxSettingsAuditEntries-1)
+			return nil
+		})
+		return err
+	}
+
+	for range maxSettingsRecordTries {
+		err := l.client.Watch(ctx, record, versionKey)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue // another change took the version first
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to record settings change: %w", err)
+		}
+		return change.Version, nil
+	}
+	return 0, fmt.Errorf("failed to record settings change: gave up after %d concurrent changes", maxSettingsRecordTries)
+}
+
+// List returns the most recent changes first
+func (l *RedisSettingsAuditLog) List(ctx context.Context, limit int) ([]domain.SettingsChange, error) {
+	if limit <= 0 {
+		limit = maxSettingsAuditEntries
+	}
+
+	entries, err := l.client.LRange(ctx, l.key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settings changes: %w", err)
+	}
+
+	changes := make([]domain.SettingsChange, 0, len(entries))
+	for _, entry := range entries {
+		var change domain.SettingsChange
+		if err := json.Unmarshal([]byte(entry), &change); err != nil {
+			return nil, fmt.Errorf("failed to deserialize settings change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Close closes the Redis connection
+func (l *RedisSettingsAuditLog) Close() error {
+	return l.client.Close()
+}
+
+// newClient connects to Redis and checks the connection
+func newClient(redisURL string) (*redis.Client, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,384 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamPayloadField = "payment"
+	// streamReadCount is one so a message is only read, and its idle time
+	// only starts, when a worker is ready for it
+	streamReadCount = 1
+	streamBlock     = 2 * time.Second
+)
+
+// RedisStreamQueue implements the PaymentQueue port on a Redis stream. Every
+// instance reads as its own consumer of a shared group, so a payment stays
+// pending until it is acknowledged and payments left behind by a dead
+// instance are claimed by the others once idle for too long.
+type RedisStreamQueue struct {
+	client    *redis.Client
+	stream    string
+	group     string
+	consumer  string
+	claimIdle time.Duration
+	maxLen    int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+	// inFlight holds the deliveries per correlation ID, oldest first
+	inFlight map[string][]streamDelivery
+}
+
+// streamDelivery is a message handed to the workers and not yet acknowledged
+type streamDelivery struct {
+	id string
+	at time.Time
+}
+
+// NewRedisStreamQueue creates the consumer group if needed and returns a queue
+// reading as consumer. Messages idle for claimIdle are claimed from other
+// consumers, and acknowledged entries are trimmed once the stream is longer
+// than maxLen.
+func NewRedisStreamQueue(redisURL, stream, group, consumer string, claimIdle time.Duration, maxLen int64) (*RedisStreamQueue, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	// Start from the beginning so a new group picks up payments already queued
+	err = client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		client.Close()
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	queueCtx, queueCancel := context.WithCancel(context.Background())
+	return &RedisStreamQueue{
+		client:    client,
+		stream:    stream,
+		group:     group,
+		consumer:  consumer,
+		claimIdle: claimIdle,
+		maxLen:    maxLen,
+		ctx:       queueCtx,
+		cancel:    queueCancel,
+		inFlight:  make(map[string][]streamDelivery),
+	}, nil
+}
+
+// Send appends a payment to the stream
+func (q *RedisStreamQueue) Send(payment domain.Payment) error {
+	if q.ctx.Err() != nil {
+		return fmt.Errorf("queue is closed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	paymentData, err := json.Marshal(payment)
+	if err != nil {
+		return fmt.Errorf("failed to serialize payment: %w", err)
+	}
+
+	err = q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.stream,
+		Values: map[string]interface{}{streamPayloadField: paymentData},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to add payment to stream: %w", err)
+	}
+
+	return nil
+}
+
+// SendBatch appends the payments to the stream in one MULTI/EXEC transaction
+func (q *RedisStreamQueue) SendBatch(payments []domain.Payment) error {
+	if q.ctx.Err() != nil {
+		return fmt.Errorf("queue is closed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	values := make([][]byte, len(payments))
+	for i, payment := range payments {
+		paymentData, err := json.Marshal(payment)
+		if err != nil {
+			return fmt.Errorf("failed to serialize payment: %w", err)
+		}
+		values[i] = paymentData
+	}
+
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, paymentData := range values {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: q.stream,
+				Values: map[string]interface{}{streamPayloadField: paymentData},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add payments to stream: %w", err)
+	}
+
+	return nil
+}
+
+// Receive returns a channel that delivers payments from the stream. Payments
+// this consumer had pending before a restart are delivered first.
+func (q *RedisStreamQueue) Receive() <-chan domain.Payment {
+	// Unbuffered, so messages are only read when a worker is ready for them
+	paymentChan := make(chan domain.Payment)
+
+	go q.trimPeriodically()
+	go func() {
+		defer close(paymentChan)
+
+		// "0" re-reads our own pending messages, ">" reads new ones
+		pendingID := "0"
+		nextClaim := time.Now()
+
+		for q.ctx.Err() == nil {
+			if time.Now().After(nextClaim) {
+				q.claimStale(paymentChan)
+				nextClaim = time.Now().Add(q.claimIdle / 2)
+			}
+
+			readID := ">"
+			if pendingID != "" {
+				readID = pendingID
+			}
+
+			streams, err := q.client.XReadGroup(q.ctx, &redis.XReadGroupArgs{
+				Group:    q.group,
+				Consumer: q.consumer,
+				Streams:  []string{q.stream, readID},
+				Count:    streamReadCount,
+				Block:    streamBlock,
+			}).Result()
+			if err != nil {
+				if !errors.Is(err, redis.Nil) && q.ctx.Err() == nil {
+					fmt.Printf("Error reading Redis stream: %v\n", err)
+					time.Sleep(100 * time.Millisecond)
+				}
+				continue
+			}
+
+			for _, stream := range streams {
+				if pendingID != "" {
+					if len(stream.Messages) == 0 {
+						pendingID = "" // caught up on our own backlog
+					} else {
+						pendingID = stream.Messages[len(stream.Messages)-1].ID
+					}
+				}
+				for _, message := range stream.Messages {
+					q.deliver(paymentChan, message)
+				}
+			}
+		}
+	}()
+
+	return paymentChan
+}
+
+// claimStale takes over messages that other consumers left pending for too long
+func (q *RedisStreamQueue) claimStale(paymentChan chan<- domain.Payment) {
+	start := "0-0"
+	for q.ctx.Err() == nil {
+		messages, next, err := q.client.XAutoClaim(q.ctx, &redis.XAutoClaimArgs{
+			Stream:   q.stream,
+			Group:    q.group,
+			Consumer: q.consumer,
+			MinIdle:  q.claimIdle,
+			Start:    start,
+			Count:    streamReadCount,
+		}).Result()
+		if err != nil {
+			if q.ctx.Err() == nil {
+				fmt.Printf("Error claiming idle payments: %v\n", err)
+			}
+			return
+		}
+
+		for _, message := range messages {
+			q.deliver(paymentChan, message)
+		}
+		if next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+// trimPeriodically trims the stream every half claimIdle, apart from the
+// reads so a busy worker pool does not hold it back
+func (q *RedisStreamQueue) trimPeriodically() {
+	ticker := time.NewTicker(q.claimIdle / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := q.trim(); err != nil && q.ctx.Err() == nil {
+			fmt.Printf("Error trimming Redis stream: %v\n", err)
+		}
+	}
+}
+
+// trim drops acknowledged entries once the stream is longer than maxLen. Only
+// entries below the oldest pending one and the last one delivered to every
+// group are removed, so unacknowledged and unread payments are never lost.
+func (q *RedisStreamQueue) trim() error {
+	ctx, cancel := context.WithTimeout(q.ctx, queueTimeout)
+	defer cancel()
+
+	length, err := q.client.XLen(ctx, q.stream).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read stream length: %w", err)
+	}
+	if length <= q.maxLen {
+		return nil
+	}
+
+	groups, err := q.client.XInfoGroups(ctx, q.stream).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read consumer groups: %w", err)
+	}
+	minID := ""
+	for _, group := range groups {
+		if minID == "" || streamIDLess(group.LastDeliveredID, minID) {
+			minID = group.LastDeliveredID
+		}
+		pending, err := q.client.XPending(ctx, q.stream, group.Name).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read pending payments of %s: %w", group.Name, err)
+		}
+		if pending.Count > 0 && streamIDLess(pending.Lower, minID) {
+			minID = pending.Lower
+		}
+	}
+	if minID == "" {
+		return nil
+	}
+
+	if err := q.client.XTrimMinIDApprox(ctx, q.stream, minID, 0).Err(); err != nil {
+		return fmt.Errorf("failed to trim stream: %w", err)
+	}
+	return nil
+}
+
+// streamIDLess reports whether stream entry ID a sorts before b
+func streamIDLess(a, b string) bool {
+	parse := func(id string) (uint64, uint64) {
+		ms, seq, _ := strings.Cut(id, "-")
+		msValue, _ := strconv.ParseUint(ms, 10, 64)
+		seqValue, _ := strconv.ParseUint(seq, 10, 64)
+		return msValue, seqValue
+	}
+	aMs, aSeq := parse(a)
+	bMs, bSeq := parse(b)
+	return aMs < bMs || (aMs == bMs && aSeq < bSeq)
+}
+
+// deliver hands a message to the workers unless it is already being processed here
+func (q *RedisStreamQueue) deliver(paymentChan chan<- domain.Payment, message redis.XMessage) {
+	raw, _ := message.Values[streamPayloadField].(string)
+
+	var payment domain.Payment
+	if err := json.Unmarshal([]byte(raw), &payment); err != nil {
+		fmt.Printf("Failed to deserialize payment %s, dropping it: %v\n", message.ID, err)
+		q.ack(message.ID)
+		return
+	}
+
+	q.mu.Lock()
+	deliveries := q.inFlight[payment.CorrelationId]
+	redelivery := false
+	for i, d := range deliveries {
+		if d.id != message.ID {
+			continue
+		}
+		// Claimed back from ourselves: skip it while a worker may still hold it,
+		// otherwise the worker gave up without acknowledging and it is retried
+		if time.Since(d.at) < q.claimIdle {
+			q.mu.Unlock()
+			return
+		}
+		deliveries[i].at = time.Now()
+		redelivery = true
+	}
+	if !redelivery {
+		q.inFlight[payment.CorrelationId] = append(deliveries, streamDelivery{id: message.ID, at: time.Now()})
+	}
+	q.mu.Unlock()
+
+	select {
+	case paymentChan <- payment:
+	case <-q.ctx.Done():
+	}
+}
+
+// Ack acknowledges the oldest delivery of the payment
+func (q *RedisStreamQueue) Ack(payment domain.Payment) error {
+	q.mu.Lock()
+	deliveries := q.inFlight[payment.CorrelationId]
+	if len(deliveries) == 0 {
+		q.mu.Unlock()
+		return fmt.Errorf("payment %s is not in flight", payment.CorrelationId)
+	}
+	if len(deliveries) == 1 {
+		delete(q.inFlight, payment.CorrelationId)
+	} else {
+		q.inFlight[payment.CorrelationId] = deliveries[1:]
+	}
+	q.mu.Unlock()
+
+	return q.ack(deliveries[0].id)
+}
+
+func (q *RedisStreamQueue) ack(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+	defer cancel()
+
+	if err := q.client.XAck(ctx, q.stream, q.group, id).Err(); err != nil {
+		return fmt.Errorf("failed to acknowledge payment: %w", err)
+	}
+	return nil
+}
+
+// Close stops reading and closes the connection. Unacknowledged payments stay
+// pending and are claimed by another instance.
+func (q *RedisStreamQueue) Close() error {
+	if q.ctx.Err() != nil {
+		return nil
+	}
+
+	q.cancel()
+	return q.client.Close()
+}
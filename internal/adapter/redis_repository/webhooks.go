@@ -0,0 +1,156 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultWebhookPrefix = "webhook:"
+
+// claimWebhooksScript returns the IDs due at ARGV[1] and reschedules them at
+// ARGV[2], atomically so each is claimed by a single instance
+var claimWebhooksScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, id in ipairs(due) do
+	redis.call('ZADD', KEYS[1], ARGV[2], id)
+end
+return due
+`)
+
+// RedisWebhookLog implements the WebhookDeliveryLog port. Each delivery is an
+// expiring key; a sorted set by next attempt schedules the pending ones and
+// another by creation time lists the recent ones.
+type RedisWebhookLog struct {
+	client    *redis.Client
+	prefix    string
+	retention time.Duration
+}
+
+// NewRedisWebhookLog creates a new Redis webhook delivery log keeping
+// deliveries for the retention period
+func NewRedisWebhookLog(redisURL string, prefix string, retention time.Duration) (*RedisWebhookLog, error) {
+	client, err := newClient(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix == "" {
+		prefix = defaultWebhookPrefix
+	}
+
+	return &RedisWebhookLog{client: client, prefix: prefix, retention: retention}, nil
+}
+
+func (l *RedisWebhookLog) deliveryKey(id string) string { return l.prefix + "delivery:" + id }
+func (l *RedisWebhookLog) scheduleKey() string          { return l.prefix + "schedule" }
+func (l *RedisWebhookLog) recentKey() string            { return l.prefix + "recent" }
+
+// Save creates or replaces a delivery and updates its schedule
+func (l *RedisWebhookLog) Save(ctx context.Context, delivery domain.WebhookDelivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to serialize webhook delivery: %w", err)
+	}
+
+	pipe := l.client.TxPipeline()
+	pipe.Set(ctx, l.deliveryKey(delivery.ID), data, l.retention)
+	pipe.ZAdd(ctx, l.recentKey(), redis.Z{Score: float64(delivery.CreatedAt.UnixMilli()), Member: delivery.ID})
+	pipe.ZRemRangeByScore(ctx, l.recentKey(), "-inf", strconv.FormatInt(time.Now().Add(-l.retention).UnixMilli(), 10))
+	if delivery.Status == domain.WebhookPending && delivery.NextAttemptAt != nil {
+		pipe.ZAdd(ctx, l.scheduleKey(), redis.Z{Score: float64(delivery.NextAttemptAt.UnixMilli()), Member: delivery.ID})
+	} else {
+		pipe.ZRem(ctx, l.scheduleKey(), delivery.ID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Get returns the delivery with the given ID
+func (l *RedisWebhookLog) Get(ctx context.Context, id string) (domain.WebhookDelivery, bool, error) {
+	deliveries, err := l.load(ctx, []string{id})
+	if err != nil || len(deliveries) == 0 {
+		return domain.WebhookDelivery{}, false, err
+	}
+	return deliveries[0], true, nil
+}
+
+// List returns the most recent deliveries first
+func (l *RedisWebhookLog) List(ctx context.Context, limit int) ([]domain.WebhookDelivery, error) {
+	ids, err := l.client.ZRevRange(ctx, l.recentKey(), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return l.load(ctx, ids)
+}
+
+// Claim returns the pending deliveries due at now, earliest first, and
+// postpones them by lease
+func (l *RedisWebhookLog) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	ids, err := claimWebhooksScript.Run(ctx, l.client, []string{l.scheduleKey()},
+		now.UnixMilli(), now.Add(lease).UnixMilli(), limit).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	deliveries, err := l.load(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) < len(ids) {
+		// Deliveries that expired from the log are dropped from the schedule
+		found := make(map[string]bool, len(deliveries))
+		for _, d := range deliveries {
+			found[d.ID] = true
+		}
+		for _, id := range ids {
+			if !found[id] {
+				l.client.ZRem(ctx, l.scheduleKey(), id)
+			}
+		}
+	}
+	return deliveries, nil
+}
+
+// load reads the deliveries with the given IDs in order, skipping the
+// expired ones
+func (l *RedisWebhookLog) load(ctx context.Context, ids []string) ([]domain.WebhookDelivery, error) {
+	if len(ids) == 0 {
+		return []domain.WebhookDelivery{}, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = l.deliveryKey(id)
+	}
+	values, err := l.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var delivery domain.WebhookDelivery
+		if err := json.Unmarshal([]byte(data), &delivery); err != nil {
+			return nil, fmt.Errorf("failed to deserialize webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// Close closes the Redis connection
+func (l *RedisWebhookLog) Close() error {
+	return l.client.Close()
+}
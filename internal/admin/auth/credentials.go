@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when the user is unknown so that unknown
+// users take as long to reject as wrong passwords
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("unknown-user"), bcrypt.DefaultCost)
+
+// Credentials maps usernames to bcrypt password hashes
+type Credentials struct {
+	hashes map[string][]byte
+}
+
+// NewCredentials creates an empty credential set
+func NewCredentials() *Credentials {
+	return &Credentials{hashes: make(map[string][]byte)}
+}
+
+// AddPassword hashes and stores a plaintext password for a user
+func (c *Credentials) AddPassword(user, password string) error {
+	if user == "" || password == "" {
+		return errors.New("username and password are required")
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	c.hashes[user] = []byte(hash)
+	return nil
+}
+
+// AddHash stores an existing bcrypt hash for a user
+func (c *Credentials) AddHash(user, hash string) error {
+	if user == "" {
+		return errors.New("username is required")
+	}
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+		return fmt.Errorf("invalid bcrypt hash for %s: %w", user, err)
+	}
+	c.hashes[user] = []byte(hash)
+	return nil
+}
+
+// Len returns the number of users
+func (c *Credentials) Len() int {
+	return len(c.hashes)
+}
+
+// Verify reports whether the password matches the user's hash
+func (c *Credentials) Verify(user, password string) bool {
+	hash, ok := c.hashes[user]
+	if !ok {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+}
+
+// LoadPasswordFile reads "user:bcrypt-hash" lines, as written by
+// `htpasswd -B` or `admin -hash-password`. Blank lines and lines starting
+// with # are ignored.
+func LoadPasswordFile(path string, creds *Credentials) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open password file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(text, ":")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected user:hash", path, line)
+		}
+		if err := creds.AddHash(user, hash); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read password file: %w", err)
+	}
+	return nil
+}
+
+// HashPassword returns the bcrypt hash of a password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// tokensEqual compares two tokens in constant time
+func tokensEqual(a, b string) bool {
+	return a != "" && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// SessionCookie holds the session ID
+	SessionCookie = "admin_session"
+	// CSRFHeader carries the session's CSRF token on htmx requests
+	CSRFHeader = "X-CSRF-Token"
+	// CSRFField carries the CSRF token on plain form posts
+	CSRFField = "csrf_token"
+
+	loginCSRFCookie = "admin_login_csrf"
+	sessionKey      = "session"
+)
+
+// ErrInvalidCredentials is returned when a login fails
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// Authenticator handles login, logout and the session and CSRF checks
+type Authenticator struct {
+	credentials  *Credentials
+	sessions     *SessionStore
+	secureCookie bool
+}
+
+// NewAuthenticator creates an authenticator. secureCookie should be true
+// whenever the admin app is served over HTTPS.
+func NewAuthenticator(credentials *Credentials, sessions *SessionStore, secureCookie bool) *Authenticator {
+	return &Authenticator{
+		credentials:  credentials,
+		sessions:     sessions,
+		secureCookie: secureCookie,
+	}
+}
+
+// Login checks the credentials and starts a session cookie
+func (a *Authenticator) Login(c *gin.Context, user, password string) error {
+	if !a.credentials.Verify(user, password) {
+		fmt.Printf("Admin login failed for %q from %s\n", user, c.ClientIP())
+		return ErrInvalidCredentials
+	}
+
+	session, err := a.sessions.Create(user)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	a.setCookie(c, SessionCookie, session.ID, 0)
+	a.setCookie(c, loginCSRFCookie, "", -1)
+	fmt.Printf("Admin login for %q from %s\n", user, c.ClientIP())
+	return nil
+}
+
+// Logout ends the current session
+func (a *Authenticator) Logout(c *gin.Context) {
+	if id, err := c.Cookie(SessionCookie); err == nil {
+		a.sessions.Delete(id)
+	}
+	a.setCookie(c, SessionCookie, "", -1)
+}
+
+// LoginCSRFToken issues the token the login form must post back. It is
+// bound to a short-lived cookie since there is no session yet.
+func (a *Authenticator) LoginCSRFToken(c *gin.Context) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	a.setCookie(c, loginCSRFCookie, token, int((10 * time.Minute).Seconds()))
+	return token, nil
+}
+
+// CheckLoginCSRF reports whether the login form carries the token from its cookie
+func (a *Authenticator) CheckLoginCSRF(c *gin.Context) bool {
+	cookie, err := c.Cookie(loginCSRFCookie)
+	if err != nil {
+		return false
+	}
+	return tokensEqual(c.PostForm(CSRFField), cookie)
+}
+
+// RequireSession rejects requests without a valid session, and state
+// changing requests without the session's CSRF token
+func (a *Authenticator) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := c.Cookie(SessionCookie)
+		session, ok := a.sessions.Get(id)
+		if err != nil || !ok {
+			if c.GetHeader("HX-Request") != "" {
+				// htmx swaps responses into the page; ask it to navigate instead
+				c.Header("HX-Redirect", "/login")
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Redirect(http.StatusSeeOther, "/login")
+			c.Abort()
+			return
+		}
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			token := c.GetHeader(CSRFHeader)
+			if token == "" {
+				token = c.PostForm(CSRFField)
+			}
+			if !tokensEqual(token, session.CSRFToken) {
+				c.String(http.StatusForbidden, "Invalid CSRF token")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(sessionKey, session)
+		c.Next()
+	}
+}
+
+// CurrentSession returns the session set by RequireSession
+func CurrentSession(c *gin.Context) Session {
+	session, _ := c.MustGet(sessionKey).(Session)
+	return session
+}
+
+func (a *Authenticator) setCookie(c *gin.Context, name, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   a.secureCookie,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// Session is a logged in admin user
+type Session struct {
+	ID        string
+	User      string
+	CSRFToken string
+	ExpiresAt time.Time
+}
+
+// SessionStore keeps sessions in memory. Sessions are lost on restart, which
+// only means logging in again.
+type SessionStore struct {
+	ttl      time.Duration
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewSessionStore creates a session store with the given idle timeout
+func NewSessionStore(ttl time.Duration) *SessionStore {
+	return &SessionStore{
+		ttl:      ttl,
+		sessions: make(map[string]*Session),
+	}
+}
+
+// Create starts a session for the user
+func (s *SessionStore) Create(user string) (*Session, error) {
+	id, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		ID:        id,
+		User:      user,
+		CSRFToken: csrf,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+	s.sessions[id] = session
+	return session, nil
+}
+
+// Get returns a copy of a live session and extends its expiry
+func (s *SessionStore) Get(id string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	if time.Now().After(session.ExpiresAt) {
+		delete(s.sessions, id)
+		return Session{}, false
+	}
+	session.ExpiresAt = time.Now().Add(s.ttl)
+	return *session, true
+}
+
+// Delete ends a session
+func (s *SessionStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+func (s *SessionStore) removeExpired() {
+	now := time.Now()
+	for id, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// randomToken returns 32 random bytes encoded for cookies and headers
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
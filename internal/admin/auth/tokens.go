@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minTokenLength is the shortest API token accepted
+const minTokenLength = 16
+
+const operatorKey = "operator"
+
+// ParseAPITokens parses comma separated "name:token" pairs
+func ParseAPITokens(spec string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for i, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(pair, ":")
+		if !ok || name == "" {
+			// Without a colon the entry may be a bare token, so it is not echoed
+			return nil, fmt.Errorf("invalid API token entry %d, expected name:token", i+1)
+		}
+		if len(token) < minTokenLength {
+			return nil, fmt.Errorf("API token %q must have at least %d characters", name, minTokenLength)
+		}
+		tokens[name] = token
+	}
+	return tokens, nil
+}
+
+// RequireAPIToken authenticates JSON API requests with one of the bearer
+// tokens and stores the token's name as the operator. The API is disabled
+// when no tokens are configured.
+func RequireAPIToken(tokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(tokens) == 0 {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "admin API is disabled"})
+			return
+		}
+
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		for name, expected := range tokens {
+			if tokensEqual(token, expected) {
+				c.Set(operatorKey, name)
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid bearer token"})
+	}
+}
+
+// Operator returns the name of the API token used for the request
+func Operator(c *gin.Context) string {
+	return c.GetString(operatorKey)
+}
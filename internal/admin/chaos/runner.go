@@ -0,0 +1,245 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Processor is the subset of the processor admin client used by scenarios
+type Processor interface {
+	SetDelay(ctx context.Context, delay int) error
+	SetFailure(ctx context.Context, failure bool) error
+}
+
+// ActionState is the progress of one scheduled action
+type ActionState string
+
+const (
+	ActionPending ActionState = "pending"
+	ActionDone    ActionState = "done"
+	ActionFailed  ActionState = "failed"
+	ActionSkipped ActionState = "skipped"
+)
+
+// ActionStatus reports what happened to an action
+type ActionStatus struct {
+	Action
+	State ActionState
+	Error string
+}
+
+// RunStatus is a snapshot of a scenario run
+type RunStatus struct {
+	Scenario   string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Duration   time.Duration
+	Running    bool
+	Aborted    bool
+	Actions    []ActionStatus
+}
+
+// Elapsed returns how long the run has been going, or went
+func (s RunStatus) Elapsed() time.Duration {
+	if s.Running {
+		return time.Since(s.StartedAt).Truncate(time.Second)
+	}
+	return s.FinishedAt.Sub(s.StartedAt).Truncate(time.Second)
+}
+
+// Progress returns the completion percentage of the run
+func (s RunStatus) Progress() int {
+	if !s.Running || s.Duration <= 0 {
+		return 100
+	}
+	pct := int(time.Since(s.StartedAt) * 100 / s.Duration)
+	if pct > 100 {
+		return 100
+	}
+	return pct
+}
+
+// ErrAlreadyRunning is returned when a scenario is started while another runs
+var ErrAlreadyRunning = errors.New("a scenario is already running")
+
+// Runner runs one scenario at a time against the processors
+type Runner struct {
+	processors func() map[string]Processor
+
+	mu     sync.Mutex
+	status *RunStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRunner creates a scenario runner. processors is called for every action
+// so that processors added or removed while a scenario runs are seen.
+func NewRunner(processors func() map[string]Processor) *Runner {
+	return &Runner{processors: processors}
+}
+
+// Start runs the scenario in the background
+func (r *Runner) Start(scenario Scenario) error {
+	if err := scenario.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status != nil && r.status.Running {
+		return ErrAlreadyRunning
+	}
+
+	actions := scenario.Actions()
+	status := &RunStatus{
+		Scenario:  scenario.Name,
+		StartedAt: time.Now(),
+		Duration:  scenario.Duration(),
+		Running:   true,
+		Actions:   make([]ActionStatus, len(actions)),
+	}
+	for i, a := range actions {
+		status.Actions[i] = ActionStatus{Action: a, State: ActionPending}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.status = status
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.run(ctx, status, r.done)
+	return nil
+}
+
+func (r *Runner) run(ctx context.Context, status *RunStatus, done chan struct{}) {
+	defer close(done)
+
+	for i := range status.Actions {
+		r.mu.Lock()
+		action := status.Actions[i].Action
+		r.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(status.StartedAt.Add(action.At)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.finish(status, true)
+			return
+		case <-timer.C:
+		}
+
+		err := r.apply(ctx, action)
+
+		r.mu.Lock()
+		if err != nil {
+			status.Actions[i].State = ActionFailed
+			status.Actions[i].Error = err.Error()
+		} else {
+			status.Actions[i].State = ActionDone
+		}
+		r.mu.Unlock()
+
+		if err != nil {
+			fmt.Printf("Chaos scenario %q: t+%s %s failed: %v\n", status.Scenario, action.At, action.Description(), err)
+		} else {
+			fmt.Printf("Chaos scenario %q: t+%s %s\n", status.Scenario, action.At, action.Description())
+		}
+	}
+
+	r.finish(status, false)
+}
+
+// apply performs an action on the processors it targets
+func (r *Runner) apply(ctx context.Context, action Action) error {
+	processors := r.processors()
+
+	names := []string{action.Processor}
+	if action.Processor == "all" {
+		names = names[:0]
+		for name := range processors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var errs []error
+	for _, name := range names {
+		processor, ok := processors[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown processor", name))
+			continue
+		}
+
+		var err error
+		if action.Delay != nil {
+			err = processor.SetDelay(ctx, *action.Delay)
+		} else if action.Failure != nil {
+			err = processor.SetFailure(ctx, *action.Failure)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Runner) finish(status *RunStatus, aborted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status.Running = false
+	status.Aborted = aborted
+	status.FinishedAt = time.Now()
+	if aborted {
+		for i := range status.Actions {
+			if status.Actions[i].State == ActionPending {
+				status.Actions[i].State = ActionSkipped
+			}
+		}
+	}
+}
+
+// Abort stops the running scenario and, when reset is true, turns failure
+// off and delay to zero on every processor
+func (r *Runner) Abort(ctx context.Context, reset bool) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	running := r.status != nil && r.status.Running
+	r.mu.Unlock()
+
+	if !running {
+		return errors.New("no scenario is running")
+	}
+
+	cancel()
+	<-done
+
+	if !reset {
+		return nil
+	}
+
+	zero, off := 0, false
+	return errors.Join(
+		r.apply(ctx, Action{Processor: "all", Failure: &off}),
+		r.apply(ctx, Action{Processor: "all", Delay: &zero}),
+	)
+}
+
+// Status returns a snapshot of the current or last run, or nil if none ran
+func (r *Runner) Status() *RunStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status == nil {
+		return nil
+	}
+
+	snapshot := *r.status
+	snapshot.Actions = append([]ActionStatus(nil), r.status.Actions...)
+	return &snapshot
+}
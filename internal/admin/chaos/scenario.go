@@ -0,0 +1,134 @@
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Step changes a processor's behaviour at an offset from the scenario start.
+// When For is set the change is reverted (failure off, delay 0) after that long.
+type Step struct {
+	At        time.Duration `yaml:"at"`
+	Processor string        `yaml:"processor"` // a processor name or "all"
+	Delay     *int          `yaml:"delay"`     // milliseconds
+	Failure   *bool         `yaml:"failure"`
+	For       time.Duration `yaml:"for"`
+}
+
+// Scenario is a named list of timed steps
+type Scenario struct {
+	Name  string `yaml:"name"`
+	Steps []Step `yaml:"steps"`
+}
+
+// Action is a single processor change scheduled by a scenario
+type Action struct {
+	At        time.Duration
+	Processor string
+	Delay     *int
+	Failure   *bool
+}
+
+// Description returns a human readable description of the action
+func (a Action) Description() string {
+	switch {
+	case a.Delay != nil:
+		return fmt.Sprintf("%s delay %dms", a.Processor, *a.Delay)
+	case a.Failure != nil && *a.Failure:
+		return fmt.Sprintf("%s failure on", a.Processor)
+	case a.Failure != nil:
+		return fmt.Sprintf("%s failure off", a.Processor)
+	default:
+		return a.Processor
+	}
+}
+
+// ParseScenario parses a scenario written in YAML or JSON
+func ParseScenario(data []byte) (Scenario, error) {
+	var scenario Scenario
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return Scenario{}, fmt.Errorf("invalid scenario: %w", err)
+	}
+	if err := scenario.Validate(); err != nil {
+		return Scenario{}, err
+	}
+	return scenario, nil
+}
+
+// LoadScenarioFile reads and parses a scenario file
+func LoadScenarioFile(path string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// Validate validates the scenario steps
+func (s Scenario) Validate() error {
+	if len(s.Steps) == 0 {
+		return errors.New("scenario has no steps")
+	}
+
+	var errs []error
+	for i, step := range s.Steps {
+		if step.Processor == "" {
+			errs = append(errs, fmt.Errorf("step %d: processor is required", i+1))
+		}
+		if (step.Delay == nil) == (step.Failure == nil) {
+			errs = append(errs, fmt.Errorf("step %d: set exactly one of delay or failure", i+1))
+		}
+		if step.Delay != nil && *step.Delay < 0 {
+			errs = append(errs, fmt.Errorf("step %d: delay must not be negative", i+1))
+		}
+		if step.At < 0 || step.For < 0 {
+			errs = append(errs, fmt.Errorf("step %d: at and for must not be negative", i+1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Actions expands the steps into processor changes ordered by time, adding
+// the reverting change of every step with a duration
+func (s Scenario) Actions() []Action {
+	var actions []Action
+	for _, step := range s.Steps {
+		actions = append(actions, Action{
+			At:        step.At,
+			Processor: step.Processor,
+			Delay:     step.Delay,
+			Failure:   step.Failure,
+		})
+
+		if step.For > 0 {
+			revert := Action{At: step.At + step.For, Processor: step.Processor}
+			if step.Delay != nil {
+				zero := 0
+				revert.Delay = &zero
+			} else {
+				off := false
+				revert.Failure = &off
+			}
+			actions = append(actions, revert)
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].At < actions[j].At })
+	return actions
+}
+
+// Duration returns the offset of the last action
+func (s Scenario) Duration() time.Duration {
+	var last time.Duration
+	for _, a := range s.Actions() {
+		if a.At > last {
+			last = a.At
+		}
+	}
+	return last
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AdminProcessorStatus describes a processor in the admin JSON API
+type AdminProcessorStatus struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// AdminProcessorRequest adds a processor through the admin JSON API. Token
+// defaults to the admin app's processor token.
+type AdminProcessorRequest struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Token string `json:"token,omitempty"`
+}
+
+// AdminProcessorsResponse is the admin JSON API processor list
+type AdminProcessorsResponse struct {
+	Processors []AdminProcessorStatus `json:"processors"`
+}
+
+// AdminResult is the outcome of an admin action on one processor
+type AdminResult struct {
+	Processor string           `json:"processor"`
+	OK        bool             `json:"ok"`
+	Message   string           `json:"message,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Summary   *PaymentsSummary `json:"summary,omitempty"`
+}
+
+// AdminResponse is the admin JSON API response for actions and summaries
+type AdminResponse struct {
+	Results []AdminResult `json:"results"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Failed returns the results that did not succeed
+func (r AdminResponse) Failed() []AdminResult {
+	var failed []AdminResult
+	for _, result := range r.Results {
+		if !result.OK {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// AdminError is returned when the admin API rejects a request
+type AdminError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *AdminError) Error() string {
+	return fmt.Sprintf("admin API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// AdminClient talks to the admin app's versioned JSON API
+type AdminClient struct {
+	BaseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewAdminClient creates a client for the admin JSON API at baseURL
+func NewAdminClient(baseURL, token string) *AdminClient {
+	return &AdminClient{
+		BaseURL: baseURL,
+		token:   token,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Processors lists the processors and whether they respond
+func (c *AdminClient) Processors(ctx context.Context) (*AdminProcessorsResponse, error) {
+	var response AdminProcessorsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/processors", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// AddProcessor registers a processor with the admin app
+func (c *AdminClient) AddProcessor(ctx context.Context, name, processorURL, token string) error {
+	var status AdminProcessorStatus
+	return c.do(ctx, http.MethodPost, "/api/v1/processors", nil, AdminProcessorRequest{Name: name, URL: processorURL, Token: token}, &status)
+}
+
+// RemoveProcessor unregisters a processor from the admin app
+func (c *AdminClient) RemoveProcessor(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/processors/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// Summary returns the payments summary of a processor, or of every processor
+// when name is "all"
+func (c *AdminClient) Summary(ctx context.Context, name string, from, to *time.Time) (*AdminResponse, error) {
+	query := url.Values{}
+	if from != nil {
+		query.Set("from", from.Format(time.RFC3339))
+	}
+	if to != nil {
+		query.Set("to", to.Format(time.RFC3339))
+	}
+	return c.action(ctx, http.MethodGet, name, "summary", query, nil)
+}
+
+// SetToken changes the admin token of a processor, or of all of them
+func (c *AdminClient) SetToken(ctx context.Context, name, token string) (*AdminResponse, error) {
+	return c.action(ctx, http.MethodPut, name, "token", nil, TokenConfig{Token: token})
+}
+
+// SetDelay changes the payment delay of a processor, or of all of them
+func (c *AdminClient) SetDelay(ctx context.Context, name string, delay int) (*AdminResponse, error) {
+	return c.action(ctx, http.MethodPut, name, "delay", nil, DelayConfig{Delay: delay})
+}
+
+// SetFailure toggles failure mode on a processor, or on all of them
+func (c *AdminClient) SetFailure(ctx context.Context, name string, failure bool) (*AdminResponse, error) {
+	return c.action(ctx, http.MethodPut, name, "failure", nil, FailureConfig{Failure: failure})
+}
+
+// Purge deletes the payments of a processor, or of all of them
+func (c *AdminClient) Purge(ctx context.Context, name string) (*AdminResponse, error) {
+	return c.action(ctx, http.MethodPost, name, "purge", nil, nil)
+}
+
+// Processor returns a view of one processor that changes it through the admin API
+func (c *AdminClient) Processor(name string) *AdminProcessor {
+	return &AdminProcessor{client: c, name: name}
+}
+
+// action performs a per-processor action and turns failed results into an error
+func (c *AdminClient) action(ctx context.Context, method, name, action string, query url.Values, body interface{}) (*AdminResponse, error) {
+	path := fmt.Sprintf("/api/v1/processors/%s/%s", url.PathEscape(name), action)
+
+	var response AdminResponse
+	err := c.do(ctx, method, path, query, body, &response)
+	if err != nil && len(response.Results) == 0 {
+		return nil, err
+	}
+	return &response, err
+}
+
+// do sends a request and decodes the JSON response. Responses with
+// per-processor results are decoded even when some of them failed.
+func (c *AdminClient) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	endpoint := c.BaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	success := resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusNoContent
+	if out != nil && len(data) > 0 && (success || resp.StatusCode == http.StatusBadGateway) {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	if !success {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		message := string(data)
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			message = apiErr.Error
+		}
+		return &AdminError{StatusCode: resp.StatusCode, Message: message}
+	}
+	return nil
+}
+
+// AdminProcessor changes a single processor through the admin API
+type AdminProcessor struct {
+	client *AdminClient
+	name   string
+}
+
+// SetDelay sets the processor's payment delay
+func (p *AdminProcessor) SetDelay(ctx context.Context, delay int) error {
+	_, err := p.client.SetDelay(ctx, p.name, delay)
+	return err
+}
+
+// SetFailure toggles the processor's failure mode
+func (p *AdminProcessor) SetFailure(ctx context.Context, failure bool) error {
+	_, err := p.client.SetFailure(ctx, p.name, failure)
+	return err
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// APIClient reads from our payments API
+type APIClient struct {
+	BaseURL      string
+	ControlToken string // bearer token for the /internal endpoints
+	APIKey       string // sent as X-API-Key when the API requires authentication
+	httpClient   *http.Client
+}
+
+// NewAPIClient creates a new payments API client. controlToken may be empty
+// when the queue endpoints are not used and apiKey when the API does not
+// require authentication.
+func NewAPIClient(baseURL, controlToken, apiKey string) *APIClient {
+	return &APIClient{
+		BaseURL:      baseURL,
+		ControlToken: controlToken,
+		APIKey:       apiKey,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// APIChannelStats represents the statistics of one channel in our summary
+type APIChannelStats struct {
+	TotalRequests int     `json:"totalRequests"`
+	TotalAmount   float64 `json:"totalAmount"`
+}
+
+// APIPaymentsSummary represents our /payments-summary response, by processor
+// name. It always has default and fallback, and any other configured processor.
+type APIPaymentsSummary map[string]APIChannelStats
+
+// GetPaymentsSummary retrieves the payments summary from our API
+func (c *APIClient) GetPaymentsSummary(ctx context.Context, from, to *time.Time) (APIPaymentsSummary, error) {
+	url := fmt.Sprintf("%s/payments-summary", c.BaseURL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add query parameters if provided
+	q := req.URL.Query()
+	if from != nil {
+		q.Add("from", from.Format(time.RFC3339))
+	}
+	if to != nil {
+		q.Add("to", to.Format(time.RFC3339))
+	}
+	req.URL.RawQuery = q.Encode()
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var summary APIPaymentsSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return summary, nil
+}
+
+// APIQueueStats describes a queue of our API
+type APIQueueStats struct {
+	Queue            string     `json:"queue"`
+	Depth            int        `json:"depth"`
+	OldestEnqueuedAt *time.Time `json:"oldestEnqueuedAt"`
+	OldestAgeSeconds float64    `json:"oldestAgeSeconds"`
+}
+
+// APIQueuedPayment is a payment waiting in a queue of our API
+type APIQueuedPayment struct {
+	CorrelationID string    `json:"correlationId"`
+	Amount        float64   `json:"amount"`
+	EnqueuedAt    time.Time `json:"enqueuedAt"`
+}
+
+// QueueStats returns the stats of the main and dead-letter queues
+func (c *APIClient) QueueStats(ctx context.Context) ([]APIQueueStats, error) {
+	var response struct {
+		Queues []APIQueueStats `json:"queues"`
+	}
+	err := c.control(ctx, http.MethodGet, "/internal/queue", nil, &response)
+	return response.Queues, err
+}
+
+// QueueItems returns a page of the payments waiting in a queue
+func (c *APIClient) QueueItems(ctx context.Context, queue string, offset, limit int) ([]APIQueuedPayment, error) {
+	query := url.Values{"offset": {strconv.Itoa(offset)}, "limit": {strconv.Itoa(limit)}}
+	var response struct {
+		Items []APIQueuedPayment `json:"items"`
+	}
+	err := c.control(ctx, http.MethodGet, "/internal/queue/"+url.PathEscape(queue)+"/items?"+query.Encode(), nil, &response)
+	return response.Items, err
+}
+
+// MoveQueueItems moves payments between queues, all of them when no IDs are given
+func (c *APIClient) MoveQueueItems(ctx context.Context, from, to string, correlationIDs []string) (int, error) {
+	body := map[string]interface{}{"from": from, "to": to, "correlationIds": correlationIDs}
+	var response struct {
+		Moved int `json:"moved"`
+	}
+	err := c.control(ctx, http.MethodPost, "/internal/queue/move", body, &response)
+	return response.Moved, err
+}
+
+// PurgeQueue deletes every payment of a queue
+func (c *APIClient) PurgeQueue(ctx context.Context, queue string) (int, error) {
+	var response struct {
+		Purged int `json:"purged"`
+	}
+	err := c.control(ctx, http.MethodDelete, "/internal/queue/"+url.PathEscape(queue), nil, &response)
+	return response.Purged, err
+}
+
+// control calls an authenticated /internal endpoint and decodes its JSON response
+func (c *APIClient) control(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.ControlToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -5,18 +5,27 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/auth"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/chaos"
 	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+	"github.com/lmtani/rinha-de-backend-2025/internal/processorspec"
 )
 
 // AdminHandler handles admin web interface requests
 type AdminHandler struct {
-	defaultClient  *client.ProcessorClient
-	fallbackClient *client.ProcessorClient
-	templates      *template.Template
+	processors   *processorRegistry
+	token        string
+	apiClient    *client.APIClient
+	chaosRunner  *chaos.Runner
+	scenariosDir string
+	auth         *auth.Authenticator
+	apiTokens    map[string]string
+	templates    *template.Template
 }
 
 // ProcessorInfo holds information about a processor
@@ -31,19 +40,52 @@ type ProcessorInfo struct {
 // DashboardData holds data for the dashboard template
 type DashboardData struct {
 	Processors []ProcessorInfo
-	Token      string
+	APIURL     string
+	Scenarios  []string
+	User       string
+	CSRFToken  string
+}
+
+// Config configures the admin handler
+type Config struct {
+	Processors      []processorspec.Processor
+	APIURL          string
+	APIControlToken string // bearer token for the API's /internal endpoints, optional
+	APIKey          string // X-API-Key for the API's payment endpoints, optional
+	Token           string // processor admin token, also the default for processors added at runtime
+	ScenariosDir    string
+	Auth            *auth.Authenticator
+	APITokens       map[string]string // bearer tokens for the JSON API, by name
+	TemplatesDir    string            // defaults to web/templates
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(defaultURL, fallbackURL, token string) *AdminHandler {
+func NewAdminHandler(cfg Config) (*AdminHandler, error) {
 	// Load templates
-	templates := template.Must(template.ParseGlob("web/templates/*.html"))
-
-	return &AdminHandler{
-		defaultClient:  client.NewProcessorClient(defaultURL, token),
-		fallbackClient: client.NewProcessorClient(fallbackURL, token),
-		templates:      templates,
+	templatesDir := cfg.TemplatesDir
+	if templatesDir == "" {
+		templatesDir = "web/templates"
+	}
+	templates := template.Must(template.ParseGlob(filepath.Join(templatesDir, "*.html")))
+
+	processors := &processorRegistry{}
+	h := &AdminHandler{
+		processors:   processors,
+		token:        cfg.Token,
+		apiClient:    client.NewAPIClient(cfg.APIURL, cfg.APIControlToken, cfg.APIKey),
+		chaosRunner:  chaos.NewRunner(processors.chaosProcessors),
+		scenariosDir: cfg.ScenariosDir,
+		auth:         cfg.Auth,
+		apiTokens:    cfg.APITokens,
+		templates:    templates,
+	}
+
+	for _, p := range cfg.Processors {
+		if err := h.addProcessorTarget(p.Name, p.URL, ""); err != nil {
+			return nil, err
+		}
 	}
+	return h, nil
 }
 
 // RegisterRoutes registers all admin routes
@@ -51,36 +93,58 @@ func (h *AdminHandler) RegisterRoutes(r *gin.Engine) {
 	// Serve static files
 	r.Static("/static", "web/static")
 
+	// JSON API for scripts, authenticated with bearer tokens
+	h.registerAPIRoutes(r)
+
+	// Login
+	r.GET("/login", h.showLogin)
+	r.POST("/login", h.login)
+
+	// Everything else needs a session, and POSTs a CSRF token
+	a := r.Group("/", h.auth.RequireSession())
+	a.POST("/logout", h.logout)
+
 	// Main dashboard
-	r.GET("/", h.dashboard)
+	a.GET("/", h.dashboard)
+
+	// API versus processors comparison
+	a.GET("/comparison", h.getComparison)
+
+	// Chaos scenarios
+	a.GET("/chaos/status", h.getScenarioStatus)
+	a.POST("/chaos/run", h.runScenario)
+	a.POST("/chaos/abort", h.abortScenario)
+
+	// Payment queue
+	a.GET("/queue", h.getQueue)
+	a.POST("/queue/move", h.moveQueueItems)
+	a.POST("/queue/purge", h.purgeQueue)
 
 	// Processor management
-	r.GET("/processor/:name/summary", h.getProcessorSummary)
-	r.POST("/processor/:name/token", h.setProcessorToken)
-	r.POST("/processor/:name/delay", h.setProcessorDelay)
-	r.POST("/processor/:name/failure", h.setProcessorFailure)
-	r.POST("/processor/:name/purge", h.purgeProcessorPayments)
+	a.POST("/processors", h.addProcessor)
+	a.POST("/processor/:name/remove", h.removeProcessor)
+	a.GET("/processor/:name/summary", h.getProcessorSummary)
+	a.POST("/processor/:name/token", h.setProcessorToken)
+	a.POST("/processor/:name/delay", h.setProcessorDelay)
+	a.POST("/processor/:name/failure", h.setProcessorFailure)
+	a.POST("/processor/:name/purge", h.purgeProcessorPayments)
 
 	// Global actions
-	r.POST("/global/token", h.setGlobalToken)
-	r.POST("/global/delay", h.setGlobalDelay)
-	r.POST("/global/failure", h.setGlobalFailure)
-	r.POST("/global/purge", h.purgeAllPayments)
+	a.POST("/global/token", h.setGlobalToken)
+	a.POST("/global/delay", h.setGlobalDelay)
+	a.POST("/global/failure", h.setGlobalFailure)
+	a.POST("/global/purge", h.purgeAllPayments)
 }
 
 // dashboard renders the main dashboard
 func (h *AdminHandler) dashboard(c *gin.Context) {
-	processors := []ProcessorInfo{
-		{
-			Name:   "default",
-			URL:    h.defaultClient.BaseURL,
-			Client: h.defaultClient,
-		},
-		{
-			Name:   "fallback",
-			URL:    h.fallbackClient.BaseURL,
-			Client: h.fallbackClient,
-		},
+	var processors []ProcessorInfo
+	for _, target := range h.processors.list() {
+		processors = append(processors, ProcessorInfo{
+			Name:   target.name,
+			URL:    target.client.BaseURL,
+			Client: target.client,
+		})
 	}
 
 	// Check processor status
@@ -95,9 +159,13 @@ func (h *AdminHandler) dashboard(c *gin.Context) {
 		processors[i].LastChecked = time.Now()
 	}
 
+	session := auth.CurrentSession(c)
 	data := DashboardData{
 		Processors: processors,
-		Token:      h.defaultClient.Token,
+		APIURL:     h.apiClient.BaseURL,
+		Scenarios:  h.listScenarioFiles(),
+		User:       session.User,
+		CSRFToken:  session.CSRFToken,
 	}
 
 	if err := h.templates.ExecuteTemplate(c.Writer, "dashboard.html", data); err != nil {
@@ -282,18 +350,12 @@ func (h *AdminHandler) setGlobalToken(c *gin.Context) {
 	ctx := context.Background()
 	var errors []string
 
-	// Update default processor
-	if err := h.defaultClient.SetToken(ctx, token); err != nil {
-		errors = append(errors, fmt.Sprintf("Default: %v", err))
-	} else {
-		h.defaultClient.UpdateToken(token)
-	}
-
-	// Update fallback processor
-	if err := h.fallbackClient.SetToken(ctx, token); err != nil {
-		errors = append(errors, fmt.Sprintf("Fallback: %v", err))
-	} else {
-		h.fallbackClient.UpdateToken(token)
+	for _, target := range h.processors.list() {
+		if err := target.client.SetToken(ctx, token); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", target.name, err))
+		} else {
+			target.client.UpdateToken(token)
+		}
 	}
 
 	if len(errors) > 0 {
@@ -316,12 +378,10 @@ func (h *AdminHandler) setGlobalDelay(c *gin.Context) {
 	ctx := context.Background()
 	var errors []string
 
-	if err := h.defaultClient.SetDelay(ctx, delay); err != nil {
-		errors = append(errors, fmt.Sprintf("Default: %v", err))
-	}
-
-	if err := h.fallbackClient.SetDelay(ctx, delay); err != nil {
-		errors = append(errors, fmt.Sprintf("Fallback: %v", err))
+	for _, target := range h.processors.list() {
+		if err := target.client.SetDelay(ctx, delay); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", target.name, err))
+		}
 	}
 
 	if len(errors) > 0 {
@@ -359,12 +419,10 @@ func (h *AdminHandler) setGlobalFailure(c *gin.Context) {
 	ctx := context.Background()
 	var errors []string
 
-	if err := h.defaultClient.SetFailure(ctx, failure); err != nil {
-		errors = append(errors, fmt.Sprintf("Default: %v", err))
-	}
-
-	if err := h.fallbackClient.SetFailure(ctx, failure); err != nil {
-		errors = append(errors, fmt.Sprintf("Fallback: %v", err))
+	for _, target := range h.processors.list() {
+		if err := target.client.SetFailure(ctx, failure); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", target.name, err))
+		}
 	}
 
 	if len(errors) > 0 {
@@ -383,31 +441,19 @@ func (h *AdminHandler) purgeAllPayments(c *gin.Context) {
 	ctx := context.Background()
 	var results []string
 
-	// Purge default processor
-	if response, err := h.defaultClient.PurgePayments(ctx); err != nil {
-		results = append(results, fmt.Sprintf("Default: Error - %v", err))
-	} else {
-		results = append(results, fmt.Sprintf("Default: %s", response.Message))
-	}
-
-	// Purge fallback processor
-	if response, err := h.fallbackClient.PurgePayments(ctx); err != nil {
-		results = append(results, fmt.Sprintf("Fallback: Error - %v", err))
-	} else {
-		results = append(results, fmt.Sprintf("Fallback: %s", response.Message))
+	for _, target := range h.processors.list() {
+		if response, err := target.client.PurgePayments(ctx); err != nil {
+			results = append(results, fmt.Sprintf("%s: Error - %v", target.name, err))
+		} else {
+			results = append(results, fmt.Sprintf("%s: %s", target.name, response.Message))
+		}
 	}
 
 	c.String(http.StatusOK, "Results: %v", results)
 }
 
-// getClient returns the appropriate client based on processor name
+// getClient returns the client of the named processor, or nil
 func (h *AdminHandler) getClient(name string) *client.ProcessorClient {
-	switch name {
-	case "default":
-		return h.defaultClient
-	case "fallback":
-		return h.fallbackClient
-	default:
-		return nil
-	}
+	c, _ := h.processors.get(name)
+	return c
 }
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/auth"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+)
+
+// registerAPIRoutes registers the versioned JSON API. Every action accepts a
+// processor name or "all".
+func (h *AdminHandler) registerAPIRoutes(r *gin.Engine) {
+	v1 := r.Group("/api/v1", auth.RequireAPIToken(h.apiTokens))
+
+	v1.GET("/processors", h.apiListProcessors)
+	v1.POST("/processors", h.apiAddProcessor)
+	v1.DELETE("/processors/:name", h.apiRemoveProcessor)
+	v1.GET("/processors/:name/summary", h.apiGetSummary)
+	v1.PUT("/processors/:name/token", h.apiSetToken)
+	v1.PUT("/processors/:name/delay", h.apiSetDelay)
+	v1.PUT("/processors/:name/failure", h.apiSetFailure)
+	v1.POST("/processors/:name/purge", h.apiPurge)
+}
+
+// apiTargets resolves the :name parameter to the processors it addresses
+func (h *AdminHandler) apiTargets(c *gin.Context) ([]processorTarget, bool) {
+	name := c.Param("name")
+	targets, ok := h.processors.resolve(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("unknown processor %q", name)})
+	}
+	return targets, ok
+}
+
+// apiApply runs fn on every addressed processor and responds with one result
+// per processor, using 502 if any of them failed
+func (h *AdminHandler) apiApply(c *gin.Context, action string, fn func(context.Context, processorTarget) client.AdminResult) {
+	targets, ok := h.apiTargets(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	response := client.AdminResponse{}
+	for _, target := range targets {
+		result := fn(ctx, target)
+		result.Processor = target.name
+		response.Results = append(response.Results, result)
+	}
+
+	if action != "summary" {
+		fmt.Printf("Admin API %s on %s by %s\n", action, c.Param("name"), auth.Operator(c))
+	}
+
+	if failed := len(response.Failed()); failed > 0 {
+		response.Error = fmt.Sprintf("%d of %d processors failed", failed, len(response.Results))
+		c.JSON(http.StatusBadGateway, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// failed builds the result of a failed processor call
+func failed(err error) client.AdminResult {
+	return client.AdminResult{Error: err.Error()}
+}
+
+func (h *AdminHandler) apiListProcessors(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	response := client.AdminProcessorsResponse{}
+	for _, target := range h.processors.list() {
+		status := client.AdminProcessorStatus{Name: target.name, URL: target.client.BaseURL, OK: true}
+		if _, err := target.client.GetPaymentsSummary(ctx, nil, nil); err != nil {
+			status.OK = false
+			status.Error = err.Error()
+		}
+		response.Processors = append(response.Processors, status)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) apiAddProcessor(c *gin.Context) {
+	var body client.AdminProcessorRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		return
+	}
+
+	if err := h.addProcessorTarget(body.Name, body.URL, body.Token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fmt.Printf("Admin API added processor %s (%s) by %s\n", body.Name, body.URL, auth.Operator(c))
+	c.JSON(http.StatusCreated, client.AdminProcessorStatus{Name: body.Name, URL: body.URL, OK: true})
+}
+
+func (h *AdminHandler) apiRemoveProcessor(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.processors.remove(name); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	fmt.Printf("Admin API removed processor %s by %s\n", name, auth.Operator(c))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) apiGetSummary(c *gin.Context) {
+	from, ok := parseTimeParam(c, "from")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from' timestamp"})
+		return
+	}
+	to, ok := parseTimeParam(c, "to")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to' timestamp"})
+		return
+	}
+
+	h.apiApply(c, "summary", func(ctx context.Context, target processorTarget) client.AdminResult {
+		summary, err := target.client.GetPaymentsSummary(ctx, from, to)
+		if err != nil {
+			return failed(err)
+		}
+		return client.AdminResult{OK: true, Summary: summary}
+	})
+}
+
+func (h *AdminHandler) apiSetToken(c *gin.Context) {
+	var body client.TokenConfig
+	if err := c.ShouldBindJSON(&body); err != nil || body.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected {\"token\": \"...\"}"})
+		return
+	}
+
+	h.apiApply(c, "token", func(ctx context.Context, target processorTarget) client.AdminResult {
+		if err := target.client.SetToken(ctx, body.Token); err != nil {
+			return failed(err)
+		}
+		target.client.UpdateToken(body.Token)
+		return client.AdminResult{OK: true, Message: "token updated"}
+	})
+}
+
+func (h *AdminHandler) apiSetDelay(c *gin.Context) {
+	var body struct {
+		Delay *int `json:"delay"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Delay == nil || *body.Delay < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected {\"delay\": <milliseconds>}"})
+		return
+	}
+
+	h.apiApply(c, "delay", func(ctx context.Context, target processorTarget) client.AdminResult {
+		if err := target.client.SetDelay(ctx, *body.Delay); err != nil {
+			return failed(err)
+		}
+		return client.AdminResult{OK: true, Message: fmt.Sprintf("delay set to %d ms", *body.Delay)}
+	})
+}
+
+func (h *AdminHandler) apiSetFailure(c *gin.Context) {
+	var body struct {
+		Failure *bool `json:"failure"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Failure == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected {\"failure\": true|false}"})
+		return
+	}
+
+	h.apiApply(c, "failure", func(ctx context.Context, target processorTarget) client.AdminResult {
+		if err := target.client.SetFailure(ctx, *body.Failure); err != nil {
+			return failed(err)
+		}
+		status := "disabled"
+		if *body.Failure {
+			status = "enabled"
+		}
+		return client.AdminResult{OK: true, Message: "failure mode " + status}
+	})
+}
+
+func (h *AdminHandler) apiPurge(c *gin.Context) {
+	h.apiApply(c, "purge", func(ctx context.Context, target processorTarget) client.AdminResult {
+		response, err := target.client.PurgePayments(ctx)
+		if err != nil {
+			return failed(err)
+		}
+		return client.AdminResult{OK: true, Message: response.Message}
+	})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/chaos"
+)
+
+// listScenarioFiles returns the scenario file names found in the scenarios directory
+func (h *AdminHandler) listScenarioFiles() []string {
+	entries, err := os.ReadDir(h.scenariosDir)
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// runScenario starts a scenario from a file in the scenarios directory or from the posted text
+func (h *AdminHandler) runScenario(c *gin.Context) {
+	var scenario chaos.Scenario
+	var err error
+
+	if file := c.PostForm("file"); file != "" {
+		// Only accept names listed from the scenarios directory
+		found := false
+		for _, name := range h.listScenarioFiles() {
+			if name == file {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.String(http.StatusBadRequest, "Unknown scenario file")
+			return
+		}
+		scenario, err = chaos.LoadScenarioFile(filepath.Join(h.scenariosDir, file))
+		if scenario.Name == "" {
+			scenario.Name = file
+		}
+	} else if text := c.PostForm("scenario"); strings.TrimSpace(text) != "" {
+		scenario, err = chaos.ParseScenario([]byte(text))
+		if scenario.Name == "" {
+			scenario.Name = "ad-hoc"
+		}
+	} else {
+		c.String(http.StatusBadRequest, "Choose a scenario file or paste a scenario")
+		return
+	}
+
+	if err != nil {
+		c.String(http.StatusBadRequest, "Error: %v", err)
+		return
+	}
+
+	if err := h.chaosRunner.Start(scenario); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, chaos.ErrAlreadyRunning) {
+			status = http.StatusConflict
+		}
+		c.String(status, "Error: %v", err)
+		return
+	}
+
+	h.renderChaosStatus(c)
+}
+
+// abortScenario stops the running scenario, optionally resetting the processors
+func (h *AdminHandler) abortScenario(c *gin.Context) {
+	reset := c.PostForm("reset") == "true"
+
+	if err := h.chaosRunner.Abort(c.Request.Context(), reset); err != nil {
+		c.String(http.StatusBadRequest, "Error: %v", err)
+		return
+	}
+
+	h.renderChaosStatus(c)
+}
+
+// getScenarioStatus renders the progress of the current or last scenario
+func (h *AdminHandler) getScenarioStatus(c *gin.Context) {
+	h.renderChaosStatus(c)
+}
+
+func (h *AdminHandler) renderChaosStatus(c *gin.Context) {
+	if err := h.templates.ExecuteTemplate(c.Writer, "chaos_status.html", map[string]interface{}{
+		"Status": h.chaosRunner.Status(),
+		"Now":    time.Now().Format("2006-01-02 15:04:05"),
+	}); err != nil {
+		c.String(http.StatusInternalServerError, "Template error: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+)
+
+// ChannelComparison compares our summary of a channel with its processor's summary
+type ChannelComparison struct {
+	Channel           string
+	APIRequests       int
+	APIAmount         float64
+	ProcessorRequests int
+	ProcessorAmount   float64
+	ProcessorError    string
+	APIFailed         bool
+}
+
+// comparable reports whether both sides were fetched successfully
+func (c ChannelComparison) comparable() bool {
+	return c.ProcessorError == "" && !c.APIFailed
+}
+
+// CountMismatch reports whether the request counts differ
+func (c ChannelComparison) CountMismatch() bool {
+	return c.comparable() && c.APIRequests != c.ProcessorRequests
+}
+
+// AmountMismatch reports whether the amounts differ by at least one cent
+func (c ChannelComparison) AmountMismatch() bool {
+	return c.comparable() && math.Abs(c.APIAmount-c.ProcessorAmount) >= 0.005
+}
+
+// RequestDiff returns our count minus the processor's count
+func (c ChannelComparison) RequestDiff() int {
+	return c.APIRequests - c.ProcessorRequests
+}
+
+// AmountDiff returns our amount minus the processor's amount
+func (c ChannelComparison) AmountDiff() float64 {
+	return c.APIAmount - c.ProcessorAmount
+}
+
+// ComparisonData holds data for the comparison template
+type ComparisonData struct {
+	APIURL   string
+	APIError string
+	Channels []ChannelComparison
+	From     *time.Time
+	To       *time.Time
+	Now      string
+}
+
+// getComparison renders our summary next to each processor's summary for the same range
+func (h *AdminHandler) getComparison(c *gin.Context) {
+	from, ok := parseTimeParam(c, "from")
+	if !ok {
+		c.String(http.StatusBadRequest, "Invalid 'from' timestamp")
+		return
+	}
+	to, ok := parseTimeParam(c, "to")
+	if !ok {
+		c.String(http.StatusBadRequest, "Invalid 'to' timestamp")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	processors := h.processors.list()
+
+	var (
+		wg         sync.WaitGroup
+		apiSummary client.APIPaymentsSummary
+		apiErr     error
+		summaries  = make([]*client.PaymentsSummary, len(processors))
+		errs       = make([]error, len(processors))
+	)
+
+	wg.Add(1 + len(processors))
+	go func() {
+		defer wg.Done()
+		apiSummary, apiErr = h.apiClient.GetPaymentsSummary(ctx, from, to)
+	}()
+	for i, p := range processors {
+		go func(i int, p *client.ProcessorClient) {
+			defer wg.Done()
+			summaries[i], errs[i] = p.GetPaymentsSummary(ctx, from, to)
+		}(i, p.client)
+	}
+	wg.Wait()
+
+	data := ComparisonData{
+		APIURL: h.apiClient.BaseURL,
+		From:   from,
+		To:     to,
+		Now:    time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if apiErr != nil {
+		data.APIError = apiErr.Error()
+	}
+
+	// Processors are matched to our channels by name; a processor our API
+	// does not route to has no payments on our side
+	for i, p := range processors {
+		stats := apiSummary[p.name]
+		comparison := ChannelComparison{
+			Channel:     p.name,
+			APIRequests: stats.TotalRequests,
+			APIAmount:   stats.TotalAmount,
+			APIFailed:   apiErr != nil,
+		}
+
+		if errs[i] != nil {
+			comparison.ProcessorError = errs[i].Error()
+		} else {
+			comparison.ProcessorRequests = summaries[i].TotalRequests
+			comparison.ProcessorAmount = summaries[i].TotalAmount
+		}
+
+		data.Channels = append(data.Channels, comparison)
+	}
+
+	if err := h.templates.ExecuteTemplate(c.Writer, "comparison.html", data); err != nil {
+		c.String(http.StatusInternalServerError, "Template error: %v", err)
+	}
+}
+
+// parseTimeParam parses an optional timestamp query parameter. It accepts
+// RFC3339 and the "2006-01-02T15:04" format sent by datetime-local inputs,
+// which is read as UTC.
+func parseTimeParam(c *gin.Context, name string) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			utc := t.UTC()
+			return &utc, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/auth"
+)
+
+// LoginData holds data for the login template
+type LoginData struct {
+	CSRFToken string
+	Username  string
+	Error     string
+}
+
+// showLogin renders the login form
+func (h *AdminHandler) showLogin(c *gin.Context) {
+	h.renderLogin(c, http.StatusOK, "", "")
+}
+
+// login checks the posted credentials and starts a session
+func (h *AdminHandler) login(c *gin.Context) {
+	if !h.auth.CheckLoginCSRF(c) {
+		h.renderLogin(c, http.StatusForbidden, "", "The login form expired, please try again")
+		return
+	}
+
+	username := c.PostForm("username")
+	if err := h.auth.Login(c, username, c.PostForm("password")); err != nil {
+		message := "Login failed"
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			message = "Invalid username or password"
+		}
+		h.renderLogin(c, http.StatusUnauthorized, username, message)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/")
+}
+
+// logout ends the session
+func (h *AdminHandler) logout(c *gin.Context) {
+	h.auth.Logout(c)
+	if c.GetHeader("HX-Request") != "" {
+		c.Header("HX-Redirect", "/login")
+		c.Status(http.StatusOK)
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/login")
+}
+
+func (h *AdminHandler) renderLogin(c *gin.Context, status int, username, message string) {
+	token, err := h.auth.LoginCSRFToken(c)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error: %v", err)
+		return
+	}
+
+	c.Status(status)
+	if err := h.templates.ExecuteTemplate(c.Writer, "login.html", LoginData{
+		CSRFToken: token,
+		Username:  username,
+		Error:     message,
+	}); err != nil {
+		c.String(http.StatusInternalServerError, "Template error: %v", err)
+	}
+}
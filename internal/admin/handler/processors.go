@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+)
+
+// addProcessorTarget registers a processor at runtime. An empty token uses the
+// admin app's processor token.
+func (h *AdminHandler) addProcessorTarget(name, processorURL, token string) error {
+	u, err := url.Parse(processorURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid processor URL %q, expected http(s)://host[:port]", processorURL)
+	}
+	if token == "" {
+		token = h.token
+	}
+	return h.processors.add(name, client.NewProcessorClient(strings.TrimRight(processorURL, "/"), token))
+}
+
+// addProcessor adds a processor from the dashboard form and reloads the page
+func (h *AdminHandler) addProcessor(c *gin.Context) {
+	name := strings.TrimSpace(c.PostForm("name"))
+	processorURL := strings.TrimSpace(c.PostForm("url"))
+
+	if err := h.addProcessorTarget(name, processorURL, c.PostForm("token")); err != nil {
+		c.String(http.StatusBadRequest, "Error: %v", err)
+		return
+	}
+
+	fmt.Printf("Added processor %s (%s)\n", name, processorURL)
+	c.Header("HX-Refresh", "true")
+	c.String(http.StatusOK, "Processor %s added", name)
+}
+
+// removeProcessor removes a processor from the dashboard and reloads the page
+func (h *AdminHandler) removeProcessor(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := h.processors.remove(name); err != nil {
+		c.String(http.StatusBadRequest, "Error: %v", err)
+		return
+	}
+
+	fmt.Printf("Removed processor %s\n", name)
+	c.Header("HX-Refresh", "true")
+	c.String(http.StatusOK, "Processor %s removed", name)
+}
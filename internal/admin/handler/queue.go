@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/auth"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+)
+
+// queuePageSize is how many payments the dashboard lists at once
+const queuePageSize = 20
+
+// QueueData holds data for the queue template
+type QueueData struct {
+	Enabled    bool
+	Error      string
+	Message    string
+	Stats      []client.APIQueueStats
+	Queue      string
+	Items      []client.APIQueuedPayment
+	Offset     int
+	PrevOffset int
+	NextOffset int // -1 when there is no next page
+	Now        string
+}
+
+// getQueue renders the queue stats and a page of the selected queue
+func (h *AdminHandler) getQueue(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	h.renderQueue(c, c.DefaultQuery("queue", "main"), max(offset, 0), "")
+}
+
+// moveQueueItems moves one payment, or every payment when no ID is posted,
+// and renders the queue selected in the dashboard
+func (h *AdminHandler) moveQueueItems(c *gin.Context) {
+	from, to := c.PostForm("from"), c.PostForm("to")
+
+	var ids []string
+	if id := c.PostForm("correlation_id"); id != "" {
+		ids = []string{id}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	moved, err := h.apiClient.MoveQueueItems(ctx, from, to, ids)
+	message := fmt.Sprintf("Moved %d payments from %s to %s", moved, from, to)
+	if err != nil {
+		message = "Move failed: " + err.Error()
+	}
+	fmt.Printf("Admin moved %d payments from %s to %s by %s\n", moved, from, to, auth.CurrentSession(c).User)
+	h.renderQueue(c, c.DefaultPostForm("queue", from), 0, message)
+}
+
+// purgeQueue deletes every payment of a queue
+func (h *AdminHandler) purgeQueue(c *gin.Context) {
+	queue := c.PostForm("queue")
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	purged, err := h.apiClient.PurgeQueue(ctx, queue)
+	message := fmt.Sprintf("Purged %d payments from %s", purged, queue)
+	if err != nil {
+		message = "Purge failed: " + err.Error()
+	}
+	fmt.Printf("Admin purged %d payments from %s by %s\n", purged, queue, auth.CurrentSession(c).User)
+	h.renderQueue(c, queue, 0, message)
+}
+
+// renderQueue renders the queue partial
+func (h *AdminHandler) renderQueue(c *gin.Context, queue string, offset int, message string) {
+	data := QueueData{
+		Enabled:    h.apiClient.ControlToken != "",
+		Message:    message,
+		Queue:      queue,
+		Offset:     offset,
+		PrevOffset: max(offset-queuePageSize, 0),
+		NextOffset: -1,
+		Now:        time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	if data.Enabled {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		if data.Stats, err = h.apiClient.QueueStats(ctx); err == nil {
+			data.Items, err = h.apiClient.QueueItems(ctx, queue, offset, queuePageSize+1)
+		}
+		if err != nil {
+			data.Error = err.Error()
+		}
+		if len(data.Items) > queuePageSize {
+			data.Items = data.Items[:queuePageSize]
+			data.NextOffset = offset + queuePageSize
+		}
+	}
+
+	if err := h.templates.ExecuteTemplate(c.Writer, "queue.html", data); err != nil {
+		c.String(http.StatusInternalServerError, "Template error: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/chaos"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+	"github.com/lmtani/rinha-de-backend-2025/internal/processorspec"
+)
+
+// processorTarget is a named processor client
+type processorTarget struct {
+	name   string
+	client *client.ProcessorClient
+}
+
+// processorRegistry is the ordered, runtime editable list of processors
+type processorRegistry struct {
+	mu      sync.RWMutex
+	targets []processorTarget
+}
+
+// add registers a processor, failing if the name is taken
+func (r *processorRegistry) add(name string, c *client.ProcessorClient) error {
+	if err := processorspec.ValidateName(name); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, target := range r.targets {
+		if target.name == name {
+			return fmt.Errorf("processor %q already exists", name)
+		}
+	}
+	r.targets = append(r.targets, processorTarget{name: name, client: c})
+	return nil
+}
+
+// remove unregisters a processor
+func (r *processorRegistry) remove(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, target := range r.targets {
+		if target.name == name {
+			r.targets = append(r.targets[:i:i], r.targets[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown processor %q", name)
+}
+
+// get returns the client of a processor
+func (r *processorRegistry) get(name string) (*client.ProcessorClient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, target := range r.targets {
+		if target.name == name {
+			return target.client, true
+		}
+	}
+	return nil, false
+}
+
+// list returns the processors in the order they were added
+func (r *processorRegistry) list() []processorTarget {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]processorTarget(nil), r.targets...)
+}
+
+// resolve returns the processors addressed by a name or "all"
+func (r *processorRegistry) resolve(name string) ([]processorTarget, bool) {
+	if name == "all" {
+		return r.list(), true
+	}
+	c, ok := r.get(name)
+	if !ok {
+		return nil, false
+	}
+	return []processorTarget{{name: name, client: c}}, true
+}
+
+// chaosProcessors adapts the current processors for the chaos runner
+func (r *processorRegistry) chaosProcessors() map[string]chaos.Processor {
+	processors := make(map[string]chaos.Processor)
+	for _, target := range r.list() {
+		processors[target.name] = target.client
+	}
+	return processors
+}
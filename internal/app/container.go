@@ -0,0 +1,439 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/grpc_server"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/http_client"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/http_server"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/in_memory_repository"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/postgres_repository"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/redis_repository"
+	"github.com/lmtani/rinha-de-backend-2025/internal/config"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain/service"
+	"github.com/lmtani/rinha-de-backend-2025/internal/port"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+)
+
+// Container holds all application dependencies
+type Container struct {
+	Config *config.Config
+
+	// Ports/Interfaces
+	Repository       port.PaymentRepository
+	Queue            port.PaymentQueue
+	Store            port.Store
+	Processors       []service.RoutedProcessor // in priority order
+	SettingsBus      port.SettingsBus
+	SettingsAuditLog port.SettingsAuditLog
+	RateLimiter      port.RateLimiter // nil when rate limiting is disabled
+	APIKeyStore      port.APIKeyStore // nil when authentication is disabled
+	ReplayCache      port.ReplayCache
+	WebhookLog       port.WebhookDeliveryLog // nil when webhooks are disabled
+	PaymentEventBus  port.PaymentEventBus    // nil when the event stream is disabled
+
+	// Domain Services
+	PaymentProcessorService *service.PaymentProcessorService
+
+	// Use Cases
+	RequestPaymentUC  *usecase.RequestPaymentUseCase
+	AuditPaymentsUC   *usecase.AuditPaymentsUseCase
+	ProcessPaymentsUC *usecase.ProcessPaymentsUseCase
+	UpdateSettingsUC  *usecase.UpdateSettingsUseCase
+	ManageQueueUC     *usecase.ManageQueueUseCase
+	RateLimitUC       *usecase.RateLimitUseCase
+	AuthenticateUC    *usecase.AuthenticateUseCase
+	DeliverWebhooksUC *usecase.DeliverWebhooksUseCase
+	StreamPaymentsUC  *usecase.StreamPaymentsUseCase
+
+	// Infrastructure
+	HTTPServer *http_server.Server
+	GRPCServer *grpc_server.Server // nil when gRPC is disabled
+}
+
+// Option adjusts a container before its dependencies are wired
+type Option func(*Container)
+
+// WithSettings makes the container use the given settings bus and audit log
+// instead of the configured adapter, so in-process instances can share them
+// the way instances backed by the same Redis do
+func WithSettings(bus port.SettingsBus, auditLog port.SettingsAuditLog) Option {
+	return func(c *Container) {
+		c.SettingsBus = bus
+		c.SettingsAuditLog = auditLog
+	}
+}
+
+// NewContainer creates and wires all dependencies for the given configuration
+func NewContainer(cfg *config.Config, opts ...Option) (*Container, error) {
+	c := &Container{Config: cfg}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	var err error
+
+	// Initialize payment repository
+	switch c.Config.Adapters.Repository {
+	case "postgres":
+		c.Repository, err = postgres_repository.NewPostgresRepository(c.Config.Database.ConnectionString, c.Config.Database.AutoMigrate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize PostgreSQL repository: %w", err)
+		}
+	case "memory":
+		c.Repository = in_memory_repository.NewInMemoryRepository()
+	default:
+		return nil, fmt.Errorf("unknown repository adapter %q", c.Config.Adapters.Repository)
+	}
+
+	// Initialize payment queue
+	switch c.Config.Adapters.Queue {
+	case "redis":
+		c.Queue, err = redis_repository.NewRedisQueue(c.Config.Redis.URL, c.Config.Redis.QueueKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Redis queue: %w", err)
+		}
+	case "redis-stream":
+		stream := c.Config.Redis.Stream
+		c.Queue, err = redis_repository.NewRedisStreamQueue(c.Config.Redis.URL, stream.Key, stream.Group,
+			c.Config.Server.InstanceID, stream.ClaimIdle, int64(stream.MaxLen))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Redis stream queue: %w", err)
+		}
+	case "postgres":
+		database := c.Config.Database
+		pgQueue, err := postgres_repository.NewPostgresQueue(database.ConnectionString, database.AutoMigrate,
+			c.Config.Server.InstanceID, database.Queue.Lease)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Postgres queue: %w", err)
+		}
+		c.Queue = pgQueue
+
+		// Record payments and complete their queue rows in one transaction
+		if pgRepo, ok := c.Repository.(*postgres_repository.PostgresRepository); ok && database.Queue.CompleteWithPayment {
+			c.Repository = postgres_repository.NewCompletingRepository(pgRepo, pgQueue)
+		}
+	case "memory":
+		c.Queue = in_memory_repository.NewInMemoryQueue(c.Config.Processor.QueueBufferSize)
+	default:
+		return nil, fmt.Errorf("unknown queue adapter %q", c.Config.Adapters.Queue)
+	}
+
+	// Initialize UUID store
+	switch c.Config.Adapters.Store {
+	case "redis":
+		c.Store, err = redis_repository.NewRedisStore(c.Config.Redis.URL, c.Config.Redis.UuidTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Redis store: %w", err)
+		}
+	case "memory":
+		c.Store = in_memory_repository.NewInMemoryStore()
+	default:
+		return nil, fmt.Errorf("unknown store adapter %q", c.Config.Adapters.Store)
+	}
+
+	// Initialize runtime settings bus and audit log, unless given as options
+	switch {
+	case c.SettingsBus != nil && c.SettingsAuditLog != nil:
+		// Shared with other containers
+	case c.Config.Adapters.Settings == "redis":
+		if c.SettingsBus, err = redis_repository.NewRedisSettingsBus(c.Config.Redis.URL, ""); err != nil {
+			return nil, fmt.Errorf("failed to initialize Redis settings bus: %w", err)
+		}
+		if c.SettingsAuditLog, err = redis_repository.NewRedisSettingsAuditLog(c.Config.Redis.URL, ""); err != nil {
+			return nil, fmt.Errorf("failed to initialize Redis settings audit log: %w", err)
+		}
+	case c.Config.Adapters.Settings == "memory":
+		c.SettingsBus = in_memory_repository.NewInMemorySettingsBus()
+		c.SettingsAuditLog = in_memory_repository.NewInMemorySettingsAuditLog()
+	default:
+		return nil, fmt.Errorf("unknown settings adapter %q", c.Config.Adapters.Settings)
+	}
+
+	// Initialize the rate limiter, only when a rate is configured
+	if c.Config.RateLimit.Rate > 0 {
+		switch c.Config.Adapters.RateLimiter {
+		case "redis":
+			if c.RateLimiter, err = redis_repository.NewRedisRateLimiter(c.Config.Redis.URL, ""); err != nil {
+				return nil, fmt.Errorf("failed to initialize Redis rate limiter: %w", err)
+			}
+		case "memory":
+			c.RateLimiter = in_memory_repository.NewInMemoryRateLimiter()
+		default:
+			return nil, fmt.Errorf("unknown rate limiter adapter %q", c.Config.Adapters.RateLimiter)
+		}
+	}
+
+	// Initialize the API key store and signature replay cache, only when
+	// authentication is enabled
+	if c.Config.Auth.Enabled {
+		switch c.Config.Adapters.Auth {
+		case "redis":
+			if c.APIKeyStore, err = redis_repository.NewRedisAPIKeyStore(c.Config.Redis.URL, ""); err != nil {
+				return nil, fmt.Errorf("failed to initialize Redis API key store: %w", err)
+			}
+			if c.ReplayCache, err = redis_repository.NewRedisReplayCache(c.Config.Redis.URL, ""); err != nil {
+				return nil, fmt.Errorf("failed to initialize Redis replay cache: %w", err)
+			}
+		case "memory":
+			c.APIKeyStore = in_memory_repository.NewInMemoryAPIKeyStore()
+			c.ReplayCache = in_memory_repository.NewInMemoryReplayCache()
+		default:
+			return nil, fmt.Errorf("unknown auth adapter %q", c.Config.Adapters.Auth)
+		}
+	}
+
+	// Initialize the webhook delivery log, only when webhooks are enabled
+	webhooks := c.Config.Webhooks
+	if webhooks.Enabled {
+		switch c.Config.Adapters.Webhooks {
+		case "redis":
+			if c.WebhookLog, err = redis_repository.NewRedisWebhookLog(c.Config.Redis.URL, "", webhooks.Retention); err != nil {
+				return nil, fmt.Errorf("failed to initialize Redis webhook log: %w", err)
+			}
+		case "memory":
+			c.WebhookLog = in_memory_repository.NewInMemoryWebhookLog(webhooks.Retention)
+		default:
+			return nil, fmt.Errorf("unknown webhooks adapter %q", c.Config.Adapters.Webhooks)
+		}
+	}
+	c.DeliverWebhooksUC = usecase.NewDeliverWebhooksUseCase(
+		c.WebhookLog,
+		http_client.NewWebhookClient(webhooks.Timeout, webhooks.AllowPrivateCallbacks),
+		webhooks.URLs,
+		webhooks.Secret,
+		domain.WebhookRetryPolicy{MaxAttempts: webhooks.MaxAttempts, Backoff: webhooks.Backoff, MaxBackoff: webhooks.MaxBackoff},
+		webhooks.Timeout,
+		c.Config.Server.InstanceID,
+	)
+
+	// Initialize the payment event bus, only when the event stream is enabled
+	if c.Config.EventStream.Enabled {
+		switch c.Config.Adapters.EventStream {
+		case "redis":
+			if c.PaymentEventBus, err = redis_repository.NewRedisPaymentEventBus(c.Config.Redis.URL, ""); err != nil {
+				return nil, fmt.Errorf("failed to initialize Redis payment event bus: %w", err)
+			}
+		case "memory":
+			c.PaymentEventBus = in_memory_repository.NewInMemoryPaymentEventBus()
+		default:
+			return nil, fmt.Errorf("unknown event stream adapter %q", c.Config.Adapters.EventStream)
+		}
+	}
+	c.StreamPaymentsUC = usecase.NewStreamPaymentsUseCase(c.PaymentEventBus, c.Repository,
+		c.Config.EventStream.SnapshotInterval, c.Config.Server.InstanceID)
+
+	// Payment outcomes go to the webhooks and the event stream
+	events := service.PaymentEventPublishers{c.DeliverWebhooksUC, c.StreamPaymentsUC}
+
+	// Initialize processor clients. Every processor but the last goes through
+	// its own circuit breaker; the last one is always attempted.
+	var tunables []port.Tunable
+	targets := c.Config.Processor.Targets()
+	for i, target := range targets {
+		client := http_client.NewPaymentProcessorClient(target.URL, c.Config.Processor.Timeout)
+		routed := service.RoutedProcessor{Channel: target.Channel, Processor: client}
+		tunables = append(tunables, client)
+
+		if i < len(targets)-1 {
+			circuitBreaker := http_client.NewCircuitBreakerAdapter(
+				"payment-processor-"+target.Channel.String(),
+				c.Config.Processor.CircuitBreaker.MaxRequests,
+				c.Config.Processor.CircuitBreaker.Interval,
+				c.Config.Processor.CircuitBreaker.Timeout,
+				c.Config.Processor.CircuitBreaker.FailureRatio,
+				c.Config.Processor.CircuitBreaker.MinRequests,
+			)
+			routed.CircuitBreaker = circuitBreaker
+			tunables = append(tunables, circuitBreaker)
+		}
+		c.Processors = append(c.Processors, routed)
+	}
+
+	// Initialize domain services
+	c.PaymentProcessorService = service.NewPaymentProcessorService(c.Processors, c.Repository, events)
+
+	// Initialize use cases
+	c.RequestPaymentUC = usecase.NewRequestPaymentUseCase(c.Queue, c.Store, events, c.Config.Webhooks.CallbackHosts)
+	// Queues that cannot be inspected leave the manager nil
+	queueManager, _ := c.Queue.(port.QueueManager)
+	c.AuditPaymentsUC = usecase.NewAuditPaymentsUseCase(c.Repository, c.Store, queueManager)
+	c.ProcessPaymentsUC = usecase.NewProcessPaymentsUseCase(
+		c.Queue, c.PaymentProcessorService, events, queueManager, c.Config.Processor.MaxAttempts,
+		c.Config.Server.InstanceID, c.Config.Server.WorkerConcurrency,
+	)
+	c.UpdateSettingsUC = usecase.NewUpdateSettingsUseCase(
+		resilienceSettings(c.Config),
+		append(tunables, c.ProcessPaymentsUC),
+		c.SettingsBus,
+		c.SettingsAuditLog,
+		c.Config.Server.InstanceID,
+	)
+
+	c.ManageQueueUC = usecase.NewManageQueueUseCase(queueManager, c.Store, events, c.Config.Server.InstanceID)
+
+	rateLimit := c.Config.RateLimit
+	quotas := make(map[string]domain.RateLimitQuota, len(rateLimit.Quotas))
+	for name, q := range rateLimit.Quotas {
+		quotas[name] = domain.RateLimitQuota{Rate: q.Rate, Burst: q.Burst}
+	}
+	c.RateLimitUC = usecase.NewRateLimitUseCase(c.RateLimiter,
+		domain.RateLimitQuota{Rate: rateLimit.Rate, Burst: rateLimit.Burst}, quotas)
+
+	auth := c.Config.Auth
+	c.AuthenticateUC = usecase.NewAuthenticateUseCase(c.APIKeyStore, c.ReplayCache,
+		domain.SigningMode(auth.Signing), auth.SignatureTolerance, c.Config.Server.InstanceID)
+	if c.APIKeyStore != nil {
+		if err := c.AuthenticateUC.Seed(context.Background(), configuredAPIKeys(auth.APIKeys)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize HTTP server
+	c.HTTPServer = http_server.NewServer(c.RequestPaymentUC, c.AuditPaymentsUC, c.UpdateSettingsUC, c.ManageQueueUC,
+		c.RateLimitUC, c.AuthenticateUC, c.DeliverWebhooksUC, c.StreamPaymentsUC, c.Config)
+
+	// Initialize gRPC server, only when enabled
+	if c.Config.GRPC.Enabled {
+		c.GRPCServer = grpc_server.NewServer(c.RequestPaymentUC, c.AuditPaymentsUC, c.RateLimitUC, c.AuthenticateUC, c.Config)
+	}
+
+	return c, nil
+}
+
+// Start starts all background services
+func (c *Container) Start(ctx context.Context) error {
+	c.ProcessPaymentsUC.Start(ctx)
+	c.DeliverWebhooksUC.Start(ctx)
+	if err := c.StreamPaymentsUC.Start(ctx); err != nil {
+		return err
+	}
+	return c.UpdateSettingsUC.Start(ctx)
+}
+
+// Stop gracefully stops all services
+func (c *Container) Stop() error {
+	// Stop taking gRPC calls before the queue is closed
+	if c.GRPCServer != nil {
+		c.GRPCServer.Stop()
+	}
+
+	// Then stop the payment processor use case
+	if err := c.ProcessPaymentsUC.Stop(); err != nil {
+		log.Printf("Error stopping payment processor: %v", err)
+	}
+
+	// Close PostgreSQL connection if using postgres repository
+	if pgRepo, ok := c.Repository.(*postgres_repository.PostgresRepository); ok {
+		pgRepo.Close()
+	}
+	if completing, ok := c.Repository.(*postgres_repository.CompletingRepository); ok {
+		completing.Close()
+	}
+	if pgQueue, ok := c.Queue.(*postgres_repository.PostgresQueue); ok {
+		if err := pgQueue.Close(); err != nil {
+			log.Printf("Error closing Postgres queue: %v", err)
+		}
+	}
+
+	// Close Redis queue connection
+	if redisQueue, ok := c.Queue.(*redis_repository.RedisQueue); ok {
+		if err := redisQueue.Close(); err != nil {
+			log.Printf("Error closing Redis queue: %v", err)
+		}
+	}
+	if streamQueue, ok := c.Queue.(*redis_repository.RedisStreamQueue); ok {
+		if err := streamQueue.Close(); err != nil {
+			log.Printf("Error closing Redis stream queue: %v", err)
+		}
+	}
+
+	// Close Redis store connection
+	if redisStore, ok := c.Store.(*redis_repository.RedisStore); ok {
+		if err := redisStore.Close(); err != nil {
+			log.Printf("Error closing Redis store: %v", err)
+		}
+	}
+
+	// Close Redis rate limiter connection
+	if redisLimiter, ok := c.RateLimiter.(*redis_repository.RedisRateLimiter); ok {
+		if err := redisLimiter.Close(); err != nil {
+			log.Printf("Error closing Redis rate limiter: %v", err)
+		}
+	}
+
+	// Close Redis auth connections
+	if redisKeys, ok := c.APIKeyStore.(*redis_repository.RedisAPIKeyStore); ok {
+		if err := redisKeys.Close(); err != nil {
+			log.Printf("Error closing Redis API key store: %v", err)
+		}
+	}
+	if redisReplay, ok := c.ReplayCache.(*redis_repository.RedisReplayCache); ok {
+		if err := redisReplay.Close(); err != nil {
+			log.Printf("Error closing Redis replay cache: %v", err)
+		}
+	}
+
+	// Close Redis webhook log connection
+	if redisWebhooks, ok := c.WebhookLog.(*redis_repository.RedisWebhookLog); ok {
+		if err := redisWebhooks.Close(); err != nil {
+			log.Printf("Error closing Redis webhook log: %v", err)
+		}
+	}
+
+	// Close Redis payment event bus connection
+	if redisEvents, ok := c.PaymentEventBus.(*redis_repository.RedisPaymentEventBus); ok {
+		if err := redisEvents.Close(); err != nil {
+			log.Printf("Error closing Redis payment event bus: %v", err)
+		}
+	}
+
+	// Close Redis settings connections
+	if redisBus, ok := c.SettingsBus.(*redis_repository.RedisSettingsBus); ok {
+		if err := redisBus.Close(); err != nil {
+			log.Printf("Error closing Redis settings bus: %v", err)
+		}
+	}
+	if redisAuditLog, ok := c.SettingsAuditLog.(*redis_repository.RedisSettingsAuditLog); ok {
+		if err := redisAuditLog.Close(); err != nil {
+			log.Printf("Error closing Redis settings audit log: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// resilienceSettings extracts the runtime-tunable settings from the configuration
+func resilienceSettings(cfg *config.Config) domain.ResilienceSettings {
+	cb := cfg.Processor.CircuitBreaker
+	return domain.ResilienceSettings{
+		CircuitBreaker: domain.CircuitBreakerSettings{
+			MaxRequests:  cb.MaxRequests,
+			Interval:     cb.Interval,
+			Timeout:      cb.Timeout,
+			FailureRatio: cb.FailureRatio,
+			MinRequests:  cb.MinRequests,
+		},
+		ProcessorTimeout:  cfg.Processor.Timeout,
+		WorkerConcurrency: cfg.Server.WorkerConcurrency,
+	}
+}
+
+// configuredAPIKeys converts the API keys of the configuration
+func configuredAPIKeys(keys []config.APIKeyConfig) []domain.APIKey {
+	now := time.Now().UTC()
+	apiKeys := make([]domain.APIKey, 0, len(keys))
+	for _, k := range keys {
+		apiKeys = append(apiKeys, domain.APIKey{
+			ID:        domain.APIKeyID(k.Key),
+			Client:    k.Client,
+			CreatedAt: now,
+			ExpiresAt: k.ExpiresAt,
+		})
+	}
+	return apiKeys
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces secrets in the effective configuration
+const redactedValue = "xxxxx"
+
+// Redacted returns a copy of the configuration with credentials removed
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Database.ConnectionString = redactURL(c.Database.ConnectionString)
+	redacted.Redis.URL = redactURL(c.Redis.URL)
+	if len(c.Control.Tokens) > 0 {
+		redacted.Control.Tokens = make(map[string]string, len(c.Control.Tokens))
+		for name := range c.Control.Tokens {
+			redacted.Control.Tokens[name] = redactedValue
+		}
+	}
+	if len(c.Auth.APIKeys) > 0 {
+		redacted.Auth.APIKeys = make([]APIKeyConfig, len(c.Auth.APIKeys))
+		for i, key := range c.Auth.APIKeys {
+			key.Key = redactedValue
+			redacted.Auth.APIKeys[i] = key
+		}
+	}
+	if c.Webhooks.Secret != "" {
+		redacted.Webhooks.Secret = redactedValue
+	}
+	return &redacted
+}
+
+// Effective returns the redacted configuration as nested maps keyed like the
+// config file, suitable for JSON responses
+func (c *Config) Effective() (map[string]interface{}, error) {
+	data, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return values, nil
+}
+
+// YAML returns the redacted configuration in config file format
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c.Redacted())
+}
+
+// redactURL hides the password of a URL, or the whole value if it cannot be parsed
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return redactedValue
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), redactedValue)
+	}
+	return u.String()
+}
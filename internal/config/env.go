@@ -0,0 +1,245 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envParser applies environment variable overrides and collects every
+// parse error instead of silently keeping the previous value
+type envParser struct {
+	errs []error
+}
+
+func (e *envParser) fail(key, value, kind string, err error) {
+	e.errs = append(e.errs, fmt.Errorf("%s: %q is not a valid %s: %w", key, value, kind, err))
+}
+
+func (e *envParser) str(key string, target *string) {
+	if value := os.Getenv(key); value != "" {
+		*target = value
+	}
+}
+
+func (e *envParser) int(key string, target *int) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	intVal, err := strconv.Atoi(value)
+	if err != nil {
+		e.fail(key, value, "integer", err)
+		return
+	}
+	*target = intVal
+}
+
+func (e *envParser) uint32(key string, target *uint32) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	uintVal, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		e.fail(key, value, "unsigned integer", err)
+		return
+	}
+	*target = uint32(uintVal)
+}
+
+func (e *envParser) bool(key string, target *bool) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	boolVal, err := strconv.ParseBool(value)
+	if err != nil {
+		e.fail(key, value, "boolean", err)
+		return
+	}
+	*target = boolVal
+}
+
+func (e *envParser) float(key string, target *float64) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	floatVal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		e.fail(key, value, "number", err)
+		return
+	}
+	*target = floatVal
+}
+
+// duration requires an explicit unit, so "10" is rejected instead of being read as 10ns
+func (e *envParser) duration(key string, target *time.Duration) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		e.fail(key, value, "duration (use a unit such as 500ms, 5s or 1m)", err)
+		return
+	}
+	*target = duration
+}
+
+// tokens parses comma separated name:token pairs
+func (e *envParser) tokens(key string, target *map[string]string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		name, token, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			e.errs = append(e.errs, fmt.Errorf("%s: entries must be name:token pairs", key))
+			return
+		}
+		tokens[name] = token
+	}
+	*target = tokens
+}
+
+// processors parses comma separated name[:priority]=url entries. Without an
+// explicit priority, processors are tried in the listed order.
+func (e *envParser) processors(key string, target *[]ProcessorTarget) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	var processors []ProcessorTarget
+	for i, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		left, url, ok := strings.Cut(entry, "=")
+		if !ok {
+			e.errs = append(e.errs, fmt.Errorf("%s: entries must be name[:priority]=url, got %q", key, entry))
+			return
+		}
+
+		p := ProcessorTarget{Name: left, URL: url, Priority: i}
+		if name, priority, ok := strings.Cut(left, ":"); ok {
+			n, err := strconv.Atoi(priority)
+			if err != nil {
+				e.fail(key, priority, "priority", err)
+				return
+			}
+			p.Name, p.Priority = name, n
+		}
+		processors = append(processors, p)
+	}
+	*target = processors
+}
+
+// apiKeys parses comma separated client:key pairs; a client may be listed
+// more than once while rotating
+func (e *envParser) apiKeys(key string, target *[]APIKeyConfig) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	var keys []APIKeyConfig
+	for _, pair := range strings.Split(value, ",") {
+		client, apiKey, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			e.errs = append(e.errs, fmt.Errorf("%s: entries must be client:key pairs", key))
+			return
+		}
+		keys = append(keys, APIKeyConfig{Client: client, Key: apiKey})
+	}
+	*target = keys
+}
+
+// urls parses comma separated name=url entries
+func (e *envParser) urls(key string, target *map[string]string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	urls := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		name, url, ok := strings.Cut(entry, "=")
+		if !ok {
+			e.errs = append(e.errs, fmt.Errorf("%s: entries must be name=url, got %q", key, entry))
+			return
+		}
+		urls[name] = url
+	}
+	*target = urls
+}
+
+// hosts parses comma separated name=host|host entries
+func (e *envParser) hosts(key string, target *map[string][]string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	hosts := make(map[string][]string)
+	for i, entry := range strings.Split(value, ",") {
+		name, list, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" || list == "" {
+			e.errs = append(e.errs, fmt.Errorf("%s: entry %d must be name=host|host", key, i+1))
+			return
+		}
+		hosts[name] = append(hosts[name], strings.Split(list, "|")...)
+	}
+	*target = hosts
+}
+
+// quotas parses comma separated name=rate/burst entries
+func (e *envParser) quotas(key string, target *map[string]RateLimitQuota) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	quotas := make(map[string]RateLimitQuota)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		name, quota, ok := strings.Cut(entry, "=")
+		rate, burst, ok2 := strings.Cut(quota, "/")
+		if !ok || !ok2 {
+			e.errs = append(e.errs, fmt.Errorf("%s: entries must be name=rate/burst, got %q", key, entry))
+			return
+		}
+
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			e.fail(key, rate, "rate", err)
+			return
+		}
+		b, err := strconv.Atoi(burst)
+		if err != nil {
+			e.fail(key, burst, "burst", err)
+			return
+		}
+		quotas[name] = RateLimitQuota{Rate: r, Burst: b}
+	}
+	*target = quotas
+}
+
+func (e *envParser) err() error {
+	return errors.Join(e.errs...)
+}
+
+// Helper functions for environment variable parsing
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// minTokenLength is the shortest accepted control token
+const minTokenLength = 16
+
+// minTimeout guards against durations that are almost certainly a missing unit
+const minTimeout = time.Millisecond
+
+// Validate checks that every value is within its allowed range
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	positiveDuration := func(name string, d time.Duration) {
+		check(d >= minTimeout, "%s must be at least %s, got %s", name, minTimeout, d)
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Port); err != nil {
+		errs = append(errs, fmt.Errorf("server.port must be host:port or :port, got %q", c.Server.Port))
+	}
+	if c.GRPC.Enabled {
+		if _, _, err := net.SplitHostPort(c.GRPC.Port); err != nil {
+			errs = append(errs, fmt.Errorf("grpc.port must be host:port or :port, got %q", c.GRPC.Port))
+		}
+		check(c.GRPC.Port != c.Server.Port, "grpc.port must differ from server.port, both are %q", c.GRPC.Port)
+	}
+	positiveDuration("server.read_timeout", c.Server.ReadTimeout)
+	positiveDuration("server.write_timeout", c.Server.WriteTimeout)
+	positiveDuration("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	check(c.Server.InstanceID != "", "server.instance_id is required")
+	check(c.Server.WorkerConcurrency >= 1 && c.Server.WorkerConcurrency <= 1024,
+		"server.worker_concurrency must be between 1 and 1024, got %d", c.Server.WorkerConcurrency)
+
+	if len(c.Processor.Processors) == 0 {
+		errs = append(errs, validateURL("processor.default_url", c.Processor.DefaultURL, "http", "https"))
+		errs = append(errs, validateURL("processor.fallback_url", c.Processor.FallbackURL, "http", "https"))
+	} else {
+		targets := c.Processor.Targets()
+		if err := domain.ValidateProcessors(targets); err != nil {
+			errs = append(errs, fmt.Errorf("processor.processors: %w", err))
+		}
+		for _, p := range targets {
+			errs = append(errs, validateURL(fmt.Sprintf("processor.processors[%s].url", p.Channel), p.URL, "http", "https"))
+		}
+	}
+	positiveDuration("processor.timeout", c.Processor.Timeout)
+	check(c.Processor.MaxRetries >= 0, "processor.max_retries must not be negative, got %d", c.Processor.MaxRetries)
+	check(c.Processor.MaxAttempts >= 0, "processor.max_attempts must not be negative, got %d", c.Processor.MaxAttempts)
+	check(c.Processor.QueueBufferSize >= 1, "processor.queue_buffer_size must be at least 1, got %d", c.Processor.QueueBufferSize)
+
+	cb := c.Processor.CircuitBreaker
+	// An interval of zero means counts are never cleared while closed
+	check(cb.Interval == 0 || cb.Interval >= minTimeout,
+		"processor.circuit_breaker.interval must be 0 or at least %s, got %s", minTimeout, cb.Interval)
+	positiveDuration("processor.circuit_breaker.timeout", cb.Timeout)
+	check(cb.FailureRatio > 0 && cb.FailureRatio <= 1,
+		"processor.circuit_breaker.failure_ratio must be in (0, 1], got %g", cb.FailureRatio)
+	check(cb.MinRequests >= 1, "processor.circuit_breaker.min_requests must be at least 1, got %d", cb.MinRequests)
+
+	if c.Adapters.Repository == "postgres" || c.Adapters.Queue == "postgres" {
+		errs = append(errs, validateURL("database.connection_string", c.Database.ConnectionString, "postgres", "postgresql"))
+		check(c.Database.MaxConnections >= 1, "database.max_connections must be at least 1, got %d", c.Database.MaxConnections)
+		positiveDuration("database.connect_timeout", c.Database.ConnectTimeout)
+	}
+	if c.Adapters.Queue == "postgres" {
+		check(c.Database.Queue.Lease >= time.Second, "database.queue.lease must be at least 1s, got %s", c.Database.Queue.Lease)
+	}
+
+	rateLimited := c.RateLimit.Rate > 0
+	if strings.HasPrefix(c.Adapters.Queue, "redis") || c.Adapters.Store == "redis" || c.Adapters.Settings == "redis" ||
+		(rateLimited && c.Adapters.RateLimiter == "redis") || (c.Auth.Enabled && c.Adapters.Auth == "redis") ||
+		(c.Webhooks.Enabled && c.Adapters.Webhooks == "redis") ||
+		(c.EventStream.Enabled && c.Adapters.EventStream == "redis") {
+		errs = append(errs, validateURL("redis.url", c.Redis.URL, "redis", "rediss"))
+		check(c.Redis.PoolSize >= 1, "redis.pool_size must be at least 1, got %d", c.Redis.PoolSize)
+		check(c.Redis.QueueKey != "", "redis.queue_key is required")
+		positiveDuration("redis.uuid_ttl", c.Redis.UuidTTL)
+	}
+
+	if c.Adapters.Queue == "redis-stream" {
+		stream := c.Redis.Stream
+		check(stream.Key != "", "redis.stream.key is required")
+		check(stream.Group != "", "redis.stream.group is required")
+		check(stream.ClaimIdle >= time.Second, "redis.stream.claim_idle must be at least 1s, got %s", stream.ClaimIdle)
+		check(stream.MaxLen >= 1000, "redis.stream.max_len must be at least 1000, got %d", stream.MaxLen)
+	}
+
+	check(oneOf(c.Adapters.Repository, "postgres", "memory"),
+		"adapters.repository must be postgres or memory, got %q", c.Adapters.Repository)
+	check(oneOf(c.Adapters.Queue, "redis", "redis-stream", "postgres", "memory"),
+		"adapters.queue must be redis, redis-stream, postgres or memory, got %q", c.Adapters.Queue)
+	check(oneOf(c.Adapters.Store, "redis", "memory"),
+		"adapters.store must be redis or memory, got %q", c.Adapters.Store)
+	check(oneOf(c.Adapters.Settings, "redis", "memory"),
+		"adapters.settings must be redis or memory, got %q", c.Adapters.Settings)
+
+	check(oneOf(c.Adapters.RateLimiter, "redis", "memory"),
+		"adapters.rate_limiter must be redis or memory, got %q", c.Adapters.RateLimiter)
+	check(oneOf(c.Adapters.Auth, "redis", "memory"),
+		"adapters.auth must be redis or memory, got %q", c.Adapters.Auth)
+	check(oneOf(c.Adapters.Webhooks, "redis", "memory"),
+		"adapters.webhooks must be redis or memory, got %q", c.Adapters.Webhooks)
+	check(oneOf(c.Adapters.EventStream, "redis", "memory"),
+		"adapters.event_stream must be redis or memory, got %q", c.Adapters.EventStream)
+
+	if rateLimited {
+		check(c.RateLimit.Burst >= 1, "rate_limit.burst must be at least 1, got %d", c.RateLimit.Burst)
+	}
+	check(c.RateLimit.Rate >= 0, "rate_limit.rate must not be negative, got %g", c.RateLimit.Rate)
+	for name, quota := range c.RateLimit.Quotas {
+		check(quota.Rate > 0 && quota.Burst >= 1,
+			"rate_limit.quotas[%s] needs a positive rate and a burst of at least 1, got %g/%d", name, quota.Rate, quota.Burst)
+	}
+
+	check(oneOf(c.Auth.Signing, string(domain.SigningOff), string(domain.SigningOptional), string(domain.SigningRequired)),
+		"auth.signing must be off, optional or required, got %q", c.Auth.Signing)
+	check(c.Auth.SignatureTolerance >= time.Second,
+		"auth.signature_tolerance must be at least 1s, got %s", c.Auth.SignatureTolerance)
+	for i, key := range c.Auth.APIKeys {
+		check(key.Client != "", "auth.api_keys[%d] needs a client", i)
+		check(len(key.Key) >= minTokenLength,
+			"auth.api_keys[%d] of %s must be at least %d characters long", i, key.Client, minTokenLength)
+	}
+
+	if wh := c.Webhooks; wh.Enabled {
+		check(len(wh.Secret) >= minTokenLength, "webhooks.secret must be at least %d characters long", minTokenLength)
+		check(wh.MaxAttempts >= 1 && wh.MaxAttempts <= 100,
+			"webhooks.max_attempts must be between 1 and 100, got %d", wh.MaxAttempts)
+		positiveDuration("webhooks.backoff", wh.Backoff)
+		check(wh.MaxBackoff >= wh.Backoff, "webhooks.max_backoff must be at least webhooks.backoff, got %s", wh.MaxBackoff)
+		positiveDuration("webhooks.timeout", wh.Timeout)
+		check(wh.Retention >= time.Minute, "webhooks.retention must be at least 1m, got %s", wh.Retention)
+		for client, u := range wh.URLs {
+			errs = append(errs, validateURL(fmt.Sprintf("webhooks.urls[%s]", client), u, "http", "https"))
+		}
+		for client, hosts := range wh.CallbackHosts {
+			for i, host := range hosts {
+				check(host != "" && !strings.ContainsAny(host, "/:@ "),
+					"webhooks.callback_hosts[%s][%d] must be a bare host name, got %q", client, i, host)
+			}
+		}
+	}
+
+	if c.EventStream.Enabled {
+		check(c.EventStream.SnapshotInterval >= time.Second,
+			"event_stream.snapshot_interval must be at least 1s, got %s", c.EventStream.SnapshotInterval)
+	}
+
+	for name, token := range c.Control.Tokens {
+		check(name != "", "control.tokens must not have an empty name")
+		check(len(token) >= minTokenLength,
+			"control.tokens[%s] must be at least %d characters long", name, minTokenLength)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
+
+func validateURL(name, raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid URL: %w", name, err)
+	}
+	if !oneOf(u.Scheme, schemes...) || u.Host == "" {
+		return fmt.Errorf("%s must be a %s URL with a host", name, schemes[0])
+	}
+	return nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// APIKey is a key a client authenticates with. A client may hold several
+// keys while rotating; the old ones stop working at ExpiresAt.
+type APIKey struct {
+	// ID is the SHA-256 of the secret, safe to store and log
+	ID        string     `json:"id"`
+	Client    string     `json:"client"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// APIKeyID returns the ID of the key with the given secret
+func APIKeyID(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// ActiveAt reports whether the key can be used at t
+func (k APIKey) ActiveAt(t time.Time) bool {
+	return k.ExpiresAt == nil || t.Before(*k.ExpiresAt)
+}
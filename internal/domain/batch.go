@@ -0,0 +1,24 @@
+package domain
+
+// BatchItemStatus tells what happened to one payment of a batch
+type BatchItemStatus string
+
+// Batch item statuses
+const (
+	// BatchItemAccepted means the payment was queued
+	BatchItemAccepted BatchItemStatus = "accepted"
+	// BatchItemDuplicate means the correlation ID was already requested,
+	// earlier in the batch or before it
+	BatchItemDuplicate BatchItemStatus = "duplicate"
+	// BatchItemInvalid means the payment failed validation
+	BatchItemInvalid BatchItemStatus = "invalid"
+	// BatchItemFailed means the payment could not be queued and may be retried
+	BatchItemFailed BatchItemStatus = "failed"
+)
+
+// BatchItemResult is the outcome of one payment of a batch
+type BatchItemResult struct {
+	CorrelationId string          `json:"correlationId,omitempty"`
+	Status        BatchItemStatus `json:"status"`
+	Error         string          `json:"error,omitempty"`
+}
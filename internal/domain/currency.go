@@ -0,0 +1,36 @@
+package domain
+
+import "strings"
+
+// Currency is an ISO-4217 alphabetic currency code
+type Currency string
+
+// DefaultCurrency is assumed for payments that do not name a currency
+const DefaultCurrency Currency = "BRL"
+
+// isoCurrencies lists the active ISO-4217 alphabetic codes
+var isoCurrencies = func() map[Currency]bool {
+	codes := `AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB
+		BRL BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD
+		EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS
+		INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD
+		LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK
+		NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK
+		SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH
+		UGX USD UYU UZS VES VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG`
+	set := make(map[Currency]bool)
+	for _, code := range strings.Fields(codes) {
+		set[Currency(code)] = true
+	}
+	return set
+}()
+
+// Valid reports whether the currency is an active ISO-4217 code
+func (c Currency) Valid() bool {
+	return isoCurrencies[c]
+}
+
+// String returns the currency code
+func (c Currency) String() string {
+	return string(c)
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// PaymentEventType tells what happened to a payment
+type PaymentEventType string
+
+// Payment event types
+const (
+	// PaymentAccepted is raised when the API queued the payment
+	PaymentAccepted PaymentEventType = "payment.accepted"
+	// PaymentCompleted is raised when a processor accepted the payment
+	PaymentCompleted PaymentEventType = "payment.completed"
+	// PaymentRetried is raised when every processor failed and the payment
+	// was queued again
+	PaymentRetried PaymentEventType = "payment.retried"
+	// PaymentFailed is raised when the payment is moved to the dead-letter queue
+	PaymentFailed PaymentEventType = "payment.failed"
+)
+
+// PaymentEvent reports the outcome of a payment
+type PaymentEvent struct {
+	ID         string           `json:"id"`
+	Type       PaymentEventType `json:"type"`
+	Payment    Payment          `json:"payment"`
+	Channel    ProcessorChannel `json:"channel,omitempty"` // processor that accepted the payment
+	Reason     string           `json:"reason,omitempty"`  // why the payment was retried or failed
+	OccurredAt time.Time        `json:"occurredAt"`
+}
+
+// NewPaymentAcceptedEvent reports a payment queued by the API
+func NewPaymentAcceptedEvent(payment Payment, at time.Time) PaymentEvent {
+	return PaymentEvent{
+		ID:         newEventID(),
+		Type:       PaymentAccepted,
+		Payment:    payment,
+		OccurredAt: at.UTC(),
+	}
+}
+
+// NewPaymentRetriedEvent reports a payment queued again after every
+// processor failed
+func NewPaymentRetriedEvent(payment Payment, reason string, at time.Time) PaymentEvent {
+	return PaymentEvent{
+		ID:         newEventID(),
+		Type:       PaymentRetried,
+		Payment:    payment,
+		Reason:     reason,
+		OccurredAt: at.UTC(),
+	}
+}
+
+// NewPaymentCompletedEvent reports a payment accepted by the processor of channel
+func NewPaymentCompletedEvent(payment Payment, channel ProcessorChannel, at time.Time) PaymentEvent {
+	return PaymentEvent{
+		ID:         newEventID(),
+		Type:       PaymentCompleted,
+		Payment:    payment,
+		Channel:    channel,
+		OccurredAt: at.UTC(),
+	}
+}
+
+// NewPaymentFailedEvent reports a payment that will not be processed
+func NewPaymentFailedEvent(payment Payment, reason string, at time.Time) PaymentEvent {
+	return PaymentEvent{
+		ID:         newEventID(),
+		Type:       PaymentFailed,
+		Payment:    payment,
+		Reason:     reason,
+		OccurredAt: at.UTC(),
+	}
+}
+
+// newEventID returns a random 128-bit hex ID
+func newEventID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// PaymentEventFilter selects the events and summary channels a stream
+// subscriber is interested in. The zero value selects everything.
+type PaymentEventFilter struct {
+	// Channels keeps only the events of payments accepted by these
+	// processors, dropping events that have no channel
+	Channels []ProcessorChannel
+	// Client keeps only the events of payments requested by this
+	// authenticated client
+	Client string
+}
+
+// Matches reports whether the event passes the filter
+func (f PaymentEventFilter) Matches(event PaymentEvent) bool {
+	if f.Client != "" && event.Payment.Client != f.Client {
+		return false
+	}
+	return len(f.Channels) == 0 || f.hasChannel(event.Channel)
+}
+
+// Summary returns the statistics of the filtered channels of a summary
+func (f PaymentEventFilter) Summary(summary PaymentsSummary) map[ProcessorChannel]PaymentsChannelStats {
+	channels := summary.Channels()
+	if len(f.Channels) == 0 {
+		return channels
+	}
+	for channel := range channels {
+		if !f.hasChannel(channel) {
+			delete(channels, channel)
+		}
+	}
+	return channels
+}
+
+func (f PaymentEventFilter) hasChannel(channel ProcessorChannel) bool {
+	for _, c := range f.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
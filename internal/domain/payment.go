@@ -1,13 +1,36 @@
 package domain
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
+	"time"
 )
 
 // Payment represents a payment request in the domain
 type Payment struct {
 	CorrelationId string  `json:"correlationId"`
 	Amount        float64 `json:"amount"`
+	// Currency is optional, payments without one are in DefaultCurrency
+	Currency Currency `json:"currency,omitempty"`
+	// Client is the authenticated API client that requested the payment,
+	// never taken from the request body
+	Client string `json:"client,omitempty"`
+	// CallbackURL receives the webhooks of this payment instead of the
+	// client's configured URL
+	CallbackURL string `json:"callbackUrl,omitempty"`
+	// Attempts counts the failed processing attempts, carried through the
+	// queue and never taken from the request
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// CurrencyOrDefault returns the payment currency, DefaultCurrency when unset
+func (p Payment) CurrencyOrDefault() Currency {
+	if p.Currency == "" {
+		return DefaultCurrency
+	}
+	return p.Currency
 }
 
 // AmountAsFloat returns the amount as a float64
@@ -29,23 +52,170 @@ func (p Payment) Validate() error {
 		return errors.New("correlation ID is required")
 	}
 
+	if p.Currency != "" && !p.Currency.Valid() {
+		return fmt.Errorf("unknown currency %q, expected an ISO-4217 code such as BRL or USD", p.Currency)
+	}
+
+	if p.CallbackURL != "" {
+		if u, err := url.Parse(p.CallbackURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("callback URL must be an absolute http or https URL")
+		}
+	}
+
 	_, err := p.AmountAsFloat()
 	return err
 }
 
-// PaymentsChannelStats represents statistics for a payment channel
-type PaymentsChannelStats struct {
+// PaymentStatus tells how far a requested payment got
+type PaymentStatus string
+
+const (
+	// PaymentPending is a payment waiting to be processed
+	PaymentPending PaymentStatus = "pending"
+	// PaymentProcessed is a payment accepted by a processor
+	PaymentProcessed PaymentStatus = "processed"
+	// PaymentDeadLettered is a payment that failed and waits in the
+	// dead-letter queue to be moved back or purged
+	PaymentDeadLettered PaymentStatus = "dead_lettered"
+)
+
+// PaymentRecord is a payment accepted by the processor of Channel
+type PaymentRecord struct {
+	Payment
+	Channel     ProcessorChannel `json:"channel"`
+	ProcessedAt time.Time        `json:"processedAt"`
+}
+
+// PaymentRecordFilter selects payment records. Nil bounds, an empty channel
+// and an empty client select everything.
+type PaymentRecordFilter struct {
+	From    *time.Time // inclusive
+	To      *time.Time // inclusive
+	Channel ProcessorChannel
+	Client  string // the authenticated client that requested the payments
+}
+
+// Matches reports whether the record passes the filter
+func (f PaymentRecordFilter) Matches(record PaymentRecord) bool {
+	if f.From != nil && record.ProcessedAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && record.ProcessedAt.After(*f.To) {
+		return false
+	}
+	if f.Client != "" && record.Client != f.Client {
+		return false
+	}
+	return f.Channel == "" || record.Channel == f.Channel
+}
+
+// CurrencyStats represents the payments of a channel in one currency
+type CurrencyStats struct {
 	TotalRequests int     `json:"totalRequests"`
 	TotalAmount   float64 `json:"totalAmount"`
 }
 
-// PaymentsSummary represents a summary of all payment channels
+// PaymentsChannelStats represents statistics for a payment channel. The
+// totals cover payments in DefaultCurrency only; other currencies are kept
+// apart in ByCurrency so amounts are never summed across currencies.
+type PaymentsChannelStats struct {
+	TotalRequests int                        `json:"totalRequests"`
+	TotalAmount   float64                    `json:"totalAmount"`
+	ByCurrency    map[Currency]CurrencyStats `json:"byCurrency,omitempty"`
+}
+
+// Add counts payments of the given currency
+func (s *PaymentsChannelStats) Add(currency Currency, requests int, amount float64) {
+	if currency == "" || currency == DefaultCurrency {
+		s.TotalRequests += requests
+		s.TotalAmount += amount
+		return
+	}
+	if s.ByCurrency == nil {
+		s.ByCurrency = make(map[Currency]CurrencyStats)
+	}
+	stats := s.ByCurrency[currency]
+	stats.TotalRequests += requests
+	stats.TotalAmount += amount
+	s.ByCurrency[currency] = stats
+}
+
+// PaymentsSummary represents a summary of all payment channels. Default and
+// fallback are always reported; other channels appear next to them in JSON
+// under their own names.
 type PaymentsSummary struct {
-	Default  PaymentsChannelStats `json:"default"`
-	Fallback PaymentsChannelStats `json:"fallback"`
+	Default  PaymentsChannelStats
+	Fallback PaymentsChannelStats
+	// Others holds the channels other than default and fallback
+	Others map[ProcessorChannel]PaymentsChannelStats
+}
+
+// Get returns the statistics of a channel
+func (s PaymentsSummary) Get(channel ProcessorChannel) PaymentsChannelStats {
+	switch channel {
+	case DefaultProcessor:
+		return s.Default
+	case FallbackProcessor:
+		return s.Fallback
+	default:
+		return s.Others[channel]
+	}
+}
+
+// Set replaces the statistics of a channel
+func (s *PaymentsSummary) Set(channel ProcessorChannel, stats PaymentsChannelStats) {
+	switch channel {
+	case DefaultProcessor:
+		s.Default = stats
+	case FallbackProcessor:
+		s.Fallback = stats
+	default:
+		if s.Others == nil {
+			s.Others = make(map[ProcessorChannel]PaymentsChannelStats)
+		}
+		s.Others[channel] = stats
+	}
+}
+
+// Record adds payments of a currency to a channel
+func (s *PaymentsSummary) Record(channel ProcessorChannel, currency Currency, requests int, amount float64) {
+	stats := s.Get(channel)
+	stats.Add(currency, requests, amount)
+	s.Set(channel, stats)
+}
+
+// Channels returns the statistics of every channel by name
+func (s PaymentsSummary) Channels() map[ProcessorChannel]PaymentsChannelStats {
+	channels := map[ProcessorChannel]PaymentsChannelStats{
+		DefaultProcessor:  s.Default,
+		FallbackProcessor: s.Fallback,
+	}
+	for channel, stats := range s.Others {
+		channels[channel] = stats
+	}
+	return channels
+}
+
+// MarshalJSON writes one key per channel, so the default/fallback shape is kept
+func (s PaymentsSummary) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Channels())
+}
+
+// UnmarshalJSON reads the object written by MarshalJSON
+func (s *PaymentsSummary) UnmarshalJSON(data []byte) error {
+	var channels map[ProcessorChannel]PaymentsChannelStats
+	if err := json.Unmarshal(data, &channels); err != nil {
+		return err
+	}
+	*s = PaymentsSummary{}
+	for channel, stats := range channels {
+		s.Set(channel, stats)
+	}
+	return nil
 }
 
-// ProcessorChannel represents the different payment processor channels
+// ProcessorChannel is the name of a payment processor, under which its
+// payments are summarized
 type ProcessorChannel string
 
 const (
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// processorNamePattern keeps channel names usable as JSON keys, URL segments and metric labels
+var processorNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// Processor is a payment processor payments can be routed to
+type Processor struct {
+	Channel  ProcessorChannel
+	URL      string
+	Priority int // lower is tried first
+}
+
+// ValidateProcessors checks names, uniqueness and priorities of a processor list
+func ValidateProcessors(processors []Processor) error {
+	if len(processors) == 0 {
+		return errors.New("at least one processor is required")
+	}
+
+	var errs []error
+	seen := make(map[ProcessorChannel]bool)
+	for _, p := range processors {
+		if !processorNamePattern.MatchString(string(p.Channel)) {
+			errs = append(errs, fmt.Errorf("invalid processor name %q, start with a lowercase letter and use letters, digits, - and _", p.Channel))
+		}
+		if seen[p.Channel] {
+			errs = append(errs, fmt.Errorf("processor %q is listed twice", p.Channel))
+		}
+		seen[p.Channel] = true
+		if p.Priority < 0 {
+			errs = append(errs, fmt.Errorf("processor %q priority must not be negative, got %d", p.Channel, p.Priority))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SortProcessors orders processors by priority, keeping the listed order for ties
+func SortProcessors(processors []Processor) {
+	sort.SliceStable(processors, func(i, j int) bool {
+		return processors[i].Priority < processors[j].Priority
+	})
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// QueueName identifies the main payment queue or its dead-letter queue
+type QueueName string
+
+// Queues a payment can wait in
+const (
+	MainQueue       QueueName = "main"
+	DeadLetterQueue QueueName = "dead"
+)
+
+// ParseQueueName validates a queue name
+func ParseQueueName(value string) (QueueName, error) {
+	switch q := QueueName(value); q {
+	case MainQueue, DeadLetterQueue:
+		return q, nil
+	default:
+		return "", fmt.Errorf("unknown queue %q, expected main or dead", value)
+	}
+}
+
+// QueuedPayment is a payment waiting in a queue
+type QueuedPayment struct {
+	Payment
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+}
+
+// QueueStats describes the payments waiting in a queue
+type QueueStats struct {
+	Queue QueueName
+	Depth int
+	// OldestEnqueuedAt is nil when the queue is empty or the oldest payment
+	// was queued without a timestamp
+	OldestEnqueuedAt *time.Time
+}
+
+// OldestAge returns how long the oldest payment has been waiting
+func (s QueueStats) OldestAge(now time.Time) time.Duration {
+	if s.OldestEnqueuedAt == nil {
+		return 0
+	}
+	return now.Sub(*s.OldestEnqueuedAt)
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimitQuota is a token bucket holding up to Burst tokens and refilled at
+// Rate tokens per second. Each request takes one token.
+type RateLimitQuota struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitDecision is the outcome of taking a token from a bucket
+type RateLimitDecision struct {
+	Allowed   bool
+	Limit     int           // the bucket size
+	Remaining int           // whole tokens left
+	Reset     time.Duration // until the bucket is full again
+	// RetryAfter is how long to wait for the next token, zero when allowed
+	RetryAfter time.Duration
+}
+
+// Decide builds the decision for a bucket left with tokens after the request
+func (q RateLimitQuota) Decide(allowed bool, tokens float64) RateLimitDecision {
+	decision := RateLimitDecision{
+		Allowed:   allowed,
+		Limit:     q.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     q.refill(float64(q.Burst) - tokens),
+	}
+	if !allowed {
+		decision.RetryAfter = q.refill(1 - tokens)
+	}
+	return decision
+}
+
+// refill returns how long the bucket takes to gain tokens
+func (q RateLimitQuota) refill(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / q.Rate * float64(time.Second))
+}
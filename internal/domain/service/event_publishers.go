@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/port"
+)
+
+// PaymentEventPublishers publishes every event to each of its publishers
+type PaymentEventPublishers []port.PaymentEventPublisher
+
+// Publish publishes the event to every publisher, even when one fails
+func (p PaymentEventPublishers) Publish(ctx context.Context, event domain.PaymentEvent) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
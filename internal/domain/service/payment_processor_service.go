@@ -2,66 +2,77 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
 	"github.com/lmtani/rinha-de-backend-2025/internal/port"
 )
 
+// RoutedProcessor is a payment processor the service can route payments to
+type RoutedProcessor struct {
+	Channel        domain.ProcessorChannel
+	Processor      port.PaymentProcessor
+	CircuitBreaker port.CircuitBreaker // nil calls the processor directly
+}
+
 // PaymentProcessorService handles the business logic for payment processing
 type PaymentProcessorService struct {
-	defaultProcessor  port.PaymentProcessor
-	fallbackProcessor port.PaymentProcessor
-	circuitBreaker    port.CircuitBreaker
-	repository        port.PaymentRepository
+	processors []RoutedProcessor
+	repository port.PaymentRepository
+	events     port.PaymentEventPublisher
 }
 
-// NewPaymentProcessorService creates a new payment processor service
+// NewPaymentProcessorService creates a new payment processor service. The
+// processors are tried in the given order. events is told about completed
+// payments and may be nil.
 func NewPaymentProcessorService(
-	defaultProcessor, fallbackProcessor port.PaymentProcessor,
-	circuitBreaker port.CircuitBreaker,
+	processors []RoutedProcessor,
 	repository port.PaymentRepository,
+	events port.PaymentEventPublisher,
 ) *PaymentProcessorService {
 	return &PaymentProcessorService{
-		defaultProcessor:  defaultProcessor,
-		fallbackProcessor: fallbackProcessor,
-		circuitBreaker:    circuitBreaker,
-		repository:        repository,
+		processors: processors,
+		repository: repository,
+		events:     events,
 	}
 }
 
-// ProcessPayment processes a payment using the default processor with fallback
+// ProcessPayment sends the payment to the first processor that accepts it
 func (s *PaymentProcessorService) ProcessPayment(ctx context.Context, payment domain.Payment) error {
 	if err := payment.Validate(); err != nil {
 		return fmt.Errorf("invalid payment: %w", err)
 	}
 
-	amount, _ := payment.AmountAsFloat() // Already validated above
+	var errs []error
+	for _, p := range s.processors {
+		var err error
+		if p.CircuitBreaker != nil {
+			err = p.CircuitBreaker.Execute(func() error {
+				return p.Processor.ProcessPayment(ctx, payment)
+			})
+		} else {
+			err = p.Processor.ProcessPayment(ctx, payment)
+		}
 
-	// Try default processor with circuit breaker
-	err := s.circuitBreaker.Execute(func() error {
-		return s.defaultProcessor.ProcessPayment(ctx, payment)
-	})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Channel, err))
+			continue
+		}
 
-	if err == nil {
-		// Success with default processor
-		if err := s.repository.Add(payment.CorrelationId, domain.DefaultProcessor, amount); err != nil {
+		if err := s.repository.Add(payment, p.Channel); err != nil {
 			// Log error but don't fail the payment
 			fmt.Printf("Failed to record payment stats: %v\n", err)
 		}
+		if s.events != nil {
+			if err := s.events.Publish(ctx, domain.NewPaymentCompletedEvent(payment, p.Channel, time.Now())); err != nil {
+				// The payment went through, only its notification is lost
+				fmt.Printf("Failed to publish payment %s: %v\n", payment.CorrelationId, err)
+			}
+		}
 		return nil
 	}
 
-	// Try fallback processor
-	if err := s.fallbackProcessor.ProcessPayment(ctx, payment); err != nil {
-		return fmt.Errorf("both default and fallback processors failed: %w", err)
-	}
-
-	// Success with fallback processor
-	if err := s.repository.Add(payment.CorrelationId, domain.FallbackProcessor, amount); err != nil {
-		// Log error but don't fail the payment
-		fmt.Printf("Failed to record payment stats: %v\n", err)
-	}
-
-	return nil
+	return fmt.Errorf("all processors failed: %w", errors.Join(errs...))
 }
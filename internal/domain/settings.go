@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CircuitBreakerSettings holds the circuit breaker thresholds
+type CircuitBreakerSettings struct {
+	MaxRequests  uint32        `json:"maxRequests"`
+	Interval     time.Duration `json:"interval"`
+	Timeout      time.Duration `json:"timeout"`
+	FailureRatio float64       `json:"failureRatio"`
+	MinRequests  uint32        `json:"minRequests"`
+}
+
+// ResilienceSettings holds the settings that can be tuned at runtime
+type ResilienceSettings struct {
+	CircuitBreaker    CircuitBreakerSettings `json:"circuitBreaker"`
+	ProcessorTimeout  time.Duration          `json:"processorTimeout"`
+	WorkerConcurrency int                    `json:"workerConcurrency"`
+}
+
+// Validate validates the resilience settings
+func (s ResilienceSettings) Validate() error {
+	var errs []error
+
+	if s.ProcessorTimeout < time.Millisecond {
+		errs = append(errs, fmt.Errorf("processor timeout must be at least 1ms, got %s", s.ProcessorTimeout))
+	}
+	if s.WorkerConcurrency < 1 || s.WorkerConcurrency > 1024 {
+		errs = append(errs, fmt.Errorf("worker concurrency must be between 1 and 1024, got %d", s.WorkerConcurrency))
+	}
+
+	cb := s.CircuitBreaker
+	if cb.Interval != 0 && cb.Interval < time.Millisecond {
+		errs = append(errs, fmt.Errorf("circuit breaker interval must be 0 or at least 1ms, got %s", cb.Interval))
+	}
+	if cb.Timeout < time.Millisecond {
+		errs = append(errs, fmt.Errorf("circuit breaker timeout must be at least 1ms, got %s", cb.Timeout))
+	}
+	if cb.FailureRatio <= 0 || cb.FailureRatio > 1 {
+		errs = append(errs, fmt.Errorf("circuit breaker failure ratio must be in (0, 1], got %g", cb.FailureRatio))
+	}
+	if cb.MinRequests < 1 {
+		errs = append(errs, errors.New("circuit breaker min requests must be at least 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// SettingsUpdate is a partial update of the resilience settings. Nil fields are left unchanged.
+type SettingsUpdate struct {
+	CircuitBreakerMaxRequests  *uint32
+	CircuitBreakerInterval     *time.Duration
+	CircuitBreakerTimeout      *time.Duration
+	CircuitBreakerFailureRatio *float64
+	CircuitBreakerMinRequests  *uint32
+	ProcessorTimeout           *time.Duration
+	WorkerConcurrency          *int
+}
+
+// ApplyTo returns the settings with the update applied
+func (u SettingsUpdate) ApplyTo(s ResilienceSettings) ResilienceSettings {
+	if u.CircuitBreakerMaxRequests != nil {
+		s.CircuitBreaker.MaxRequests = *u.CircuitBreakerMaxRequests
+	}
+	if u.CircuitBreakerInterval != nil {
+		s.CircuitBreaker.Interval = *u.CircuitBreakerInterval
+	}
+	if u.CircuitBreakerTimeout != nil {
+		s.CircuitBreaker.Timeout = *u.CircuitBreakerTimeout
+	}
+	if u.CircuitBreakerFailureRatio != nil {
+		s.CircuitBreaker.FailureRatio = *u.CircuitBreakerFailureRatio
+	}
+	if u.CircuitBreakerMinRequests != nil {
+		s.CircuitBreaker.MinRequests = *u.CircuitBreakerMinRequests
+	}
+	if u.ProcessorTimeout != nil {
+		s.ProcessorTimeout = *u.ProcessorTimeout
+	}
+	if u.WorkerConcurrency != nil {
+		s.WorkerConcurrency = *u.WorkerConcurrency
+	}
+	return s
+}
+
+// SettingsChange records who changed the resilience settings, where and when.
+// Version orders the changes, a newer change has a higher version.
+type SettingsChange struct {
+	Version   int64              `json:"version"`
+	ChangedBy string             `json:"changedBy"`
+	ChangedAt time.Time          `json:"changedAt"`
+	Instance  string             `json:"instance"`
+	Fields    []string           `json:"fields"`
+	Before    ResilienceSettings `json:"before"`
+	After     ResilienceSettings `json:"after"`
+}
+
+// ChangedFields lists the names of the settings that differ between before and after
+func ChangedFields(before, after ResilienceSettings) []string {
+	var fields []string
+	add := func(changed bool, name string) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+
+	add(before.CircuitBreaker.MaxRequests != after.CircuitBreaker.MaxRequests, "circuitBreaker.maxRequests")
+	add(before.CircuitBreaker.Interval != after.CircuitBreaker.Interval, "circuitBreaker.interval")
+	add(before.CircuitBreaker.Timeout != after.CircuitBreaker.Timeout, "circuitBreaker.timeout")
+	add(before.CircuitBreaker.FailureRatio != after.CircuitBreaker.FailureRatio, "circuitBreaker.failureRatio")
+	add(before.CircuitBreaker.MinRequests != after.CircuitBreaker.MinRequests, "circuitBreaker.minRequests")
+	add(before.ProcessorTimeout != after.ProcessorTimeout, "processorTimeout")
+	add(before.WorkerConcurrency != after.WorkerConcurrency, "workerConcurrency")
+
+	return fields
+}
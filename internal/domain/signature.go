@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SigningMode tells whether requests must be signed
+type SigningMode string
+
+// Supported signing modes
+const (
+	SigningOff      SigningMode = "off"
+	SigningOptional SigningMode = "optional" // signatures are checked when present
+	SigningRequired SigningMode = "required"
+)
+
+// RequestSignature is a parsed "t=<unix seconds>,v1=<hex>" signature header
+type RequestSignature struct {
+	Timestamp time.Time
+	MAC       []byte
+}
+
+// ParseRequestSignature parses a signature header
+func ParseRequestSignature(header string) (RequestSignature, error) {
+	var signature RequestSignature
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "t":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return RequestSignature{}, fmt.Errorf("invalid signature timestamp %q", value)
+			}
+			signature.Timestamp = time.Unix(seconds, 0)
+		case "v1":
+			mac, err := hex.DecodeString(value)
+			if err != nil {
+				return RequestSignature{}, errors.New("invalid signature, expected hex")
+			}
+			signature.MAC = mac
+		}
+	}
+
+	if signature.Timestamp.IsZero() || len(signature.MAC) == 0 {
+		return RequestSignature{}, errors.New("signature must be t=<unix seconds>,v1=<hex HMAC-SHA256>")
+	}
+	return signature, nil
+}
+
+// SignRequest returns the HMAC-SHA256, keyed with the API key secret, of
+// "<unix seconds>.<method> <request URI>.<body>"
+func SignRequest(secret string, timestamp time.Time, method, requestURI string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s %s.", timestamp.Unix(), method, requestURI)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// FormatRequestSignature returns the signature header of a request
+func FormatRequestSignature(secret string, timestamp time.Time, method, requestURI string, body []byte) string {
+	mac := SignRequest(secret, timestamp, method, requestURI, body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp.Unix(), hex.EncodeToString(mac))
+}
+
+// Verify reports whether the signature matches the request
+func (s RequestSignature) Verify(secret, method, requestURI string, body []byte) bool {
+	return hmac.Equal(s.MAC, SignRequest(secret, s.Timestamp, method, requestURI, body))
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxTimeseriesPoints bounds how many buckets a single timeseries query may return
+const MaxTimeseriesPoints = 3600
+
+// TimeseriesBucket is the width of each timeseries bucket
+type TimeseriesBucket string
+
+// Supported bucket widths
+const (
+	BucketSecond TimeseriesBucket = "1s"
+	BucketMinute TimeseriesBucket = "1m"
+	BucketHour   TimeseriesBucket = "1h"
+)
+
+// ParseTimeseriesBucket validates a bucket width
+func ParseTimeseriesBucket(value string) (TimeseriesBucket, error) {
+	switch b := TimeseriesBucket(value); b {
+	case BucketSecond, BucketMinute, BucketHour:
+		return b, nil
+	default:
+		return "", fmt.Errorf("invalid bucket %q, expected 1s, 1m or 1h", value)
+	}
+}
+
+// Duration returns the width of the bucket
+func (b TimeseriesBucket) Duration() time.Duration {
+	switch b {
+	case BucketMinute:
+		return time.Minute
+	case BucketHour:
+		return time.Hour
+	default:
+		return time.Second
+	}
+}
+
+// Unit returns the bucket width as a date_trunc field name
+func (b TimeseriesBucket) Unit() string {
+	switch b {
+	case BucketMinute:
+		return "minute"
+	case BucketHour:
+		return "hour"
+	default:
+		return "second"
+	}
+}
+
+// Truncate returns the start of the bucket holding t, in UTC
+func (b TimeseriesBucket) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(b.Duration())
+}
+
+// WholeSecondBounds returns the first and one past the last whole second
+// inside [from, to], the part of a range that per-second rollups can answer.
+// Timestamps are compared at microsecond precision, like Postgres keeps them.
+// A nil bound stays unbounded, and when both are set and lo is not before hi
+// no whole second fits in the range.
+func WholeSecondBounds(from, to *time.Time) (lo, hi *time.Time) {
+	if from != nil {
+		start := from.UTC().Truncate(time.Microsecond)
+		if truncated := start.Truncate(time.Second); truncated.Before(start) {
+			start = truncated.Add(time.Second)
+		}
+		lo = &start
+	}
+	if to != nil {
+		// A second is whole when its last microsecond is not after to
+		end := to.UTC().Truncate(time.Microsecond).Add(time.Microsecond).Truncate(time.Second)
+		hi = &end
+	}
+	return lo, hi
+}
+
+// Starts returns the start of every bucket between from and to, inclusive
+func (b TimeseriesBucket) Starts(from, to time.Time) []time.Time {
+	var starts []time.Time
+	for start := b.Truncate(from); !start.After(to); start = start.Add(b.Duration()) {
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// ValidateTimeseriesRange checks that a range is ordered and not too wide for the bucket
+func ValidateTimeseriesRange(from, to time.Time, bucket TimeseriesBucket) error {
+	if to.Before(from) {
+		return errors.New("'to' must not be before 'from'")
+	}
+	points := int64(bucket.Truncate(to).Sub(bucket.Truncate(from))/bucket.Duration()) + 1
+	if points > MaxTimeseriesPoints {
+		return fmt.Errorf("range spans %d buckets of %s, at most %d are allowed", points, bucket, MaxTimeseriesPoints)
+	}
+	return nil
+}
+
+// SummaryPoint is the payments summary of one timeseries bucket
+type SummaryPoint struct {
+	Start   time.Time       `json:"start"`
+	Summary PaymentsSummary `json:"summary"`
+}
+
+// Timeseries is a payments summary split into consecutive buckets
+type Timeseries struct {
+	From   time.Time        `json:"from"`
+	To     time.Time        `json:"to"`
+	Bucket TimeseriesBucket `json:"bucket"`
+	Points []SummaryPoint   `json:"points"`
+}
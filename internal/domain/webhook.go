@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WebhookStatus is the state of a webhook delivery
+type WebhookStatus string
+
+// Webhook delivery states
+const (
+	WebhookPending   WebhookStatus = "pending"   // waiting for its next attempt
+	WebhookDelivered WebhookStatus = "delivered" // the receiver answered 2xx
+	WebhookFailed    WebhookStatus = "failed"    // every attempt failed
+)
+
+// WebhookDelivery is a payment event posted to a receiver, with the outcome
+// of its attempts so far. Its ID is the ID of the event.
+type WebhookDelivery struct {
+	ID             string        `json:"id"`
+	URL            string        `json:"url"`
+	Event          PaymentEvent  `json:"event"`
+	Status         WebhookStatus `json:"status"`
+	Attempts       int           `json:"attempts"`
+	LastStatusCode int           `json:"lastStatusCode,omitempty"`
+	LastError      string        `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time    `json:"nextAttemptAt,omitempty"` // set while pending
+	// Restricted deliveries go to a URL chosen by the caller, which may only
+	// resolve to public addresses
+	Restricted bool      `json:"restricted,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// NewWebhookDelivery creates a delivery of event to url, due at once
+func NewWebhookDelivery(event PaymentEvent, url string, now time.Time) WebhookDelivery {
+	now = now.UTC()
+	return WebhookDelivery{
+		ID:            event.ID,
+		URL:           url,
+		Event:         event,
+		Status:        WebhookPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// CallbackPolicy maps a client name to the hosts its payments may name in
+// callbackUrl. Anonymous payments may not name one.
+type CallbackPolicy map[string][]string
+
+// Check returns an error unless client may have its webhooks posted to rawURL
+func (p CallbackPolicy) Check(client, rawURL string) error {
+	if client == "" {
+		return errors.New("callback URLs require an authenticated client")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.New("callback URL must be an absolute http or https URL")
+	}
+	host := strings.ToLower(u.Hostname())
+	if !slices.ContainsFunc(p[client], func(allowed string) bool { return strings.EqualFold(allowed, host) }) {
+		return fmt.Errorf("callback host %q is not allowed for client %q", host, client)
+	}
+	return nil
+}
+
+// WebhookRetryPolicy decides when a failed delivery is attempted again
+type WebhookRetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration // delay after the first failure, doubled after each one
+	MaxBackoff  time.Duration
+}
+
+// Delay returns how long to wait after the given number of failed attempts
+func (p WebhookRetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+// RecordAttempt updates the delivery with the outcome of an attempt made at
+// now. err is nil when the receiver answered with statusCode.
+func (d *WebhookDelivery) RecordAttempt(policy WebhookRetryPolicy, statusCode int, err error, now time.Time) {
+	now = now.UTC()
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.UpdatedAt = now
+
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		d.Status, d.LastError, d.NextAttemptAt = WebhookDelivered, "", nil
+		return
+	case err != nil:
+		d.LastError = err.Error()
+	default:
+		d.LastError = fmt.Sprintf("receiver answered %d", statusCode)
+	}
+
+	if d.Attempts >= policy.MaxAttempts {
+		d.Status, d.NextAttemptAt = WebhookFailed, nil
+		return
+	}
+	next := now.Add(policy.Delay(d.Attempts))
+	d.Status, d.NextAttemptAt = WebhookPending, &next
+}
+
+// Redeliver schedules the delivery again at now with a fresh attempt budget
+func (d *WebhookDelivery) Redeliver(now time.Time) {
+	now = now.UTC()
+	d.Status = WebhookPending
+	d.Attempts = 0
+	d.NextAttemptAt = &now
+	d.UpdatedAt = now
+}
+
+// SignWebhook returns the HMAC-SHA256, keyed with the webhook secret, of
+// "<unix seconds>.<body>"
+func SignWebhook(secret string, timestamp time.Time, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp.Unix())
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// FormatWebhookSignature returns the signature header of a webhook, in the
+// "t=<unix seconds>,v1=<hex>" format of request signatures
+func FormatWebhookSignature(secret string, timestamp time.Time, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp.Unix(), hex.EncodeToString(SignWebhook(secret, timestamp, body)))
+}
@@ -0,0 +1,447 @@
+package loadgen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// tickInterval is how often the scheduler releases due arrivals
+const tickInterval = 10 * time.Millisecond
+
+// Config holds the settings of a load generation run
+type Config struct {
+	TargetURL    string
+	APIKey       string // sent as X-API-Key when set
+	Stages       []Stage
+	MaxInFlight  int
+	Timeout      time.Duration
+	PollInterval time.Duration // 0 disables summary polling during the run
+	SettleTime   time.Duration // how long to wait for the final summary to match
+	Amount       float64
+	Replay       []domain.Payment // when set, amounts are taken from these payments in order
+	Events       []ProcessorEvent
+	Processors   map[string]Processor
+}
+
+// Processor is a payment processor whose behaviour events can change, either
+// directly or through the admin API
+type Processor interface {
+	SetDelay(ctx context.Context, delay int) error
+	SetFailure(ctx context.Context, failure bool) error
+}
+
+// Runner drives a load generation run against the payments API
+type Runner struct {
+	cfg        Config
+	httpClient *http.Client
+
+	payments *latencyRecorder
+	polls    *latencyRecorder
+
+	sent         atomic.Int64
+	skipped      atomic.Int64
+	pollFailures atomic.Int64
+
+	mu             sync.Mutex
+	acceptedCount  int
+	acceptedAmount float64
+	eventFailures  []string
+}
+
+// NewRunner creates a new load generation runner
+func NewRunner(cfg Config) *Runner {
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = 256
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.Amount <= 0 {
+		cfg.Amount = 19.90
+	}
+	cfg.TargetURL = strings.TrimRight(cfg.TargetURL, "/")
+
+	return &Runner{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				MaxIdleConns:        cfg.MaxInFlight,
+				MaxIdleConnsPerHost: cfg.MaxInFlight,
+				IdleConnTimeout:     30 * time.Second,
+			},
+		},
+		payments: newLatencyRecorder(),
+		polls:    newLatencyRecorder(),
+	}
+}
+
+// Run executes every stage, then waits for the API summary to settle and
+// returns the collected report
+func (r *Runner) Run(ctx context.Context) (Report, error) {
+	if len(r.cfg.Stages) == 0 {
+		return Report{}, fmt.Errorf("no stages configured")
+	}
+
+	start := time.Now().UTC()
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var background sync.WaitGroup
+	if r.cfg.PollInterval > 0 {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			r.pollSummary(runCtx, start)
+		}()
+	}
+
+	for _, event := range r.cfg.Events {
+		background.Add(1)
+		go func(event ProcessorEvent) {
+			defer background.Done()
+			r.fireEvent(runCtx, start, event)
+		}(event)
+	}
+
+	inFlight := r.generate(ctx, start)
+	inFlight.Wait()
+	duration := time.Since(start)
+
+	cancel()
+	background.Wait()
+
+	check := r.checkSummary(ctx, start)
+
+	statuses, transportErrors := r.payments.counts()
+	report := Report{
+		Duration:     duration,
+		Sent:         int(r.sent.Load()),
+		Skipped:      int(r.skipped.Load()),
+		Errors:       transportErrors,
+		StatusCounts: statuses,
+		Payments:     r.payments.stats(),
+		SummaryPolls: r.polls.stats(),
+		PollFailures: int(r.pollFailures.Load()),
+		SummaryCheck: check,
+	}
+	for status, count := range statuses {
+		switch {
+		case status >= 200 && status < 300:
+			report.Accepted += count
+		case status >= 400 && status < 500:
+			report.Rejected += count
+		case status >= 500:
+			report.ServerErrors += count
+		}
+	}
+
+	r.mu.Lock()
+	report.EventFailures = append([]string(nil), r.eventFailures...)
+	r.mu.Unlock()
+
+	return report, nil
+}
+
+// generate schedules arrivals according to the stages. It returns a wait
+// group tracking the requests still in flight.
+func (r *Runner) generate(ctx context.Context, start time.Time) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, r.cfg.MaxInFlight)
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	var due float64
+	last := start
+	seq := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return &wg
+		case now := <-ticker.C:
+			rate, ok := RateAt(r.cfg.Stages, now.Sub(start))
+			if !ok {
+				return &wg
+			}
+
+			due += rate * now.Sub(last).Seconds()
+			last = now
+
+			for ; due >= 1; due-- {
+				select {
+				case slots <- struct{}{}:
+				default:
+					r.skipped.Add(1)
+					continue
+				}
+
+				payment := r.nextPayment(seq)
+				seq++
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-slots }()
+					r.sendPayment(ctx, payment)
+				}()
+			}
+		}
+	}
+}
+
+// nextPayment builds the payment for the given sequence number with a fresh correlation ID
+func (r *Runner) nextPayment(seq int) domain.Payment {
+	amount := r.cfg.Amount
+	if len(r.cfg.Replay) > 0 {
+		amount = r.cfg.Replay[seq%len(r.cfg.Replay)].Amount
+	}
+	return domain.Payment{
+		CorrelationId: newUUID(),
+		Amount:        amount,
+	}
+}
+
+func (r *Runner) sendPayment(ctx context.Context, payment domain.Payment) {
+	body, err := json.Marshal(payment)
+	if err != nil {
+		r.payments.record(0, 0)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.TargetURL+"/payments", bytes.NewReader(body))
+	if err != nil {
+		r.payments.record(0, 0)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", r.cfg.APIKey)
+	}
+
+	r.sent.Add(1)
+	began := time.Now()
+	resp, err := r.httpClient.Do(req)
+	latency := time.Since(began)
+	if err != nil {
+		r.payments.record(latency, 0)
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	r.payments.record(latency, resp.StatusCode)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		r.mu.Lock()
+		r.acceptedCount++
+		r.acceptedAmount += payment.Amount
+		r.mu.Unlock()
+	}
+}
+
+// pollSummary queries the summary endpoint on every poll interval until ctx is done
+func (r *Runner) pollSummary(ctx context.Context, start time.Time) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			began := time.Now()
+			_, err := r.fetchSummary(ctx, start, time.Now().UTC())
+			latency := time.Since(began)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				r.pollFailures.Add(1)
+				r.polls.record(latency, 0)
+				continue
+			}
+			r.polls.record(latency, http.StatusOK)
+		}
+	}
+}
+
+// fireEvent waits until the event's offset and then applies it to the processor
+func (r *Runner) fireEvent(ctx context.Context, start time.Time, event ProcessorEvent) {
+	timer := time.NewTimer(time.Until(start.Add(event.At)))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	processor, ok := r.cfg.Processors[event.Processor]
+	if !ok {
+		r.recordEventFailure(event, fmt.Errorf("processor %q is not configured", event.Processor))
+		return
+	}
+
+	var err error
+	switch {
+	case event.Delay != nil:
+		err = processor.SetDelay(ctx, *event.Delay)
+	case event.Failure != nil:
+		err = processor.SetFailure(ctx, *event.Failure)
+	}
+	if err != nil {
+		r.recordEventFailure(event, err)
+		return
+	}
+
+	fmt.Printf("[loadgen] t+%s applied %s\n", event.At, describeEvent(event))
+}
+
+func (r *Runner) recordEventFailure(event ProcessorEvent, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.eventFailures = append(r.eventFailures, fmt.Sprintf("t+%s %s: %v", event.At, describeEvent(event), err))
+}
+
+// checkSummary polls the final summary until it accounts for every accepted
+// payment or the settle time runs out
+func (r *Runner) checkSummary(ctx context.Context, start time.Time) SummaryCheck {
+	r.mu.Lock()
+	check := SummaryCheck{
+		AcceptedRequests: r.acceptedCount,
+		AcceptedAmount:   r.acceptedAmount,
+	}
+	r.mu.Unlock()
+
+	deadline := time.Now().Add(r.cfg.SettleTime)
+	for {
+		summary, err := r.fetchSummary(ctx, start, time.Now().UTC())
+		check.Err = err
+		if err == nil {
+			check.ReportedRequests, check.ReportedAmount = 0, 0
+			for _, stats := range summary.Channels() {
+				check.ReportedRequests += stats.TotalRequests
+				check.ReportedAmount += stats.TotalAmount
+			}
+			if check.Matches() {
+				return check
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return check
+		}
+
+		select {
+		case <-ctx.Done():
+			return check
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+func (r *Runner) fetchSummary(ctx context.Context, from, to time.Time) (domain.PaymentsSummary, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.cfg.TargetURL+"/payments-summary", nil)
+	if err != nil {
+		return domain.PaymentsSummary{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("from", from.Format(time.RFC3339Nano))
+	q.Add("to", to.Format(time.RFC3339Nano))
+	req.URL.RawQuery = q.Encode()
+	if r.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", r.cfg.APIKey)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return domain.PaymentsSummary{}, fmt.Errorf("failed to fetch summary: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return domain.PaymentsSummary{}, fmt.Errorf("summary returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var summary domain.PaymentsSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return domain.PaymentsSummary{}, fmt.Errorf("failed to decode summary: %w", err)
+	}
+	return summary, nil
+}
+
+// LoadReplayFile reads payments from a newline delimited JSON file
+func LoadReplayFile(path string) ([]domain.Payment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+	defer file.Close()
+
+	var payments []domain.Payment
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var payment domain.Payment
+		if err := json.Unmarshal([]byte(text), &payment); err != nil {
+			return nil, fmt.Errorf("invalid payment on line %d: %w", line, err)
+		}
+		if _, err := payment.AmountAsFloat(); err != nil {
+			return nil, fmt.Errorf("invalid payment on line %d: %w", line, err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read replay file: %w", err)
+	}
+
+	if len(payments) == 0 {
+		return nil, fmt.Errorf("replay file %s has no payments", path)
+	}
+	return payments, nil
+}
+
+func describeEvent(event ProcessorEvent) string {
+	switch {
+	case event.Delay != nil:
+		return fmt.Sprintf("%s delay=%dms", event.Processor, *event.Delay)
+	case event.Failure != nil:
+		return fmt.Sprintf("%s failure=%t", event.Processor, *event.Failure)
+	default:
+		return event.Processor
+	}
+}
+
+// newUUID returns a random (version 4) UUID string
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("failed to generate uuid: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
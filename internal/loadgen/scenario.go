@@ -0,0 +1,127 @@
+package loadgen
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stage describes one ramp step of the arrival rate. The rate moves linearly
+// from the previous stage's target to Target over Duration.
+type Stage struct {
+	Duration time.Duration
+	Target   float64 // requests per second at the end of the stage
+}
+
+// ProcessorEvent toggles a processor's admin delay or failure mode at a
+// given offset from the start of the run.
+type ProcessorEvent struct {
+	At        time.Duration
+	Processor string // name of a configured processor
+	Delay     *int   // milliseconds, nil when not changed
+	Failure   *bool  // nil when not changed
+}
+
+// ParseStages parses a comma separated list of "duration:rate" pairs,
+// e.g. "10s:50,30s:500,10s:0".
+func ParseStages(spec string) ([]Stage, error) {
+	var stages []Stage
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		durStr, rateStr, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid stage %q, expected duration:rate", part)
+		}
+
+		duration, err := time.ParseDuration(durStr)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid stage duration %q", durStr)
+		}
+
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid stage rate %q", rateStr)
+		}
+
+		stages = append(stages, Stage{Duration: duration, Target: rate})
+	}
+
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("at least one stage is required")
+	}
+	return stages, nil
+}
+
+// ParseEvent parses a processor event of the form "offset:processor:key=value",
+// e.g. "10s:default:failure=true" or "15s:fallback:delay=800". The processor
+// must be one of processors.
+func ParseEvent(spec string, processors []string) (ProcessorEvent, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) != 3 {
+		return ProcessorEvent{}, fmt.Errorf("invalid event %q, expected offset:processor:key=value", spec)
+	}
+
+	at, err := time.ParseDuration(parts[0])
+	if err != nil || at < 0 {
+		return ProcessorEvent{}, fmt.Errorf("invalid event offset %q", parts[0])
+	}
+
+	event := ProcessorEvent{At: at, Processor: parts[1]}
+	if !slices.Contains(processors, event.Processor) {
+		return ProcessorEvent{}, fmt.Errorf("unknown event processor %q, expected one of %s", parts[1], strings.Join(processors, ", "))
+	}
+
+	key, value, ok := strings.Cut(parts[2], "=")
+	if !ok {
+		return ProcessorEvent{}, fmt.Errorf("invalid event action %q, expected key=value", parts[2])
+	}
+
+	switch key {
+	case "delay":
+		delay, err := strconv.Atoi(value)
+		if err != nil || delay < 0 {
+			return ProcessorEvent{}, fmt.Errorf("invalid delay %q", value)
+		}
+		event.Delay = &delay
+	case "failure":
+		failure, err := strconv.ParseBool(value)
+		if err != nil {
+			return ProcessorEvent{}, fmt.Errorf("invalid failure %q", value)
+		}
+		event.Failure = &failure
+	default:
+		return ProcessorEvent{}, fmt.Errorf("unknown event action %q", key)
+	}
+
+	return event, nil
+}
+
+// TotalDuration returns the sum of all stage durations
+func TotalDuration(stages []Stage) time.Duration {
+	var total time.Duration
+	for _, s := range stages {
+		total += s.Duration
+	}
+	return total
+}
+
+// RateAt returns the target arrival rate at the given offset into the run.
+// It returns false once every stage has elapsed.
+func RateAt(stages []Stage, elapsed time.Duration) (float64, bool) {
+	var previous float64
+	for _, s := range stages {
+		if elapsed < s.Duration {
+			progress := float64(elapsed) / float64(s.Duration)
+			return previous + (s.Target-previous)*progress, true
+		}
+		elapsed -= s.Duration
+		previous = s.Target
+	}
+	return 0, false
+}
@@ -0,0 +1,182 @@
+package loadgen
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyRecorder collects request latencies and outcome counters
+type latencyRecorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	statuses  map[int]int
+	errors    int
+}
+
+func newLatencyRecorder() *latencyRecorder {
+	return &latencyRecorder{
+		latencies: make([]time.Duration, 0, 4096),
+		statuses:  make(map[int]int),
+	}
+}
+
+// record stores the outcome of one request. A status of 0 means a transport error.
+func (r *latencyRecorder) record(latency time.Duration, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latencies = append(r.latencies, latency)
+	if status == 0 {
+		r.errors++
+		return
+	}
+	r.statuses[status]++
+}
+
+// LatencyStats summarizes a latency distribution
+type LatencyStats struct {
+	Count int
+	Min   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func (r *latencyRecorder) stats() LatencyStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return NewLatencyStats(r.latencies)
+}
+
+// NewLatencyStats summarizes the latencies, using nearest-rank percentiles.
+// The slice is not modified.
+func NewLatencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p float64) time.Duration {
+		idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return sorted[idx]
+	}
+
+	return LatencyStats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+func (r *latencyRecorder) counts() (map[int]int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make(map[int]int, len(r.statuses))
+	for k, v := range r.statuses {
+		statuses[k] = v
+	}
+	return statuses, r.errors
+}
+
+// SummaryCheck compares the API's final summary with what the generator saw accepted
+type SummaryCheck struct {
+	AcceptedRequests int
+	AcceptedAmount   float64
+	ReportedRequests int
+	ReportedAmount   float64
+	Err              error
+}
+
+// Matches reports whether the API summary accounts for every accepted payment
+func (c SummaryCheck) Matches() bool {
+	return c.Err == nil &&
+		c.AcceptedRequests == c.ReportedRequests &&
+		math.Abs(c.AcceptedAmount-c.ReportedAmount) < 0.005
+}
+
+// Report holds the results of a load generation run
+type Report struct {
+	Duration      time.Duration
+	Sent          int
+	Skipped       int // arrivals dropped because max in-flight requests was reached
+	Accepted      int
+	Rejected      int // 4xx responses
+	ServerErrors  int // 5xx responses
+	Errors        int // transport errors
+	StatusCounts  map[int]int
+	Payments      LatencyStats
+	SummaryPolls  LatencyStats
+	PollFailures  int
+	SummaryCheck  SummaryCheck
+	EventFailures []string
+}
+
+// Print writes a human readable version of the report
+func (r Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "Duration:       %s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "Sent:           %d (%.1f req/s)\n", r.Sent, float64(r.Sent)/r.Duration.Seconds())
+	fmt.Fprintf(w, "Skipped:        %d\n", r.Skipped)
+	fmt.Fprintf(w, "Accepted:       %d\n", r.Accepted)
+	fmt.Fprintf(w, "Rejected (4xx): %d\n", r.Rejected)
+	fmt.Fprintf(w, "Server (5xx):   %d\n", r.ServerErrors)
+	fmt.Fprintf(w, "Errors:         %d\n", r.Errors)
+
+	codes := make([]int, 0, len(r.StatusCounts))
+	for code := range r.StatusCounts {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "  HTTP %d: %d\n", code, r.StatusCounts[code])
+	}
+
+	printLatency(w, "POST /payments", r.Payments)
+	printLatency(w, "GET /payments-summary", r.SummaryPolls)
+	if r.PollFailures > 0 {
+		fmt.Fprintf(w, "Summary poll failures: %d\n", r.PollFailures)
+	}
+
+	for _, failure := range r.EventFailures {
+		fmt.Fprintf(w, "Event failed: %s\n", failure)
+	}
+
+	check := r.SummaryCheck
+	fmt.Fprintln(w, "Final summary check:")
+	if check.Err != nil {
+		fmt.Fprintf(w, "  error: %v\n", check.Err)
+		return
+	}
+	fmt.Fprintf(w, "  accepted: %d requests, %.2f amount\n", check.AcceptedRequests, check.AcceptedAmount)
+	fmt.Fprintf(w, "  reported: %d requests, %.2f amount\n", check.ReportedRequests, check.ReportedAmount)
+	if check.Matches() {
+		fmt.Fprintln(w, "  result:   MATCH")
+	} else {
+		fmt.Fprintln(w, "  result:   MISMATCH")
+	}
+}
+
+func printLatency(w io.Writer, name string, s LatencyStats) {
+	if s.Count == 0 {
+		fmt.Fprintf(w, "%s: no samples\n", name)
+		return
+	}
+	fmt.Fprintf(w, "%s latency (%d samples): min=%s p50=%s p90=%s p99=%s max=%s\n",
+		name, s.Count,
+		s.Min.Round(time.Microsecond), s.P50.Round(time.Microsecond), s.P90.Round(time.Microsecond),
+		s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+}
@@ -9,11 +9,21 @@ import (
 
 // PaymentRepository defines the interface for payment statistics storage
 type PaymentRepository interface {
-	Add(correlationID string, channel domain.ProcessorChannel, amount float64) error
+	// Add records a payment accepted by the processor of channel
+	Add(payment domain.Payment, channel domain.ProcessorChannel) error
 	GetSummary() (domain.PaymentsSummary, error)
+	// Find returns the latest record of a payment, ok is false when it was
+	// never processed
+	Find(correlationID string) (record domain.PaymentRecord, ok bool, err error)
+	// Export calls fn for every record matching filter, oldest first, reading
+	// them from a cursor. It stops at the first error of fn.
+	Export(ctx context.Context, filter domain.PaymentRecordFilter, fn func(domain.PaymentRecord) error) error
 	// GetSummaryInRange returns the summary filtered by the given time range.
 	// If from or to are nil, the respective bound is ignored.
 	GetSummaryInRange(from, to *time.Time) (domain.PaymentsSummary, error)
+	// GetTimeseries returns one summary per bucket between from and to,
+	// inclusive, with empty buckets reported as zero
+	GetTimeseries(from, to time.Time, bucket domain.TimeseriesBucket) ([]domain.SummaryPoint, error)
 }
 
 // PaymentProcessor defines the interface for external payment processors
@@ -24,10 +34,31 @@ type PaymentProcessor interface {
 // PaymentQueue defines the interface for payment message queue
 type PaymentQueue interface {
 	Send(payment domain.Payment) error
+	// SendBatch queues every payment in one round trip, or none of them
+	// when it returns an error
+	SendBatch(payments []domain.Payment) error
 	Receive() <-chan domain.Payment
+	// Ack confirms a received payment was handled. Queues that do not track
+	// deliveries ignore it.
+	Ack(payment domain.Payment) error
 	Close() error
 }
 
+// QueueManager is implemented by queues whose pending payments can be
+// inspected and edited
+type QueueManager interface {
+	Stats(queue domain.QueueName) (domain.QueueStats, error)
+	// List returns up to limit payments starting at offset, oldest first
+	List(queue domain.QueueName, offset, limit int) ([]domain.QueuedPayment, error)
+	// Move moves the payments with the given correlation IDs, or every
+	// payment when none are given, and returns the moved payments
+	Move(from, to domain.QueueName, correlationIDs []string) ([]domain.QueuedPayment, error)
+	// Purge deletes every payment of a queue and returns the deleted payments
+	Purge(queue domain.QueueName) ([]domain.QueuedPayment, error)
+	// DeadLetter adds a payment that will not be retried to the dead-letter queue
+	DeadLetter(payment domain.Payment) error
+}
+
 // CircuitBreaker defines the interface for circuit breaker functionality
 type CircuitBreaker interface {
 	Execute(func() error) error
@@ -37,5 +68,94 @@ type CircuitBreaker interface {
 // Store defines the interface for UUID storage
 type Store interface {
 	Add(uuid string) error
+	// AddBatch adds the UUIDs in one round trip and reports, in order,
+	// which ones were added; the others were already present. On error none
+	// of them is added.
+	AddBatch(uuids []string) ([]bool, error)
 	Exists(uuid string) bool
+	// Remove forgets a UUID, so a payment that could not be queued can be retried
+	Remove(uuid string) error
+	// RemoveBatch forgets the UUIDs in one round trip
+	RemoveBatch(uuids []string) error
+}
+
+// Tunable is implemented by components whose resilience settings can change at runtime
+type Tunable interface {
+	ApplySettings(settings domain.ResilienceSettings)
+}
+
+// SettingsBus propagates resilience settings changes between instances
+type SettingsBus interface {
+	Publish(ctx context.Context, change domain.SettingsChange) error
+	Subscribe(ctx context.Context) (<-chan domain.SettingsChange, error)
+}
+
+// SettingsAuditLog records every resilience settings change
+type SettingsAuditLog interface {
+	// Record stores the change under the next version of the log and
+	// returns that version
+	Record(ctx context.Context, change domain.SettingsChange) (int64, error)
+	// List returns the most recent changes first, at most limit entries
+	List(ctx context.Context, limit int) ([]domain.SettingsChange, error)
+}
+
+// RateLimiter keeps token buckets, shared by every instance when backed by a
+// shared store
+type RateLimiter interface {
+	// Take takes one token from the bucket of key, creating it full
+	Take(ctx context.Context, key string, quota domain.RateLimitQuota) (domain.RateLimitDecision, error)
+}
+
+// APIKeyStore holds the API keys of the payments API clients
+type APIKeyStore interface {
+	// Get returns the key with the given ID, ok is false when there is none
+	Get(ctx context.Context, id string) (key domain.APIKey, ok bool, err error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	// Add stores a key, keeping an existing key with the same ID unchanged
+	Add(ctx context.Context, key domain.APIKey) error
+	// ExpireClient makes the keys of client stop working at the given time,
+	// unless they expire earlier, and returns how many were changed
+	ExpireClient(ctx context.Context, client string, at time.Time) (int, error)
+}
+
+// ReplayCache remembers request signatures so each is accepted only once
+type ReplayCache interface {
+	// Remember records id for ttl and reports false if it was already recorded
+	Remember(ctx context.Context, id string, ttl time.Duration) (bool, error)
+}
+
+// PaymentEventPublisher is told about the outcome of payments
+type PaymentEventPublisher interface {
+	Publish(ctx context.Context, event domain.PaymentEvent) error
+}
+
+// WebhookDeliveryLog stores webhook deliveries and schedules their attempts,
+// shared by every instance when backed by a shared store
+type WebhookDeliveryLog interface {
+	// Save creates or replaces a delivery, scheduling it while pending
+	Save(ctx context.Context, delivery domain.WebhookDelivery) error
+	// Get returns the delivery with the given ID, ok is false when there is none
+	Get(ctx context.Context, id string) (delivery domain.WebhookDelivery, ok bool, err error)
+	// List returns the most recent deliveries first, at most limit entries
+	List(ctx context.Context, limit int) ([]domain.WebhookDelivery, error)
+	// Claim returns up to limit pending deliveries due at now and postpones
+	// them by lease, so no other instance attempts them meanwhile
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+}
+
+// WebhookSender posts webhooks to their receivers
+type WebhookSender interface {
+	// Send posts the signed body of a delivery and returns the status code
+	// the receiver answered with
+	Send(ctx context.Context, delivery domain.WebhookDelivery, body []byte, signature string) (int, error)
+}
+
+// PaymentEventBus carries payment events between instances
+type PaymentEventBus interface {
+	// Publish sends an event to every subscriber; it may drop events rather
+	// than slow down payments
+	Publish(ctx context.Context, event domain.PaymentEvent) error
+	// Subscribe returns the events published by any instance, closed when
+	// ctx is done
+	Subscribe(ctx context.Context) (<-chan domain.PaymentEvent, error)
 }
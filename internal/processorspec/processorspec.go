@@ -0,0 +1,51 @@
+package processorspec
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Processor names a payment processor and its URL
+type Processor struct {
+	Name string
+	URL  string
+}
+
+// namePattern keeps names usable in URLs and element IDs
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// ValidateName checks that a name can be used for a processor
+func ValidateName(name string) error {
+	if name == "all" {
+		return errors.New(`"all" is reserved for actions on every processor`)
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid processor name %q, start with a lowercase letter and use letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// Parse parses comma separated "name=url" pairs
+func Parse(spec string) ([]Processor, error) {
+	var processors []Processor
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, url, ok := strings.Cut(pair, "=")
+		if !ok || url == "" {
+			return nil, fmt.Errorf("invalid processor %q, expected name=url", pair)
+		}
+		if err := ValidateName(name); err != nil {
+			return nil, err
+		}
+		processors = append(processors, Processor{Name: name, URL: url})
+	}
+	if len(processors) == 0 {
+		return nil, errors.New("no processors configured")
+	}
+	return processors, nil
+}
@@ -2,21 +2,38 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
 	"github.com/lmtani/rinha-de-backend-2025/internal/port"
 )
 
+// ErrInvalidRange is returned for timeseries ranges that cannot be queried
+var ErrInvalidRange = errors.New("invalid range")
+
+// ErrPaymentNotFound is returned for payments that were never requested
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// deadLetterPageSize is how many dead-lettered payments are read at a time
+// while looking for one
+const deadLetterPageSize = 100
+
 // AuditPaymentsUseCase handles payment audit operations
 type AuditPaymentsUseCase struct {
-	repository port.PaymentRepository
+	repository  port.PaymentRepository
+	store       port.Store        // correlation IDs of the requested payments
+	deadLetters port.QueueManager // nil when the queue has no dead-letter queue
 }
 
-// NewAuditPaymentsUseCase creates a new audit payments use case
-func NewAuditPaymentsUseCase(repository port.PaymentRepository) *AuditPaymentsUseCase {
+// NewAuditPaymentsUseCase creates a new audit payments use case. deadLetters
+// may be nil, in which case no payment is reported as dead-lettered.
+func NewAuditPaymentsUseCase(repository port.PaymentRepository, store port.Store, deadLetters port.QueueManager) *AuditPaymentsUseCase {
 	return &AuditPaymentsUseCase{
-		repository: repository,
+		repository:  repository,
+		store:       store,
+		deadLetters: deadLetters,
 	}
 }
 
@@ -26,3 +43,72 @@ func (uc *AuditPaymentsUseCase) Execute(ctx context.Context, from, to *time.Time
 	// Delegate to repository which handles nil bounds and UTC coercion
 	return uc.repository.GetSummaryInRange(from, to)
 }
+
+// Timeseries retrieves the payment summary split into buckets between from and to
+func (uc *AuditPaymentsUseCase) Timeseries(ctx context.Context, from, to time.Time, bucket domain.TimeseriesBucket) (domain.Timeseries, error) { //nolint:revive // ctx reserved for future use
+	if err := domain.ValidateTimeseriesRange(from, to, bucket); err != nil {
+		return domain.Timeseries{}, fmt.Errorf("%w: %w", ErrInvalidRange, err)
+	}
+
+	points, err := uc.repository.GetTimeseries(from, to, bucket)
+	if err != nil {
+		return domain.Timeseries{}, fmt.Errorf("failed to get payments timeseries: %w", err)
+	}
+
+	return domain.Timeseries{From: from.UTC(), To: to.UTC(), Bucket: bucket, Points: points}, nil
+}
+
+// Status tells whether a payment was processed, returning its record when it
+// was. Payments that were requested but not processed yet are pending, or
+// dead-lettered while they wait in the dead-letter queue.
+func (uc *AuditPaymentsUseCase) Status(ctx context.Context, correlationID string) (domain.PaymentStatus, domain.PaymentRecord, error) { //nolint:revive // ctx reserved for future use
+	record, ok, err := uc.repository.Find(correlationID)
+	if err != nil {
+		return "", domain.PaymentRecord{}, fmt.Errorf("failed to find payment: %w", err)
+	}
+	if ok {
+		return domain.PaymentProcessed, record, nil
+	}
+	if !uc.store.Exists(correlationID) {
+		return "", domain.PaymentRecord{}, fmt.Errorf("%w: %s", ErrPaymentNotFound, correlationID)
+	}
+
+	deadLettered, err := uc.isDeadLettered(correlationID)
+	if err != nil {
+		return "", domain.PaymentRecord{}, err
+	}
+	if deadLettered {
+		return domain.PaymentDeadLettered, domain.PaymentRecord{}, nil
+	}
+	return domain.PaymentPending, domain.PaymentRecord{}, nil
+}
+
+// isDeadLettered looks for a payment in the dead-letter queue, a page at a time
+func (uc *AuditPaymentsUseCase) isDeadLettered(correlationID string) (bool, error) {
+	if uc.deadLetters == nil {
+		return false, nil
+	}
+	for offset := 0; ; offset += deadLetterPageSize {
+		items, err := uc.deadLetters.List(domain.DeadLetterQueue, offset, deadLetterPageSize)
+		if err != nil {
+			return false, fmt.Errorf("failed to list the dead-letter queue: %w", err)
+		}
+		for _, item := range items {
+			if item.CorrelationId == correlationID {
+				return true, nil
+			}
+		}
+		if len(items) < deadLetterPageSize {
+			return false, nil
+		}
+	}
+}
+
+// Export calls fn for every payment recorded in the range and channel of
+// filter, oldest first, without loading them all
+func (uc *AuditPaymentsUseCase) Export(ctx context.Context, filter domain.PaymentRecordFilter, fn func(domain.PaymentRecord) error) error {
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return fmt.Errorf("%w: 'from' must not be after 'to'", ErrInvalidRange)
+	}
+	return uc.repository.Export(ctx, filter, fn)
+}
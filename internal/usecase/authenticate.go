@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/port"
+)
+
+// ErrUnauthenticated is returned when a request does not prove who sent it
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// ErrInvalidRotation is returned for key rotations that can never succeed
+var ErrInvalidRotation = errors.New("invalid key rotation")
+
+// apiKeyBytes is the amount of randomness in issued API keys
+const apiKeyBytes = 32
+
+// AuthRequest is the part of an HTTP request a signature covers
+type AuthRequest struct {
+	Method     string
+	RequestURI string
+	Body       []byte
+	Signature  string // the signature header, empty when unsigned
+}
+
+// AuthenticateUseCase authenticates payments API clients by API key and
+// optional request signature, and rotates their keys
+type AuthenticateUseCase struct {
+	keys       port.APIKeyStore // nil disables authentication
+	replay     port.ReplayCache
+	signing    domain.SigningMode
+	tolerance  time.Duration
+	instanceID string
+}
+
+// NewAuthenticateUseCase creates a new authenticate use case. Signature
+// timestamps may be tolerance away from now.
+func NewAuthenticateUseCase(
+	keys port.APIKeyStore,
+	replay port.ReplayCache,
+	signing domain.SigningMode,
+	tolerance time.Duration,
+	instanceID string,
+) *AuthenticateUseCase {
+	return &AuthenticateUseCase{
+		keys:       keys,
+		replay:     replay,
+		signing:    signing,
+		tolerance:  tolerance,
+		instanceID: instanceID,
+	}
+}
+
+// Enabled reports whether requests must be authenticated
+func (uc *AuthenticateUseCase) Enabled() bool {
+	return uc.keys != nil
+}
+
+// NeedsBody reports whether the request body must be read to authenticate
+func (uc *AuthenticateUseCase) NeedsBody(signature string) bool {
+	return uc.signing == domain.SigningRequired || (uc.signing == domain.SigningOptional && signature != "")
+}
+
+// Authenticate returns the active key matching secret, after checking the
+// request signature when one is required or given
+func (uc *AuthenticateUseCase) Authenticate(ctx context.Context, secret string, req AuthRequest) (domain.APIKey, error) {
+	if secret == "" {
+		return domain.APIKey{}, fmt.Errorf("%w: missing API key", ErrUnauthenticated)
+	}
+
+	key, ok, err := uc.keys.Get(ctx, domain.APIKeyID(secret))
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	now := time.Now()
+	if !ok || !key.ActiveAt(now) {
+		return domain.APIKey{}, fmt.Errorf("%w: invalid or expired API key", ErrUnauthenticated)
+	}
+
+	if !uc.NeedsBody(req.Signature) {
+		return key, nil
+	}
+	if req.Signature == "" {
+		return domain.APIKey{}, fmt.Errorf("%w: request signature required", ErrUnauthenticated)
+	}
+
+	signature, err := domain.ParseRequestSignature(req.Signature)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if age := now.Sub(signature.Timestamp); age > uc.tolerance || age < -uc.tolerance {
+		return domain.APIKey{}, fmt.Errorf("%w: signature timestamp is more than %s away", ErrUnauthenticated, uc.tolerance)
+	}
+	if !signature.Verify(secret, req.Method, req.RequestURI, req.Body) {
+		return domain.APIKey{}, fmt.Errorf("%w: signature mismatch", ErrUnauthenticated)
+	}
+
+	// A signature can only be replayed while its timestamp is tolerated
+	ttl := max(signature.Timestamp.Add(uc.tolerance).Sub(now), time.Second)
+	fresh, err := uc.replay.Remember(ctx, hex.EncodeToString(signature.MAC), ttl)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to check signature replay: %w", err)
+	}
+	if !fresh {
+		return domain.APIKey{}, fmt.Errorf("%w: signature already used", ErrUnauthenticated)
+	}
+
+	return key, nil
+}
+
+// Seed stores keys from the configuration, keeping any stored copy so
+// rotations done at runtime survive restarts
+func (uc *AuthenticateUseCase) Seed(ctx context.Context, keys []domain.APIKey) error {
+	for _, key := range keys {
+		if err := uc.keys.Add(ctx, key); err != nil {
+			return fmt.Errorf("failed to seed API key of %s: %w", key.Client, err)
+		}
+	}
+	return nil
+}
+
+// List returns every stored key
+func (uc *AuthenticateUseCase) List(ctx context.Context) ([]domain.APIKey, error) {
+	if uc.keys == nil {
+		return nil, fmt.Errorf("%w: authentication is disabled", ErrInvalidRotation)
+	}
+	return uc.keys.List(ctx)
+}
+
+// Rotate issues a new key for client and makes its current keys expire after
+// grace. The secret is only returned here.
+func (uc *AuthenticateUseCase) Rotate(ctx context.Context, client string, grace time.Duration, operator string) (string, domain.APIKey, error) {
+	if uc.keys == nil {
+		return "", domain.APIKey{}, fmt.Errorf("%w: authentication is disabled", ErrInvalidRotation)
+	}
+	if client == "" || grace < 0 {
+		return "", domain.APIKey{}, fmt.Errorf("%w: client is required and grace must not be negative", ErrInvalidRotation)
+	}
+
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", domain.APIKey{}, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := hex.EncodeToString(raw)
+
+	now := time.Now().UTC()
+	expired, err := uc.keys.ExpireClient(ctx, client, now.Add(grace))
+	if err != nil {
+		return "", domain.APIKey{}, err
+	}
+
+	key := domain.APIKey{ID: domain.APIKeyID(secret), Client: client, CreatedAt: now}
+	if err := uc.keys.Add(ctx, key); err != nil {
+		return "", domain.APIKey{}, err
+	}
+
+	fmt.Printf("[%s] %s rotated the API key of %s, %d previous keys expire in %s\n",
+		uc.instanceID, operator, client, expired, grace)
+	return secret, key, nil
+}
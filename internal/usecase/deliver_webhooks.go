@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/port"
+)
+
+const (
+	// webhookPollInterval is how often due deliveries are looked for when
+	// no new event wakes the dispatcher
+	webhookPollInterval = time.Second
+	// webhookBatchSize bounds how many deliveries are attempted at once
+	webhookBatchSize = 50
+	// MaxWebhookPageSize bounds how many deliveries a single List call returns
+	MaxWebhookPageSize = 500
+)
+
+// ErrWebhooksDisabled is returned when webhooks are not configured
+var ErrWebhooksDisabled = errors.New("webhooks are disabled")
+
+// ErrWebhookNotFound is returned for a delivery that is not in the log
+var ErrWebhookNotFound = errors.New("webhook delivery not found")
+
+// ErrInvalidWebhookRequest is returned for requests that can never succeed
+var ErrInvalidWebhookRequest = errors.New("invalid webhook request")
+
+// DeliverWebhooksUseCase posts signed payment events to the callback URL of
+// the payment or the URL configured for its client, retrying with backoff
+type DeliverWebhooksUseCase struct {
+	log        port.WebhookDeliveryLog // nil when webhooks are disabled
+	sender     port.WebhookSender
+	urls       map[string]string // by client name
+	secret     string
+	policy     domain.WebhookRetryPolicy
+	lease      time.Duration // how long a claimed delivery is reserved for this instance
+	instanceID string
+
+	wake chan struct{}
+}
+
+// NewDeliverWebhooksUseCase creates a new deliver webhooks use case. log may
+// be nil, which disables webhooks. timeout bounds each attempt.
+func NewDeliverWebhooksUseCase(
+	log port.WebhookDeliveryLog,
+	sender port.WebhookSender,
+	urls map[string]string,
+	secret string,
+	policy domain.WebhookRetryPolicy,
+	timeout time.Duration,
+	instanceID string,
+) *DeliverWebhooksUseCase {
+	return &DeliverWebhooksUseCase{
+		log:        log,
+		sender:     sender,
+		urls:       urls,
+		secret:     secret,
+		policy:     policy,
+		lease:      2*timeout + 5*time.Second,
+		instanceID: instanceID,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Enabled reports whether webhooks are delivered
+func (uc *DeliverWebhooksUseCase) Enabled() bool {
+	return uc.log != nil
+}
+
+// Publish schedules the delivery of a completed or failed event to the
+// payment's receiver, if it has one
+func (uc *DeliverWebhooksUseCase) Publish(ctx context.Context, event domain.PaymentEvent) error {
+	if !uc.Enabled() || (event.Type != domain.PaymentCompleted && event.Type != domain.PaymentFailed) {
+		return nil
+	}
+
+	url, restricted := event.Payment.CallbackURL, true
+	if url == "" {
+		url, restricted = uc.urls[event.Payment.Client], false
+	}
+	if url == "" {
+		return nil
+	}
+
+	delivery := domain.NewWebhookDelivery(event, url, time.Now())
+	delivery.Restricted = restricted
+	if err := uc.log.Save(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to schedule webhook: %w", err)
+	}
+	uc.signal()
+	return nil
+}
+
+// List returns the most recent deliveries
+func (uc *DeliverWebhooksUseCase) List(ctx context.Context, limit int) ([]domain.WebhookDelivery, error) {
+	if !uc.Enabled() {
+		return nil, ErrWebhooksDisabled
+	}
+	if limit < 1 || limit > MaxWebhookPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidWebhookRequest, MaxWebhookPageSize)
+	}
+	return uc.log.List(ctx, limit)
+}
+
+// Get returns a delivery
+func (uc *DeliverWebhooksUseCase) Get(ctx context.Context, id string) (domain.WebhookDelivery, error) {
+	if !uc.Enabled() {
+		return domain.WebhookDelivery{}, ErrWebhooksDisabled
+	}
+	delivery, ok, err := uc.log.Get(ctx, id)
+	if err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("failed to read webhook delivery: %w", err)
+	}
+	if !ok {
+		return domain.WebhookDelivery{}, ErrWebhookNotFound
+	}
+	return delivery, nil
+}
+
+// Redeliver schedules a delivery again at once with a fresh attempt budget,
+// whatever its state
+func (uc *DeliverWebhooksUseCase) Redeliver(ctx context.Context, id, operator string) (domain.WebhookDelivery, error) {
+	delivery, err := uc.Get(ctx, id)
+	if err != nil {
+		return delivery, err
+	}
+
+	delivery.Redeliver(time.Now())
+	if err := uc.log.Save(ctx, delivery); err != nil {
+		return delivery, fmt.Errorf("failed to schedule webhook: %w", err)
+	}
+	uc.signal()
+
+	fmt.Printf("[%s] %s scheduled webhook %s for redelivery\n", uc.instanceID, operator, id)
+	return delivery, nil
+}
+
+// Start attempts due deliveries in the background until ctx is done
+func (uc *DeliverWebhooksUseCase) Start(ctx context.Context) {
+	if !uc.Enabled() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+
+		for {
+			uc.dispatch(ctx)
+			select {
+			case <-ticker.C:
+			case <-uc.wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// signal wakes the dispatcher
+func (uc *DeliverWebhooksUseCase) signal() {
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch attempts every due delivery, a batch at a time
+func (uc *DeliverWebhooksUseCase) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := uc.log.Claim(ctx, time.Now(), uc.lease, webhookBatchSize)
+		if err != nil {
+			fmt.Printf("[%s] Failed to claim webhooks: %v\n", uc.instanceID, err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range due {
+			wg.Add(1)
+			go func(delivery domain.WebhookDelivery) {
+				defer wg.Done()
+				uc.attempt(ctx, delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(due) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// attempt posts a delivery once and records the outcome
+func (uc *DeliverWebhooksUseCase) attempt(ctx context.Context, delivery domain.WebhookDelivery) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		fmt.Printf("[%s] Failed to serialize webhook %s: %v\n", uc.instanceID, delivery.ID, err)
+		return
+	}
+
+	now := time.Now()
+	statusCode, err := uc.sender.Send(ctx, delivery, body, domain.FormatWebhookSignature(uc.secret, now, body))
+	if err != nil && ctx.Err() != nil {
+		// Shutting down; the lease expires and the attempt is made again
+		return
+	}
+	delivery.RecordAttempt(uc.policy, statusCode, err, time.Now())
+
+	switch delivery.Status {
+	case domain.WebhookDelivered:
+		fmt.Printf("[%s] Delivered webhook %s (%s) to %s\n", uc.instanceID, delivery.ID, delivery.Event.Type, delivery.URL)
+	case domain.WebhookFailed:
+		fmt.Printf("[%s] Giving up on webhook %s after %d attempts: %s\n", uc.instanceID, delivery.ID, delivery.Attempts, delivery.LastError)
+	}
+
+	// Saved even when ctx is done, so a delivered webhook is not sent twice
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := uc.log.Save(saveCtx, delivery); err != nil {
+		fmt.Printf("[%s] Failed to record webhook %s: %v\n", uc.instanceID, delivery.ID, err)
+	}
+}
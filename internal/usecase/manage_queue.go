@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/port"
+)
+
+// MaxQueuePageSize bounds how many payments a single List call returns
+const MaxQueuePageSize = 500
+
+// ErrQueueManagementUnsupported is returned when the configured queue cannot be inspected
+var ErrQueueManagementUnsupported = errors.New("the configured queue does not support inspection")
+
+// ErrInvalidQueueRequest is returned for requests that can never succeed
+var ErrInvalidQueueRequest = errors.New("invalid queue request")
+
+// ManageQueueUseCase inspects and edits the pending payments
+type ManageQueueUseCase struct {
+	manager    port.QueueManager          // nil when the queue does not support it
+	store      port.Store                 // forgets the correlation IDs of purged payments
+	events     port.PaymentEventPublisher // told about dead-lettered and purged payments, may be nil
+	instanceID string
+}
+
+// NewManageQueueUseCase creates a new manage queue use case. manager and
+// events may be nil.
+func NewManageQueueUseCase(manager port.QueueManager, store port.Store, events port.PaymentEventPublisher, instanceID string) *ManageQueueUseCase {
+	return &ManageQueueUseCase{
+		manager:    manager,
+		store:      store,
+		events:     events,
+		instanceID: instanceID,
+	}
+}
+
+// Stats returns the stats of the main and dead-letter queues
+func (uc *ManageQueueUseCase) Stats() ([]domain.QueueStats, error) {
+	if uc.manager == nil {
+		return nil, ErrQueueManagementUnsupported
+	}
+
+	var stats []domain.QueueStats
+	for _, queue := range []domain.QueueName{domain.MainQueue, domain.DeadLetterQueue} {
+		s, err := uc.manager.Stats(queue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s queue: %w", queue, err)
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// List returns a page of the payments waiting in a queue
+func (uc *ManageQueueUseCase) List(queue domain.QueueName, offset, limit int) ([]domain.QueuedPayment, error) {
+	if uc.manager == nil {
+		return nil, ErrQueueManagementUnsupported
+	}
+	if offset < 0 || limit < 1 || limit > MaxQueuePageSize {
+		return nil, fmt.Errorf("%w: offset must not be negative and limit must be between 1 and %d",
+			ErrInvalidQueueRequest, MaxQueuePageSize)
+	}
+
+	return uc.manager.List(queue, offset, limit)
+}
+
+// Move moves payments between queues, all of them when no IDs are given
+func (uc *ManageQueueUseCase) Move(from, to domain.QueueName, correlationIDs []string, operator string) (int, error) {
+	if uc.manager == nil {
+		return 0, ErrQueueManagementUnsupported
+	}
+	if from == to {
+		return 0, fmt.Errorf("%w: cannot move payments from %s to itself", ErrInvalidQueueRequest, from)
+	}
+
+	moved, err := uc.manager.Move(from, to, correlationIDs)
+	if to == domain.DeadLetterQueue {
+		uc.publishFailed(moved, "moved to the dead-letter queue by "+operator)
+	}
+	if err != nil {
+		return len(moved), fmt.Errorf("failed to move payments: %w", err)
+	}
+
+	fmt.Printf("[%s] %s moved %d payments from the %s queue to the %s queue\n", uc.instanceID, operator, len(moved), from, to)
+	return len(moved), nil
+}
+
+// publishFailed reports payments that will not be processed as failed
+func (uc *ManageQueueUseCase) publishFailed(payments []domain.QueuedPayment, reason string) {
+	if uc.events == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	for _, p := range payments {
+		if err := uc.events.Publish(ctx, domain.NewPaymentFailedEvent(p.Payment, reason, now)); err != nil {
+			fmt.Printf("[%s] Failed to publish failure of payment %s: %v\n", uc.instanceID, p.CorrelationId, err)
+		}
+	}
+}
+
+// Purge deletes every payment of a queue, reports them as failed and forgets
+// their correlation IDs, so clients may submit them again
+func (uc *ManageQueueUseCase) Purge(queue domain.QueueName, operator string) (int, error) {
+	if uc.manager == nil {
+		return 0, ErrQueueManagementUnsupported
+	}
+
+	purged, err := uc.manager.Purge(queue)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge queue: %w", err)
+	}
+	for _, p := range purged {
+		if err := uc.store.Remove(p.CorrelationId); err != nil {
+			fmt.Printf("[%s] Failed to remove purged payment %s from store: %v\n", uc.instanceID, p.CorrelationId, err)
+		}
+	}
+	uc.publishFailed(purged, fmt.Sprintf("purged from the %s queue by %s", queue, operator))
+
+	fmt.Printf("[%s] %s purged %d payments from the %s queue\n", uc.instanceID, operator, len(purged), queue)
+	return len(purged), nil
+}
@@ -15,15 +15,29 @@ import (
 type ProcessPaymentsUseCase struct {
 	queue            port.PaymentQueue
 	processorService *service.PaymentProcessorService
+	events           port.PaymentEventPublisher // told about retried and failed payments, may be nil
+	deadLetters      port.QueueManager          // nil when the queue has no dead-letter queue
+	maxAttempts      int                        // 0 retries payments forever
 	running          bool
 	mu               sync.Mutex
 	instanceID       string
 	workerCount      int
+
+	// Set by Start so workers can be added or removed while running
+	ctx         context.Context
+	paymentChan <-chan domain.Payment
+	workers     []chan struct{} // one quit channel per running worker
 }
 
+// NewProcessPaymentsUseCase creates a new process payments use case. A payment
+// that failed maxAttempts times is moved to the dead-letter queue of
+// deadLetters; events and deadLetters may be nil.
 func NewProcessPaymentsUseCase(
 	queue port.PaymentQueue,
 	processorService *service.PaymentProcessorService,
+	events port.PaymentEventPublisher,
+	deadLetters port.QueueManager,
+	maxAttempts int,
 	instanceID string,
 	workerCount int,
 ) *ProcessPaymentsUseCase {
@@ -34,6 +48,9 @@ func NewProcessPaymentsUseCase(
 	return &ProcessPaymentsUseCase{
 		queue:            queue,
 		processorService: processorService,
+		events:           events,
+		deadLetters:      deadLetters,
+		maxAttempts:      maxAttempts,
 		instanceID:       instanceID,
 		workerCount:      workerCount,
 	}
@@ -42,27 +59,57 @@ func NewProcessPaymentsUseCase(
 // Start begins processing payments from the queue with multiple workers
 func (uc *ProcessPaymentsUseCase) Start(ctx context.Context) {
 	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
 	if uc.running {
-		uc.mu.Unlock()
 		return
 	}
 	uc.running = true
-	uc.mu.Unlock()
 
 	fmt.Printf("[%s] Starting payment processor with %d workers\n", uc.instanceID, uc.workerCount)
 
 	// Create a channel to receive payments
-	paymentChan := uc.queue.Receive()
+	uc.ctx = ctx
+	uc.paymentChan = uc.queue.Receive()
 
 	// Start multiple worker goroutines
-	for i := 0; i < uc.workerCount; i++ {
-		workerID := fmt.Sprintf("%s-worker-%d", uc.instanceID, i)
-		go uc.startWorker(ctx, paymentChan, workerID)
+	uc.scaleWorkers(uc.workerCount)
+}
+
+// ApplySettings changes the number of workers to match the settings
+func (uc *ProcessPaymentsUseCase) ApplySettings(settings domain.ResilienceSettings) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if settings.WorkerConcurrency <= 0 || settings.WorkerConcurrency == uc.workerCount {
+		return
+	}
+
+	fmt.Printf("[%s] Changing worker count from %d to %d\n", uc.instanceID, uc.workerCount, settings.WorkerConcurrency)
+	uc.workerCount = settings.WorkerConcurrency
+	if uc.running {
+		uc.scaleWorkers(uc.workerCount)
+	}
+}
+
+// scaleWorkers starts or stops workers until count are running. Must be called with mu held.
+func (uc *ProcessPaymentsUseCase) scaleWorkers(count int) {
+	for len(uc.workers) < count {
+		quit := make(chan struct{})
+		workerID := fmt.Sprintf("%s-worker-%d", uc.instanceID, len(uc.workers))
+		uc.workers = append(uc.workers, quit)
+		go uc.startWorker(uc.ctx, uc.paymentChan, workerID, quit)
+	}
+
+	for len(uc.workers) > count {
+		last := len(uc.workers) - 1
+		close(uc.workers[last])
+		uc.workers = uc.workers[:last]
 	}
 }
 
 // startWorker starts a single worker goroutine
-func (uc *ProcessPaymentsUseCase) startWorker(ctx context.Context, paymentChan <-chan domain.Payment, workerID string) {
+func (uc *ProcessPaymentsUseCase) startWorker(ctx context.Context, paymentChan <-chan domain.Payment, workerID string, quit <-chan struct{}) {
 	fmt.Printf("[%s] Worker started\n", workerID)
 
 	defer func() {
@@ -70,7 +117,7 @@ func (uc *ProcessPaymentsUseCase) startWorker(ctx context.Context, paymentChan <
 			fmt.Printf("[%s] Worker recovered from panic: %v\n", workerID, r)
 			// Restart the worker after a short delay
 			time.Sleep(time.Second)
-			go uc.startWorker(ctx, paymentChan, workerID)
+			go uc.startWorker(ctx, paymentChan, workerID, quit)
 		} else {
 			fmt.Printf("[%s] Worker stopped\n", workerID)
 		}
@@ -91,14 +138,21 @@ func (uc *ProcessPaymentsUseCase) startWorker(ctx context.Context, paymentChan <
 
 			if err != nil {
 				fmt.Printf("[%s] Failed to process payment %s: %v\n", workerID, payment.CorrelationId, err)
-				fmt.Printf("[%s] Re-enqueuing payment %s\n", workerID, payment.CorrelationId)
-				// We could add a exponential backoff for retries
-				// But I think we need to connect to a redis tracker of what failed.
-				uc.queue.Send(payment)
+				if !uc.retry(ctx, workerID, payment, err) {
+					// Leave it unacknowledged so queues that track deliveries redeliver it
+					continue
+				}
 			} else {
 				fmt.Printf("[%s] Successfully processed payment %s\n", workerID, payment.CorrelationId)
 			}
 
+			if err := uc.queue.Ack(payment); err != nil {
+				fmt.Printf("[%s] Failed to acknowledge payment %s: %v\n", workerID, payment.CorrelationId, err)
+			}
+
+		case <-quit:
+			return
+
 		case <-ctx.Done():
 			fmt.Printf("[%s] Context cancelled, stopping worker\n", workerID)
 			return
@@ -107,6 +161,38 @@ func (uc *ProcessPaymentsUseCase) startWorker(ctx context.Context, paymentChan <
 	}
 }
 
+// retry re-enqueues a failed payment, or moves it to the dead-letter queue
+// once it has failed maxAttempts times. It returns false when the payment
+// could not be put anywhere.
+func (uc *ProcessPaymentsUseCase) retry(ctx context.Context, workerID string, payment domain.Payment, cause error) bool {
+	payment.Attempts++
+
+	var event domain.PaymentEvent
+	if uc.deadLetters != nil && uc.maxAttempts > 0 && payment.Attempts >= uc.maxAttempts {
+		fmt.Printf("[%s] Dead-lettering payment %s after %d attempts\n", workerID, payment.CorrelationId, payment.Attempts)
+		if err := uc.deadLetters.DeadLetter(payment); err != nil {
+			fmt.Printf("[%s] Failed to dead-letter payment %s: %v\n", workerID, payment.CorrelationId, err)
+			return false
+		}
+		reason := fmt.Sprintf("moved to the dead-letter queue after %d attempts: %v", payment.Attempts, cause)
+		event = domain.NewPaymentFailedEvent(payment, reason, time.Now())
+	} else {
+		fmt.Printf("[%s] Re-enqueuing payment %s\n", workerID, payment.CorrelationId)
+		if err := uc.queue.Send(payment); err != nil {
+			fmt.Printf("[%s] Failed to re-enqueue payment %s: %v\n", workerID, payment.CorrelationId, err)
+			return false
+		}
+		event = domain.NewPaymentRetriedEvent(payment, cause.Error(), time.Now())
+	}
+
+	if uc.events != nil {
+		if err := uc.events.Publish(ctx, event); err != nil {
+			fmt.Printf("[%s] Failed to publish payment %s: %v\n", workerID, payment.CorrelationId, err)
+		}
+	}
+	return true
+}
+
 // Stop stops the payment processing
 func (uc *ProcessPaymentsUseCase) Stop() error {
 	uc.mu.Lock()
@@ -118,5 +204,6 @@ func (uc *ProcessPaymentsUseCase) Stop() error {
 
 	fmt.Printf("[%s] Stopping payment processor\n", uc.instanceID)
 	uc.running = false
+	uc.scaleWorkers(0)
 	return uc.queue.Close()
 }
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/port"
+)
+
+// RateLimitUseCase applies token-bucket quotas to the callers of POST /payments
+type RateLimitUseCase struct {
+	limiter port.RateLimiter // nil disables rate limiting
+	quota   domain.RateLimitQuota
+	quotas  map[string]domain.RateLimitQuota // by client name
+}
+
+// NewRateLimitUseCase creates a new rate limit use case. quotas overrides
+// quota for some authenticated clients.
+func NewRateLimitUseCase(
+	limiter port.RateLimiter,
+	quota domain.RateLimitQuota,
+	quotas map[string]domain.RateLimitQuota,
+) *RateLimitUseCase {
+	return &RateLimitUseCase{
+		limiter: limiter,
+		quota:   quota,
+		quotas:  quotas,
+	}
+}
+
+// Enabled reports whether requests are rate limited
+func (uc *RateLimitUseCase) Enabled() bool {
+	return uc.limiter != nil
+}
+
+// Allow takes a token from the bucket of the authenticated client, or of the
+// client IP when the request is anonymous
+func (uc *RateLimitUseCase) Allow(ctx context.Context, client, clientIP string) (domain.RateLimitDecision, error) {
+	bucket, quota := "ip:"+clientIP, uc.quota
+	if client != "" {
+		bucket = "client:" + client
+		if q, ok := uc.quotas[client]; ok {
+			quota = q
+		}
+	}
+
+	decision, err := uc.limiter.Take(ctx, bucket, quota)
+	if err != nil {
+		return domain.RateLimitDecision{}, fmt.Errorf("failed to check rate limit of %s: %w", bucket, err)
+	}
+	return decision, nil
+}
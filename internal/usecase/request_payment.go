@@ -2,22 +2,38 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
 	"github.com/lmtani/rinha-de-backend-2025/internal/port"
 )
 
+// MaxBatchSize bounds how many payments one batch request may carry
+const MaxBatchSize = 1000
+
+// ErrBatchTooLarge is returned for batches over MaxBatchSize payments
+var ErrBatchTooLarge = fmt.Errorf("a batch may carry at most %d payments", MaxBatchSize)
+
 // RequestPaymentUseCase handles payment request operations
 type RequestPaymentUseCase struct {
 	queue      port.PaymentQueue
 	store      port.Store
+	events     port.PaymentEventPublisher // told about accepted payments, may be nil
+	callbacks  domain.CallbackPolicy      // hosts each client may name in callbackUrl
 	instanceID string
 }
 
-// NewRequestPaymentUseCase creates a new request payment use case
-func NewRequestPaymentUseCase(queue port.PaymentQueue, store port.Store) *RequestPaymentUseCase {
+// NewRequestPaymentUseCase creates a new request payment use case. events may
+// be nil. Payments may only name a callback URL allowed by callbacks.
+func NewRequestPaymentUseCase(
+	queue port.PaymentQueue,
+	store port.Store,
+	events port.PaymentEventPublisher,
+	callbacks domain.CallbackPolicy,
+) *RequestPaymentUseCase {
 	// Get instance ID from environment or generate a default
 	instanceID := os.Getenv("INSTANCE_ID")
 	if instanceID == "" {
@@ -27,15 +43,18 @@ func NewRequestPaymentUseCase(queue port.PaymentQueue, store port.Store) *Reques
 	return &RequestPaymentUseCase{
 		queue:      queue,
 		store:      store,
+		events:     events,
+		callbacks:  callbacks,
 		instanceID: instanceID,
 	}
 }
 
 // Execute processes a payment request by adding it to the queue
 func (uc *RequestPaymentUseCase) Execute(ctx context.Context, payment domain.Payment) error {
-	if err := payment.Validate(); err != nil {
+	if err := uc.validate(payment); err != nil {
 		return err
 	}
+	payment.Attempts = 0
 
 	fmt.Printf("[%s] Received payment request: %s\n", uc.instanceID, payment.CorrelationId)
 
@@ -46,9 +65,114 @@ func (uc *RequestPaymentUseCase) Execute(ctx context.Context, payment domain.Pay
 
 	if err := uc.queue.Send(payment); err != nil {
 		fmt.Printf("[%s] Failed to send payment to queue: %v\n", uc.instanceID, err)
+		// Release the correlation ID so the client can retry the payment
+		if removeErr := uc.store.Remove(payment.CorrelationId); removeErr != nil {
+			fmt.Printf("[%s] Failed to remove payment from store: %v\n", uc.instanceID, removeErr)
+			return errors.Join(err, removeErr)
+		}
 		return err
 	}
 
 	fmt.Printf("[%s] Successfully queued payment: %s\n", uc.instanceID, payment.CorrelationId)
+	if uc.events != nil {
+		if err := uc.events.Publish(ctx, domain.NewPaymentAcceptedEvent(payment, time.Now())); err != nil {
+			fmt.Printf("[%s] Failed to publish payment %s: %v\n", uc.instanceID, payment.CorrelationId, err)
+		}
+	}
+	return nil
+}
+
+// ExecuteBatch validates the payments, deduplicates them against the store
+// and queues the new ones in one round trip each. The store reserves the
+// batch atomically and the reservation is released if the queue fails. The
+// results are in the order of payments.
+func (uc *RequestPaymentUseCase) ExecuteBatch(ctx context.Context, payments []domain.Payment) ([]domain.BatchItemResult, error) {
+	if len(payments) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]domain.BatchItemResult, len(payments))
+	seen := make(map[string]bool, len(payments))
+	var candidates []int // indexes of the payments left to deduplicate against the store
+	for i, payment := range payments {
+		results[i].CorrelationId = payment.CorrelationId
+		if err := uc.validate(payment); err != nil {
+			results[i].Status, results[i].Error = domain.BatchItemInvalid, err.Error()
+			continue
+		}
+		if seen[payment.CorrelationId] {
+			results[i].Status = domain.BatchItemDuplicate
+			continue
+		}
+		seen[payment.CorrelationId] = true
+		payments[i].Attempts = 0
+		candidates = append(candidates, i)
+	}
+	if len(candidates) == 0 {
+		return results, nil
+	}
+
+	ids := make([]string, len(candidates))
+	for j, i := range candidates {
+		ids[j] = payments[i].CorrelationId
+	}
+	added, err := uc.store.AddBatch(ids)
+	if err != nil {
+		fmt.Printf("[%s] Failed to add batch to store: %v\n", uc.instanceID, err)
+		return nil, err
+	}
+
+	var accepted []domain.Payment
+	var acceptedIdx []int
+	for j, i := range candidates {
+		if !added[j] {
+			results[i].Status = domain.BatchItemDuplicate
+			continue
+		}
+		accepted = append(accepted, payments[i])
+		acceptedIdx = append(acceptedIdx, i)
+	}
+	if len(accepted) == 0 {
+		return results, nil
+	}
+
+	if err := uc.queue.SendBatch(accepted); err != nil {
+		fmt.Printf("[%s] Failed to send batch of %d payments to queue: %v\n", uc.instanceID, len(accepted), err)
+		// Release the correlation IDs so the client can retry the payments
+		ids := make([]string, len(accepted))
+		for j, payment := range accepted {
+			ids[j] = payment.CorrelationId
+		}
+		if removeErr := uc.store.RemoveBatch(ids); removeErr != nil {
+			fmt.Printf("[%s] Failed to remove batch from store: %v\n", uc.instanceID, removeErr)
+			err = errors.Join(err, removeErr)
+		}
+		for _, i := range acceptedIdx {
+			results[i].Status, results[i].Error = domain.BatchItemFailed, err.Error()
+		}
+		return results, nil
+	}
+
+	fmt.Printf("[%s] Successfully queued batch of %d payments\n", uc.instanceID, len(accepted))
+	now := time.Now()
+	for _, i := range acceptedIdx {
+		results[i].Status = domain.BatchItemAccepted
+		if uc.events != nil {
+			if err := uc.events.Publish(ctx, domain.NewPaymentAcceptedEvent(payments[i], now)); err != nil {
+				fmt.Printf("[%s] Failed to publish payment %s: %v\n", uc.instanceID, payments[i].CorrelationId, err)
+			}
+		}
+	}
+	return results, nil
+}
+
+// validate checks the payment and that its client may name its callback URL
+func (uc *RequestPaymentUseCase) validate(payment domain.Payment) error {
+	if err := payment.Validate(); err != nil {
+		return err
+	}
+	if payment.CallbackURL != "" {
+		return uc.callbacks.Check(payment.Client, payment.CallbackURL)
+	}
 	return nil
 }
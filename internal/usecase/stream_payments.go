@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/port"
+)
+
+// streamSubscriberBuffer is how many events a slow stream client may lag
+// behind before events are dropped for it
+const streamSubscriberBuffer = 256
+
+// ErrEventStreamDisabled is returned when the event stream is not configured
+var ErrEventStreamDisabled = errors.New("the payment event stream is disabled")
+
+// streamSubscriber is a stream client of this instance
+type streamSubscriber struct {
+	events chan domain.PaymentEvent
+	filter domain.PaymentEventFilter
+}
+
+// StreamPaymentsUseCase publishes payment events on the event bus and fans
+// the events of every instance out to the stream clients of this one
+type StreamPaymentsUseCase struct {
+	bus              port.PaymentEventBus // nil when the stream is disabled
+	repository       port.PaymentRepository
+	snapshotInterval time.Duration
+	instanceID       string
+
+	mu          sync.Mutex
+	subscribers map[*streamSubscriber]struct{}
+}
+
+// NewStreamPaymentsUseCase creates a new stream payments use case. bus may
+// be nil, which disables the stream.
+func NewStreamPaymentsUseCase(
+	bus port.PaymentEventBus,
+	repository port.PaymentRepository,
+	snapshotInterval time.Duration,
+	instanceID string,
+) *StreamPaymentsUseCase {
+	return &StreamPaymentsUseCase{
+		bus:              bus,
+		repository:       repository,
+		snapshotInterval: snapshotInterval,
+		instanceID:       instanceID,
+		subscribers:      make(map[*streamSubscriber]struct{}),
+	}
+}
+
+// Enabled reports whether the stream is available
+func (uc *StreamPaymentsUseCase) Enabled() bool {
+	return uc.bus != nil
+}
+
+// SnapshotInterval returns how often stream clients get a summary snapshot
+func (uc *StreamPaymentsUseCase) SnapshotInterval() time.Duration {
+	return uc.snapshotInterval
+}
+
+// Publish sends an event to the stream clients of every instance. The
+// callback URL of the payment is left out.
+func (uc *StreamPaymentsUseCase) Publish(ctx context.Context, event domain.PaymentEvent) error {
+	if !uc.Enabled() {
+		return nil
+	}
+	event.Payment.CallbackURL = ""
+	return uc.bus.Publish(ctx, event)
+}
+
+// Start relays the events of the bus to the stream clients until ctx is done
+func (uc *StreamPaymentsUseCase) Start(ctx context.Context) error {
+	if !uc.Enabled() {
+		return nil
+	}
+
+	events, err := uc.bus.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to payment events: %w", err)
+	}
+
+	go func() {
+		for event := range events {
+			uc.mu.Lock()
+			for sub := range uc.subscribers {
+				if !sub.filter.Matches(event) {
+					continue
+				}
+				select {
+				case sub.events <- event:
+				default:
+				}
+			}
+			uc.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+// Subscribe returns the events matching filter from now on, closed when
+// ctx is done. Events are dropped for clients that fall behind.
+func (uc *StreamPaymentsUseCase) Subscribe(ctx context.Context, filter domain.PaymentEventFilter) (<-chan domain.PaymentEvent, error) {
+	if !uc.Enabled() {
+		return nil, ErrEventStreamDisabled
+	}
+
+	sub := &streamSubscriber{
+		events: make(chan domain.PaymentEvent, streamSubscriberBuffer),
+		filter: filter,
+	}
+	uc.mu.Lock()
+	uc.subscribers[sub] = struct{}{}
+	uc.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		uc.mu.Lock()
+		delete(uc.subscribers, sub)
+		uc.mu.Unlock()
+		close(sub.events)
+	}()
+	return sub.events, nil
+}
+
+// HasSnapshots reports whether a stream with filter gets summary snapshots.
+// The summary covers the payments of every client, so streams scoped to one
+// client go without.
+func (uc *StreamPaymentsUseCase) HasSnapshots(filter domain.PaymentEventFilter) bool {
+	return filter.Client == ""
+}
+
+// Snapshot returns the current summary of the filtered channels
+func (uc *StreamPaymentsUseCase) Snapshot(filter domain.PaymentEventFilter) (map[domain.ProcessorChannel]domain.PaymentsChannelStats, error) {
+	summary, err := uc.repository.GetSummary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary: %w", err)
+	}
+	return filter.Summary(summary), nil
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/port"
+)
+
+// ErrInvalidSettings is returned when an update would produce invalid settings
+var ErrInvalidSettings = errors.New("invalid settings")
+
+// UpdateSettingsUseCase changes resilience settings at runtime and keeps
+// every instance in sync through the settings bus
+type UpdateSettingsUseCase struct {
+	mu         sync.Mutex
+	current    domain.ResilienceSettings
+	version    int64 // of the change in effect, 0 for the configured settings
+	tunables   []port.Tunable
+	bus        port.SettingsBus
+	auditLog   port.SettingsAuditLog
+	instanceID string
+}
+
+// NewUpdateSettingsUseCase creates a new update settings use case
+func NewUpdateSettingsUseCase(
+	initial domain.ResilienceSettings,
+	tunables []port.Tunable,
+	bus port.SettingsBus,
+	auditLog port.SettingsAuditLog,
+	instanceID string,
+) *UpdateSettingsUseCase {
+	return &UpdateSettingsUseCase{
+		current:    initial,
+		tunables:   tunables,
+		bus:        bus,
+		auditLog:   auditLog,
+		instanceID: instanceID,
+	}
+}
+
+// Current returns the settings in effect on this instance
+func (uc *UpdateSettingsUseCase) Current() domain.ResilienceSettings {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return uc.current
+}
+
+// Execute validates the update, records it under a new version, applies it
+// locally and broadcasts it to the other instances
+func (uc *UpdateSettingsUseCase) Execute(ctx context.Context, update domain.SettingsUpdate, changedBy string) (domain.SettingsChange, error) {
+	uc.mu.Lock()
+	before := uc.current
+	after := update.ApplyTo(before)
+	if err := after.Validate(); err != nil {
+		uc.mu.Unlock()
+		return domain.SettingsChange{}, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+	}
+
+	change := domain.SettingsChange{
+		ChangedBy: changedBy,
+		ChangedAt: time.Now().UTC(),
+		Instance:  uc.instanceID,
+		Fields:    domain.ChangedFields(before, after),
+		Before:    before,
+		After:     after,
+	}
+
+	// Holding mu while recording keeps changes of other instances from being
+	// applied in between, so every change applied so far has a lower version
+	version, err := uc.auditLog.Record(ctx, change)
+	if err != nil {
+		uc.mu.Unlock()
+		return change, fmt.Errorf("failed to record settings change: %w", err)
+	}
+	change.Version = version
+	uc.applyLocked(change)
+	uc.mu.Unlock()
+
+	fmt.Printf("[%s] Settings changed by %s (version %d): %v\n", uc.instanceID, changedBy, change.Version, change.Fields)
+
+	if err := uc.bus.Publish(ctx, change); err != nil {
+		return change, fmt.Errorf("settings applied on %s but not propagated: %w", uc.instanceID, err)
+	}
+
+	return change, nil
+}
+
+// History returns the most recent settings changes
+func (uc *UpdateSettingsUseCase) History(ctx context.Context, limit int) ([]domain.SettingsChange, error) {
+	return uc.auditLog.List(ctx, limit)
+}
+
+// Start applies the latest recorded change, so a restarted instance resumes
+// with the live settings rather than its configured ones, then listens for
+// changes made on other instances and applies them here. Changes older than
+// the one in effect are ignored, whatever order they arrive in.
+func (uc *UpdateSettingsUseCase) Start(ctx context.Context) error {
+	// Subscribe first so no change is missed between reading the log and
+	// listening; the versions tell which of them is the latest
+	changes, err := uc.bus.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to settings changes: %w", err)
+	}
+
+	if err := uc.restoreLatest(ctx); err != nil {
+		fmt.Printf("[%s] Keeping configured settings: %v\n", uc.instanceID, err)
+	}
+
+	go func() {
+		for change := range changes {
+			if change.Instance == uc.instanceID {
+				continue
+			}
+			if err := change.After.Validate(); err != nil {
+				fmt.Printf("[%s] Ignoring invalid settings from %s: %v\n", uc.instanceID, change.Instance, err)
+				continue
+			}
+
+			uc.mu.Lock()
+			applied, current := uc.applyLocked(change), uc.version
+			uc.mu.Unlock()
+			if !applied {
+				fmt.Printf("[%s] Ignoring settings version %d from %s, version %d is in effect\n",
+					uc.instanceID, change.Version, change.Instance, current)
+				continue
+			}
+			fmt.Printf("[%s] Applied settings changed by %s on %s (version %d): %v\n",
+				uc.instanceID, change.ChangedBy, change.Instance, change.Version, change.Fields)
+		}
+	}()
+
+	return nil
+}
+
+// restoreLatest applies the settings of the latest change in the audit log
+func (uc *UpdateSettingsUseCase) restoreLatest(ctx context.Context) error {
+	latest, err := uc.auditLog.List(ctx, 1)
+	if err != nil {
+		return fmt.Errorf("failed to read the latest settings change: %w", err)
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+
+	change := latest[0]
+	if err := change.After.Validate(); err != nil {
+		return fmt.Errorf("invalid settings recorded by %s: %v", change.Instance, err)
+	}
+
+	uc.mu.Lock()
+	applied := uc.applyLocked(change)
+	uc.mu.Unlock()
+	if applied {
+		fmt.Printf("[%s] Restored settings changed by %s on %s at %s (version %d)\n", uc.instanceID,
+			change.ChangedBy, change.Instance, change.ChangedAt.Format(time.RFC3339), change.Version)
+	}
+	return nil
+}
+
+// applyLocked stores and applies the settings of a change newer than the one
+// in effect, reporting whether it did. Must be called with mu held.
+func (uc *UpdateSettingsUseCase) applyLocked(change domain.SettingsChange) bool {
+	if change.Version <= uc.version {
+		return false
+	}
+	uc.version = change.Version
+	uc.current = change.After
+	for _, t := range uc.tunables {
+		t.ApplySettings(change.After)
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/auth"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/client"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/handler"
+	"github.com/lmtani/rinha-de-backend-2025/internal/processorspec"
+)
+
+const testAdminAPIToken = "test-admin-api-token-0123456789"
+
+// startAdminAPI serves the admin app in front of two fake processors
+func startAdminAPI(t *testing.T) (*client.AdminClient, *fakeProcessor, *fakeProcessor) {
+	t.Helper()
+
+	dflt, fallback := newFakeProcessor(t), newFakeProcessor(t)
+	credentials := auth.NewCredentials()
+	if err := credentials.AddPassword("admin", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := handler.NewAdminHandler(handler.Config{
+		Processors: []processorspec.Processor{
+			{Name: "default", URL: dflt.server.URL},
+			{Name: "fallback", URL: fallback.server.URL},
+		},
+		APIURL:       "http://127.0.0.1:1",
+		Token:        testProcessorToken,
+		Auth:         auth.NewAuthenticator(credentials, auth.NewSessionStore(time.Hour), false),
+		APITokens:    map[string]string{"ci": testAdminAPIToken},
+		TemplatesDir: "../web/templates",
+	})
+	if err != nil {
+		t.Fatalf("NewAdminHandler() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h.RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	return client.NewAdminClient(server.URL, testAdminAPIToken), dflt, fallback
+}
+
+func TestAdminAPIRequiresToken(t *testing.T) {
+	admin, _, _ := startAdminAPI(t)
+
+	_, err := client.NewAdminClient(admin.BaseURL, "wrong-token-0123456789").Purge(context.Background(), "all")
+	var apiErr *client.AdminError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Purge() with a wrong token error = %v, want 401", err)
+	}
+}
+
+func TestAdminAPIActions(t *testing.T) {
+	admin, dflt, fallback := startAdminAPI(t)
+	ctx := context.Background()
+
+	response, err := admin.SetFailure(ctx, "all", true)
+	if err != nil {
+		t.Fatalf("SetFailure(all) error = %v", err)
+	}
+	if len(response.Results) != 2 || !dflt.failing.Load() || !fallback.failing.Load() {
+		t.Errorf("SetFailure(all) = %+v, want both processors failing", response.Results)
+	}
+
+	if _, err := admin.SetDelay(ctx, "fallback", 250); err != nil {
+		t.Fatalf("SetDelay(fallback) error = %v", err)
+	}
+	if dflt.delay.Load() != 0 || fallback.delay.Load() != 250 {
+		t.Errorf("delays = %d/%d, want 0/250", dflt.delay.Load(), fallback.delay.Load())
+	}
+
+	summary, err := admin.Summary(ctx, "default", nil, nil)
+	if err != nil || len(summary.Results) != 1 || summary.Results[0].Summary == nil {
+		t.Errorf("Summary(default) = %+v, %v, want one summary", summary, err)
+	}
+
+	if _, err := admin.Purge(ctx, "nope"); err == nil {
+		t.Error("Purge(nope) succeeded, want unknown processor error")
+	}
+}
+
+func TestAdminAPIPartialFailure(t *testing.T) {
+	admin, _, fallback := startAdminAPI(t)
+	fallback.server.Close()
+
+	response, err := admin.SetDelay(context.Background(), "all", 10)
+	var apiErr *client.AdminError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("SetDelay(all) error = %v, want 502", err)
+	}
+	if response == nil || len(response.Results) != 2 || !response.Results[0].OK || response.Results[1].OK {
+		t.Errorf("SetDelay(all) results = %+v, want default ok and fallback failed", response)
+	}
+}
+
+func TestAdminAPIProcessorRegistry(t *testing.T) {
+	admin, dflt, fallback := startAdminAPI(t)
+	extra := newFakeProcessor(t)
+	ctx := context.Background()
+
+	if err := admin.AddProcessor(ctx, "extra", extra.server.URL, ""); err != nil {
+		t.Fatalf("AddProcessor(extra) error = %v", err)
+	}
+	if err := admin.AddProcessor(ctx, "extra", extra.server.URL, ""); err == nil {
+		t.Error("AddProcessor(extra) twice succeeded, want duplicate error")
+	}
+	if err := admin.AddProcessor(ctx, "all", extra.server.URL, ""); err == nil {
+		t.Error("AddProcessor(all) succeeded, want reserved name error")
+	}
+
+	list, err := admin.Processors(ctx)
+	if err != nil || len(list.Processors) != 3 || list.Processors[2].Name != "extra" || !list.Processors[2].OK {
+		t.Fatalf("Processors() = %+v, %v, want default, fallback and a healthy extra", list, err)
+	}
+
+	// Global actions include processors added at runtime
+	if _, err := admin.SetFailure(ctx, "all", true); err != nil {
+		t.Fatalf("SetFailure(all) error = %v", err)
+	}
+	if !dflt.failing.Load() || !fallback.failing.Load() || !extra.failing.Load() {
+		t.Error("SetFailure(all) did not reach every processor")
+	}
+
+	if err := admin.RemoveProcessor(ctx, "extra"); err != nil {
+		t.Fatalf("RemoveProcessor(extra) error = %v", err)
+	}
+	if _, err := admin.SetDelay(ctx, "extra", 10); err == nil {
+		t.Error("SetDelay(extra) after removal succeeded, want unknown processor error")
+	}
+}
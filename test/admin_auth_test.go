@@ -0,0 +1,198 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/auth"
+)
+
+// newAuthRouter wires the authenticator the way the admin handler does, with
+// a stand-in login form and a protected POST route
+func newAuthRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	credentials := auth.NewCredentials()
+	if err := credentials.AddPassword("admin", "correct horse"); err != nil {
+		t.Fatalf("AddPassword() error = %v", err)
+	}
+	a := auth.NewAuthenticator(credentials, auth.NewSessionStore(time.Hour), false)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/login", func(c *gin.Context) {
+		token, _ := a.LoginCSRFToken(c)
+		c.String(http.StatusOK, token)
+	})
+	r.POST("/login", func(c *gin.Context) {
+		if !a.CheckLoginCSRF(c) {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		if err := a.Login(c, c.PostForm("username"), c.PostForm("password")); err != nil {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	protected := r.Group("/", a.RequireSession())
+	protected.GET("/csrf", func(c *gin.Context) {
+		c.String(http.StatusOK, auth.CurrentSession(c).CSRFToken)
+	})
+	protected.POST("/global/purge", func(c *gin.Context) {
+		c.String(http.StatusOK, "purged")
+	})
+	return r
+}
+
+func serve(r *gin.Engine, req *http.Request, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func formRequest(path string, form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// login performs the login flow and returns the session cookie
+func login(t *testing.T, r *gin.Engine, password string) (*httptest.ResponseRecorder, []*http.Cookie) {
+	t.Helper()
+
+	page := serve(r, httptest.NewRequest(http.MethodGet, "/login", nil), nil)
+	form := url.Values{"csrf_token": {page.Body.String()}, "username": {"admin"}, "password": {password}}
+	w := serve(r, formRequest("/login", form), page.Result().Cookies())
+
+	var session []*http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == auth.SessionCookie && cookie.Value != "" {
+			session = append(session, cookie)
+		}
+	}
+	return w, session
+}
+
+func TestAdminAuthRequiresSession(t *testing.T) {
+	r := newAuthRouter(t)
+
+	w := serve(r, httptest.NewRequest(http.MethodPost, "/global/purge", nil), nil)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login" {
+		t.Errorf("POST without session = %d %q, want redirect to /login", w.Code, w.Header().Get("Location"))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/global/purge", nil)
+	req.Header.Set("HX-Request", "true")
+	w = serve(r, req, nil)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("HX-Redirect") != "/login" {
+		t.Errorf("htmx POST without session = %d %q, want 401 with HX-Redirect", w.Code, w.Header().Get("HX-Redirect"))
+	}
+}
+
+func TestAdminAuthLogin(t *testing.T) {
+	r := newAuthRouter(t)
+
+	if w, session := login(t, r, "wrong"); w.Code != http.StatusUnauthorized || len(session) != 0 {
+		t.Errorf("login with wrong password = %d with %d session cookies, want 401 and none", w.Code, len(session))
+	}
+
+	// A login post without the form's token is rejected
+	form := url.Values{"username": {"admin"}, "password": {"correct horse"}}
+	if w := serve(r, formRequest("/login", form), nil); w.Code != http.StatusForbidden {
+		t.Errorf("login without CSRF token = %d, want 403", w.Code)
+	}
+
+	w, session := login(t, r, "correct horse")
+	if w.Code != http.StatusNoContent || len(session) != 1 {
+		t.Fatalf("login = %d with %d session cookies, want 204 and one", w.Code, len(session))
+	}
+	if !session[0].HttpOnly || session[0].SameSite != http.SameSiteStrictMode {
+		t.Errorf("session cookie is not HttpOnly and SameSite=Strict")
+	}
+}
+
+func TestAdminAuthCSRF(t *testing.T) {
+	r := newAuthRouter(t)
+	_, session := login(t, r, "correct horse")
+
+	csrf := serve(r, httptest.NewRequest(http.MethodGet, "/csrf", nil), session).Body.String()
+
+	if w := serve(r, httptest.NewRequest(http.MethodPost, "/global/purge", nil), session); w.Code != http.StatusForbidden {
+		t.Errorf("POST without CSRF token = %d, want 403", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/global/purge", nil)
+	req.Header.Set(auth.CSRFHeader, "not-the-token")
+	if w := serve(r, req, session); w.Code != http.StatusForbidden {
+		t.Errorf("POST with wrong CSRF token = %d, want 403", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/global/purge", nil)
+	req.Header.Set(auth.CSRFHeader, csrf)
+	if w := serve(r, req, session); w.Code != http.StatusOK {
+		t.Errorf("POST with CSRF header = %d, want 200", w.Code)
+	}
+
+	if w := serve(r, formRequest("/global/purge", url.Values{auth.CSRFField: {csrf}}), session); w.Code != http.StatusOK {
+		t.Errorf("POST with CSRF form field = %d, want 200", w.Code)
+	}
+}
+
+func TestAdminPasswordFile(t *testing.T) {
+	hash, err := auth.HashPassword("s3cret-password")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "passwd")
+	content := "# admin users\n\nops:" + hash + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials := auth.NewCredentials()
+	if err := auth.LoadPasswordFile(path, credentials); err != nil {
+		t.Fatalf("LoadPasswordFile() error = %v", err)
+	}
+	if !credentials.Verify("ops", "s3cret-password") {
+		t.Error("Verify() rejected the right password")
+	}
+	if credentials.Verify("ops", "wrong") || credentials.Verify("nobody", "s3cret-password") {
+		t.Error("Verify() accepted a wrong user or password")
+	}
+
+	if err := os.WriteFile(path, []byte("ops:plaintext\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.LoadPasswordFile(path, auth.NewCredentials()); err == nil {
+		t.Error("LoadPasswordFile() accepted a plaintext password")
+	}
+}
+
+func TestParseAPITokensDoesNotEchoSecrets(t *testing.T) {
+	const secret = "0123456789abcdef-secret"
+
+	tokens, err := auth.ParseAPITokens("ci:" + secret)
+	if err != nil || tokens["ci"] != secret {
+		t.Fatalf("ParseAPITokens() = %v, %v", tokens, err)
+	}
+
+	// A token pasted without its name must not end up in logs
+	_, err = auth.ParseAPITokens("ci:" + secret + "," + secret)
+	if err == nil {
+		t.Fatal("ParseAPITokens() accepted an entry without a name")
+	}
+	if strings.Contains(err.Error(), secret) || !strings.Contains(err.Error(), "entry 2") {
+		t.Errorf("Expected the error to name the entry and not the token, got %q", err)
+	}
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/admin/chaos"
+)
+
+func TestChaosScenarioActions(t *testing.T) {
+	scenario, err := chaos.ParseScenario([]byte(`
+name: outage
+steps:
+  - {at: 10s, processor: default, failure: true, for: 20s}
+  - {at: 15s, processor: fallback, delay: 800}
+`))
+	if err != nil {
+		t.Fatalf("ParseScenario() error = %v", err)
+	}
+
+	actions := scenario.Actions()
+	want := []struct {
+		at          time.Duration
+		description string
+	}{
+		{10 * time.Second, "default failure on"},
+		{15 * time.Second, "fallback delay 800ms"},
+		{30 * time.Second, "default failure off"},
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("Actions() returned %d actions, want %d", len(actions), len(want))
+	}
+	for i, w := range want {
+		if actions[i].At != w.at || actions[i].Description() != w.description {
+			t.Errorf("action %d = t+%s %s, want t+%s %s", i, actions[i].At, actions[i].Description(), w.at, w.description)
+		}
+	}
+	if scenario.Duration() != 30*time.Second {
+		t.Errorf("Duration() = %s, want 30s", scenario.Duration())
+	}
+}
+
+func TestChaosScenarioValidation(t *testing.T) {
+	invalid := []string{
+		`steps: []`,
+		`steps: [{at: 1s, failure: true}]`,
+		`steps: [{at: 1s, processor: default}]`,
+		`steps: [{at: 1s, processor: default, failure: true, delay: 10}]`,
+		`steps: [{at: 1s, processor: default, delay: -1}]`,
+	}
+	for _, text := range invalid {
+		if _, err := chaos.ParseScenario([]byte(text)); err == nil {
+			t.Errorf("ParseScenario(%q) succeeded, want error", text)
+		}
+	}
+}
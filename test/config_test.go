@@ -0,0 +1,117 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/config"
+)
+
+func TestConfigDefaultsAreValid(t *testing.T) {
+	cfg, err := config.LoadFile("")
+	if err != nil {
+		t.Fatalf("Expected defaults to load, got %v", err)
+	}
+
+	if cfg.Processor.CircuitBreaker.Interval != 10*time.Second {
+		t.Errorf("Expected circuit breaker interval 10s, got %s", cfg.Processor.CircuitBreaker.Interval)
+	}
+	if cfg.Processor.CircuitBreaker.Timeout != 5*time.Second {
+		t.Errorf("Expected circuit breaker timeout 5s, got %s", cfg.Processor.CircuitBreaker.Timeout)
+	}
+}
+
+func TestConfigRejectsInvalidEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr string
+	}{
+		{"duration without unit", "CB_TIMEOUT", "5", "CB_TIMEOUT"},
+		{"unparsable integer", "WORKER_CONCURRENCY", "four", "WORKER_CONCURRENCY"},
+		{"negative max attempts", "PROCESSOR_MAX_ATTEMPTS", "-1", "processor.max_attempts"},
+		{"out of range ratio", "CB_FAILURE_RATIO", "1.5", "failure_ratio"},
+		{"unknown adapter", "QUEUE_ADAPTER", "kafka", "adapters.queue"},
+		{"processor without URL", "PROCESSORS", "default", "PROCESSORS"},
+		{"duplicate processor", "PROCESSORS", "a=http://a:8080,a=http://b:8080", "listed twice"},
+		{"quota without burst", "RATE_LIMIT_QUOTAS", "acme=50", "RATE_LIMIT_QUOTAS"},
+		{"short API key", "API_KEYS", "acme:short", "auth.api_keys[0]"},
+		{"unknown signing mode", "AUTH_SIGNING", "sometimes", "auth.signing"},
+		{"webhook URL without name", "WEBHOOK_URLS", "http://hooks:8080", "WEBHOOK_URLS"},
+		{"callback hosts without name", "WEBHOOK_CALLBACK_HOSTS", "hooks.acme.example", "WEBHOOK_CALLBACK_HOSTS"},
+		{"unknown event stream adapter", "EVENT_STREAM_ADAPTER", "kafka", "adapters.event_stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+
+			_, err := config.LoadFile("")
+			if err == nil {
+				t.Fatalf("Expected an error for %s=%s", tt.key, tt.value)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error to mention %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigProcessorsByPriority(t *testing.T) {
+	t.Setenv("PROCESSORS", "slow:5=http://slow:8080,default=http://default:8080,fallback:2=http://fallback:8080")
+
+	cfg, err := config.LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	var order []string
+	for _, p := range cfg.Processor.Targets() {
+		order = append(order, string(p.Channel))
+	}
+	if strings.Join(order, ",") != "default,fallback,slow" {
+		t.Errorf("Targets() order = %v, want default,fallback,slow", order)
+	}
+}
+
+func TestConfigFileWithEnvironmentOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[server]\nworker_concurrency = 8\n\n[processor.circuit_breaker]\ntimeout = \"2s\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("WORKER_CONCURRENCY", "16")
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load config file: %v", err)
+	}
+
+	if cfg.Server.WorkerConcurrency != 16 {
+		t.Errorf("Expected environment to override worker concurrency to 16, got %d", cfg.Server.WorkerConcurrency)
+	}
+	if cfg.Processor.CircuitBreaker.Timeout != 2*time.Second {
+		t.Errorf("Expected circuit breaker timeout 2s from file, got %s", cfg.Processor.CircuitBreaker.Timeout)
+	}
+}
+
+func TestConfigRedactsSecrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.ConnectionString = "postgres://user:s3cret@db:5432/payments"
+	cfg.Redis.URL = "redis://:hunter2@redis:6379/0"
+
+	out, err := cfg.YAML()
+	if err != nil {
+		t.Fatalf("Failed to render config: %v", err)
+	}
+
+	for _, secret := range []string{"s3cret", "hunter2"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("Expected %q to be redacted, got:\n%s", secret, out)
+		}
+	}
+}
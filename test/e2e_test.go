@@ -0,0 +1,1368 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	paymentsv1 "github.com/lmtani/rinha-de-backend-2025/api/payments/v1"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/http_client"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/in_memory_repository"
+	"github.com/lmtani/rinha-de-backend-2025/internal/app"
+	"github.com/lmtani/rinha-de-backend-2025/internal/config"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestE2EDefaultProcessorSuccess(t *testing.T) {
+	a := startTestApp(t)
+
+	if status := a.postPayment("e2e-default-1", 19.90); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+
+	summary := a.waitForSummary(1, 0)
+	if summary.Default.TotalAmount != 19.90 {
+		t.Errorf("Expected default amount 19.90, got %f", summary.Default.TotalAmount)
+	}
+	if got := a.dflt.received("e2e-default-1"); got != 1 {
+		t.Errorf("Expected default processor to receive the payment once, got %d", got)
+	}
+	if got := a.fallback.received("e2e-default-1"); got != 0 {
+		t.Errorf("Expected fallback processor to receive nothing, got %d", got)
+	}
+}
+
+func TestE2EFallbackWhenDefaultFails(t *testing.T) {
+	a := startTestApp(t)
+	a.dflt.failing.Store(true)
+
+	if status := a.postPayment("e2e-fallback-1", 10); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+
+	summary := a.waitForSummary(0, 1)
+	if summary.Fallback.TotalAmount != 10 {
+		t.Errorf("Expected fallback amount 10, got %f", summary.Fallback.TotalAmount)
+	}
+	if got := a.fallback.received("e2e-fallback-1"); got != 1 {
+		t.Errorf("Expected fallback processor to receive the payment once, got %d", got)
+	}
+}
+
+func TestE2EBothProcessorsDownThenRecovery(t *testing.T) {
+	a := startTestApp(t)
+	a.dflt.failing.Store(true)
+	a.fallback.failing.Store(true)
+
+	if status := a.postPayment("e2e-outage-1", 42); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+
+	// Nothing can be recorded while both processors are down
+	time.Sleep(300 * time.Millisecond)
+	summary := a.summary(nil, nil)
+	if summary.Default.TotalRequests != 0 || summary.Fallback.TotalRequests != 0 {
+		t.Fatalf("Expected no recorded payments during outage, got %+v", summary)
+	}
+
+	// Once the default processor recovers the retried payment goes through
+	a.dflt.failing.Store(false)
+	summary = a.waitForSummary(1, 0)
+	if summary.Default.TotalAmount != 42 {
+		t.Errorf("Expected default amount 42, got %f", summary.Default.TotalAmount)
+	}
+}
+
+func TestE2EDuplicateCorrelationID(t *testing.T) {
+	a := startTestApp(t)
+
+	if status := a.postPayment("e2e-dup-1", 5); status != http.StatusAccepted {
+		t.Fatalf("Expected first request to be accepted, got %d", status)
+	}
+	if status := a.postPayment("e2e-dup-1", 5); status == http.StatusAccepted {
+		t.Fatalf("Expected duplicate request to be rejected, got %d", status)
+	}
+
+	a.waitForSummary(1, 0)
+	if got := a.dflt.received("e2e-dup-1"); got != 1 {
+		t.Errorf("Expected default processor to receive the payment once, got %d", got)
+	}
+}
+
+func TestE2ESummaryRangeFiltering(t *testing.T) {
+	a := startTestApp(t)
+
+	a.postPayment("e2e-range-1", 1)
+	a.waitForSummary(1, 0)
+
+	between := time.Now().UTC()
+	time.Sleep(10 * time.Millisecond)
+
+	a.postPayment("e2e-range-2", 2)
+	a.waitForSummary(2, 0)
+
+	before := a.summary(nil, &between)
+	if before.Default.TotalRequests != 1 || before.Default.TotalAmount != 1 {
+		t.Errorf("Expected only the first payment before the split, got %+v", before.Default)
+	}
+
+	after := a.summary(&between, nil)
+	if after.Default.TotalRequests != 1 || after.Default.TotalAmount != 2 {
+		t.Errorf("Expected only the second payment after the split, got %+v", after.Default)
+	}
+}
+
+func TestE2ESummaryTimeseries(t *testing.T) {
+	a := startTestApp(t)
+	from := time.Now().UTC().Add(-2 * time.Second)
+
+	a.postPayment("e2e-series-1", 1)
+	a.postPayment("e2e-series-2", 2)
+	a.waitForSummary(2, 0)
+
+	to := time.Now().UTC().Add(time.Second)
+	query := url.Values{
+		"from":   {from.Format(time.RFC3339Nano)},
+		"to":     {to.Format(time.RFC3339Nano)},
+		"bucket": {"1s"},
+	}
+	resp, err := http.Get(a.server.URL + "/payments-summary/timeseries?" + query.Encode())
+	if err != nil {
+		t.Fatalf("GET /payments-summary/timeseries failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var series domain.Timeseries
+	if err := json.NewDecoder(resp.Body).Decode(&series); err != nil {
+		t.Fatalf("Failed to decode timeseries: %v", err)
+	}
+	if want := len(domain.BucketSecond.Starts(from, to)); len(series.Points) != want {
+		t.Fatalf("Expected %d buckets, got %d", want, len(series.Points))
+	}
+
+	var total domain.PaymentsChannelStats
+	for _, p := range series.Points {
+		total.TotalRequests += p.Summary.Default.TotalRequests
+		total.TotalAmount += p.Summary.Default.TotalAmount
+	}
+	if total.TotalRequests != 2 || total.TotalAmount != 3 {
+		t.Errorf("Expected buckets to add up to 2 payments of 3, got %+v", total)
+	}
+
+	for _, bad := range []string{"bucket=5m&from=" + url.QueryEscape(from.Format(time.RFC3339)), "bucket=1s&from=2020-01-01T00:00:00Z"} {
+		resp, err := http.Get(a.server.URL + "/payments-summary/timeseries?" + bad)
+		if err != nil {
+			t.Fatalf("GET /payments-summary/timeseries failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("GET ?%s returned %d, want 400", bad, resp.StatusCode)
+		}
+	}
+}
+
+func TestE2ERuntimeSettingsUpdate(t *testing.T) {
+	a := startTestApp(t)
+	update := map[string]interface{}{
+		"workerConcurrency": 6,
+		"processorTimeout":  "750ms",
+		"circuitBreaker":    map[string]interface{}{"timeout": "2s"},
+	}
+
+	if status := a.controlRequest(http.MethodPut, "/internal/settings", "", update, nil); status != http.StatusUnauthorized {
+		t.Fatalf("Expected unauthenticated update to be rejected with 401, got %d", status)
+	}
+
+	invalid := map[string]interface{}{"processorTimeout": "750"}
+	if status := a.controlRequest(http.MethodPut, "/internal/settings", testControlToken, invalid, nil); status != http.StatusBadRequest {
+		t.Fatalf("Expected duration without unit to be rejected with 400, got %d", status)
+	}
+
+	var change struct {
+		ChangedBy string   `json:"changedBy"`
+		Fields    []string `json:"fields"`
+	}
+	if status := a.controlRequest(http.MethodPut, "/internal/settings", testControlToken, update, &change); status != http.StatusOK {
+		t.Fatalf("Expected update to succeed, got %d", status)
+	}
+	if change.ChangedBy != "tester" || len(change.Fields) != 3 {
+		t.Errorf("Expected 3 fields changed by tester, got %+v", change)
+	}
+
+	current := a.container.UpdateSettingsUC.Current()
+	if current.WorkerConcurrency != 6 || current.ProcessorTimeout != 750*time.Millisecond || current.CircuitBreaker.Timeout != 2*time.Second {
+		t.Errorf("Expected settings to be applied, got %+v", current)
+	}
+
+	// Payments keep flowing with the resized worker pool
+	a.postPayment("e2e-settings-1", 3)
+	a.waitForSummary(1, 0)
+
+	var history []struct {
+		ChangedBy string `json:"changedBy"`
+	}
+	if status := a.controlRequest(http.MethodGet, "/internal/settings/history", testControlToken, nil, &history); status != http.StatusOK {
+		t.Fatalf("Expected history to be returned, got %d", status)
+	}
+	if len(history) != 1 || history[0].ChangedBy != "tester" {
+		t.Errorf("Expected one change by tester in history, got %+v", history)
+	}
+}
+
+func TestE2ERuntimeSettingsAcrossInstances(t *testing.T) {
+	bus := in_memory_repository.NewInMemorySettingsBus()
+	auditLog := in_memory_repository.NewInMemorySettingsAuditLog()
+	startInstance := func(instanceID string) *testApp {
+		return startTestAppWith(t, func(cfg *config.Config, _, _ *fakeProcessor) {
+			cfg.Server.InstanceID = instanceID
+		}, app.WithSettings(bus, auditLog))
+	}
+
+	a := startInstance("instance-a")
+	b := startInstance("instance-b")
+
+	update := map[string]interface{}{"workerConcurrency": 5, "processorTimeout": "600ms"}
+	if status := a.controlRequest(http.MethodPut, "/internal/settings", testControlToken, update, nil); status != http.StatusOK {
+		t.Fatalf("Expected update to succeed, got %d", status)
+	}
+	tuned := func(settings domain.ResilienceSettings) bool {
+		return settings.WorkerConcurrency == 5 && settings.ProcessorTimeout == 600*time.Millisecond
+	}
+
+	// The other instance applies the change it receives on the bus
+	deadline := time.Now().Add(5 * time.Second)
+	for !tuned(b.container.UpdateSettingsUC.Current()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected instance-b to apply the change, got %+v", b.container.UpdateSettingsUC.Current())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// A change older than the one in effect is ignored
+	stale := b.container.UpdateSettingsUC.Current()
+	stale.WorkerConcurrency = 2
+	if err := bus.Publish(context.Background(), domain.SettingsChange{Version: 1, Instance: "instance-old", After: stale}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if current := b.container.UpdateSettingsUC.Current(); !tuned(current) {
+		t.Errorf("Expected instance-b to ignore the stale change, got %+v", current)
+	}
+
+	// A restarted instance resumes with the live settings, not its configured ones
+	restarted := startInstance("instance-b")
+	if current := restarted.container.UpdateSettingsUC.Current(); !tuned(current) {
+		t.Errorf("Expected the restarted instance to restore the latest change, got %+v", current)
+	}
+}
+
+func TestE2EEffectiveConfigRequiresControlToken(t *testing.T) {
+	a := startTestApp(t)
+
+	if status := a.controlRequest(http.MethodGet, "/internal/config", "", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("Expected anonymous config request to be rejected with 401, got %d", status)
+	}
+	var effective map[string]interface{}
+	if status := a.controlRequest(http.MethodGet, "/internal/config", testControlToken, nil, &effective); status != http.StatusOK {
+		t.Fatalf("Expected config with control token to succeed, got %d", status)
+	}
+	if len(effective) == 0 {
+		t.Error("Expected the effective configuration, got an empty object")
+	}
+}
+
+func TestE2EExtraProcessorWhenOthersFail(t *testing.T) {
+	extra := newFakeProcessor(t)
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Processor.Processors = []config.ProcessorTarget{
+			{Name: "default", URL: dflt.server.URL, Priority: 0},
+			{Name: "fallback", URL: fallback.server.URL, Priority: 1},
+			{Name: "extra", URL: extra.server.URL, Priority: 2},
+		}
+	})
+	a.dflt.failing.Store(true)
+	a.fallback.failing.Store(true)
+
+	if status := a.postPayment("e2e-extra-1", 7.5); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for extra.received("e2e-extra-1") == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	var summary domain.PaymentsSummary
+	for time.Now().Before(deadline) {
+		if summary = a.summary(nil, nil); summary.Get("extra").TotalRequests == 1 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := summary.Get("extra"); got.TotalRequests != 1 || got.TotalAmount != 7.5 {
+		t.Errorf("Expected extra summary 1/7.5, got %+v", got)
+	}
+	if summary.Default.TotalRequests != 0 || summary.Fallback.TotalRequests != 0 {
+		t.Errorf("Expected no default or fallback payments, got %+v", summary)
+	}
+}
+
+func TestE2EQueueDeadLetterManagement(t *testing.T) {
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Server.WorkerConcurrency = 1
+		cfg.Processor.Timeout = 3 * time.Second
+	})
+	// Keep the only worker busy so payments pile up in the main queue
+	a.dflt.delay.Store(1000)
+
+	for i := range 5 {
+		if status := a.postPayment(fmt.Sprintf("e2e-queue-%d", i), 2); status != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d", status)
+		}
+	}
+
+	if status := a.controlRequest(http.MethodGet, "/internal/queue", "", nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("Expected unauthenticated queue stats to be rejected with 401, got %d", status)
+	}
+
+	var stats struct {
+		Queues []struct {
+			Queue string `json:"queue"`
+			Depth int    `json:"depth"`
+		} `json:"queues"`
+	}
+	if status := a.controlRequest(http.MethodGet, "/internal/queue", testControlToken, nil, &stats); status != http.StatusOK {
+		t.Fatalf("Expected queue stats, got %d", status)
+	}
+	if len(stats.Queues) != 2 || stats.Queues[0].Queue != "main" || stats.Queues[0].Depth == 0 {
+		t.Fatalf("Expected pending payments in the main queue, got %+v", stats.Queues)
+	}
+
+	// Park everything still pending in the dead-letter queue
+	var move struct {
+		Moved int `json:"moved"`
+	}
+	all := map[string]interface{}{"from": "main", "to": "dead"}
+	if status := a.controlRequest(http.MethodPost, "/internal/queue/move", testControlToken, all, &move); status != http.StatusOK {
+		t.Fatalf("Expected move to succeed, got %d", status)
+	}
+	if move.Moved == 0 {
+		t.Fatal("Expected payments to be moved to the dead-letter queue")
+	}
+	parked := move.Moved
+
+	var page struct {
+		Items []domain.QueuedPayment `json:"items"`
+	}
+	if status := a.controlRequest(http.MethodGet, "/internal/queue/dead/items?limit=10", testControlToken, nil, &page); status != http.StatusOK {
+		t.Fatalf("Expected dead-letter items, got %d", status)
+	}
+	if len(page.Items) != parked || page.Items[0].EnqueuedAt.IsZero() {
+		t.Fatalf("Expected %d dead-lettered payments with enqueue times, got %+v", parked, page.Items)
+	}
+
+	// Requeue one payment and drop the rest
+	a.dflt.delay.Store(0)
+	one := map[string]interface{}{"from": "dead", "to": "main", "correlationIds": []string{page.Items[0].CorrelationId}}
+	if status := a.controlRequest(http.MethodPost, "/internal/queue/move", testControlToken, one, &move); status != http.StatusOK || move.Moved != 1 {
+		t.Fatalf("Expected one payment to be requeued, got status %d moved %d", status, move.Moved)
+	}
+
+	var purge struct {
+		Purged int `json:"purged"`
+	}
+	if status := a.controlRequest(http.MethodDelete, "/internal/queue/dead", testControlToken, nil, &purge); status != http.StatusOK {
+		t.Fatalf("Expected purge to succeed, got %d", status)
+	}
+	if purge.Purged != parked-1 {
+		t.Errorf("Expected %d purged payments, got %d", parked-1, purge.Purged)
+	}
+
+	a.waitForSummary(5-parked+1, 0)
+	if a.dflt.received(page.Items[0].CorrelationId) != 1 {
+		t.Errorf("Expected the requeued payment to be processed")
+	}
+
+	// A purged payment is forgotten, so the client may submit it again
+	if parked > 1 {
+		if status := a.postPayment(page.Items[1].CorrelationId, 2); status != http.StatusAccepted {
+			t.Fatalf("Expected a purged payment to be accepted again, got %d", status)
+		}
+		a.waitForSummary(5-parked+2, 0)
+	}
+}
+
+func TestE2EDeadLetterAfterMaxAttempts(t *testing.T) {
+	var mu sync.Mutex
+	var failed []domain.PaymentEvent
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event domain.PaymentEvent
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		failed = append(failed, event)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Processor.MaxAttempts = 3
+		cfg.Adapters.Webhooks = "memory"
+		cfg.Webhooks = config.WebhookConfig{
+			Enabled:     true,
+			Secret:      "test-webhook-secret-0123456789",
+			URLs:        map[string]string{"": receiver.URL},
+			MaxAttempts: 1,
+			Backoff:     20 * time.Millisecond,
+			MaxBackoff:  100 * time.Millisecond,
+			Timeout:     time.Second,
+			Retention:   time.Hour,
+		}
+	})
+	a.dflt.failing.Store(true)
+	a.fallback.failing.Store(true)
+
+	if status := a.postPayment("e2e-exhausted-1", 9); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+
+	// After the last attempt the payment is dead-lettered and reported as failed
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(failed)
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the payment.failed webhook")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	mu.Lock()
+	event := failed[0]
+	mu.Unlock()
+	if event.Type != domain.PaymentFailed || event.Payment.Attempts != 3 || !strings.Contains(event.Reason, "dead-letter") {
+		t.Errorf("Expected a failure after 3 attempts, got %+v", event)
+	}
+
+	items, err := a.container.ManageQueueUC.List(domain.DeadLetterQueue, 0, 10)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(items) != 1 || items[0].CorrelationId != "e2e-exhausted-1" {
+		t.Errorf("Expected the payment in the dead-letter queue, got %+v", items)
+	}
+	if main, _ := a.container.ManageQueueUC.List(domain.MainQueue, 0, 10); len(main) != 0 {
+		t.Errorf("Expected nothing left to retry, got %+v", main)
+	}
+
+	if status, _, err := a.container.AuditPaymentsUC.Status(context.Background(), "e2e-exhausted-1"); err != nil || status != domain.PaymentDeadLettered {
+		t.Errorf("Status() = %q, %v, want %q", status, err, domain.PaymentDeadLettered)
+	}
+
+	// Purging the dead-letter queue reports the payment as failed again
+	if purged, err := a.container.ManageQueueUC.Purge(domain.DeadLetterQueue, "tester"); err != nil || purged != 1 {
+		t.Fatalf("Purge() = %d, %v", purged, err)
+	}
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(failed)
+		mu.Unlock()
+		if n > 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the payment.failed webhook of the purge")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	mu.Lock()
+	event = failed[1]
+	mu.Unlock()
+	if event.Type != domain.PaymentFailed || !strings.Contains(event.Reason, "purged") {
+		t.Errorf("Expected a failure for the purge, got %+v", event)
+	}
+}
+
+func TestE2EPaymentsSummaryByCurrency(t *testing.T) {
+	a := startTestApp(t)
+
+	payments := []domain.Payment{
+		{CorrelationId: "e2e-currency-1", Amount: 10},
+		{CorrelationId: "e2e-currency-2", Amount: 5, Currency: "BRL"},
+		{CorrelationId: "e2e-currency-3", Amount: 7, Currency: "USD"},
+		{CorrelationId: "e2e-currency-4", Amount: 3, Currency: "USD"},
+		{CorrelationId: "e2e-currency-5", Amount: 1, Currency: "XYZ"},
+	}
+	for i, payment := range payments {
+		body, _ := json.Marshal(payment)
+		resp, err := http.Post(a.server.URL+"/payments", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST /payments failed: %v", err)
+		}
+		resp.Body.Close()
+
+		want := http.StatusAccepted
+		if i == len(payments)-1 {
+			want = http.StatusBadRequest
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("Payment %s returned %d, want %d", payment.CorrelationId, resp.StatusCode, want)
+		}
+	}
+
+	// Mixed currencies never add up to one number
+	summary := a.waitForSummary(2, 0)
+	deadline := time.Now().Add(5 * time.Second)
+	for summary.Default.ByCurrency["USD"].TotalRequests < 2 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		summary = a.summary(nil, nil)
+	}
+	if summary.Default.TotalAmount != 15 {
+		t.Errorf("Expected 15 in the default currency, got %v", summary.Default.TotalAmount)
+	}
+	usd := summary.Default.ByCurrency["USD"]
+	if usd.TotalRequests != 2 || usd.TotalAmount != 10 || len(summary.Default.ByCurrency) != 1 {
+		t.Errorf("Expected 2 USD payments of 10, got %+v", summary.Default.ByCurrency)
+	}
+
+	if got := a.dflt.currency("e2e-currency-3"); got != "USD" {
+		t.Errorf("Expected the processor to receive USD, got %q", got)
+	}
+	if got := a.dflt.currency("e2e-currency-1"); got != "" {
+		t.Errorf("Expected no currency for a payment without one, got %q", got)
+	}
+}
+
+func TestE2ERateLimitPerClient(t *testing.T) {
+	const apiKey = "test-api-key-0123456789"
+	const otherKey = "test-other-key-0123456789"
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Adapters.RateLimiter = "memory"
+		cfg.RateLimit = config.RateLimitConfig{
+			Rate:   0.01,
+			Burst:  2,
+			Quotas: map[string]config.RateLimitQuota{"acme": {Rate: 0.01, Burst: 3}},
+		}
+		cfg.Adapters.Auth = "memory"
+		cfg.Auth = config.AuthConfig{
+			Enabled:            true,
+			APIKeys:            []config.APIKeyConfig{{Client: "acme", Key: apiKey}, {Client: "other", Key: otherKey}},
+			Signing:            "off",
+			SignatureTolerance: time.Minute,
+		}
+	})
+
+	post := func(id, key string) *http.Response {
+		body, _ := json.Marshal(domain.Payment{CorrelationId: id, Amount: 1})
+		req, _ := http.NewRequest(http.MethodPost, a.server.URL+"/payments", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /payments failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// Clients without a quota of their own get the default bucket of 2
+	for i := range 3 {
+		resp := post(fmt.Sprintf("e2e-ratelimit-other-%d", i), otherKey)
+		want := http.StatusAccepted
+		if i == 2 {
+			want = http.StatusTooManyRequests
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("Default quota request %d returned %d, want %d", i, resp.StatusCode, want)
+		}
+		if i == 0 && (resp.Header.Get("RateLimit-Limit") != "2" || resp.Header.Get("RateLimit-Remaining") != "1") {
+			t.Errorf("Expected RateLimit-Limit 2 and Remaining 1, got %q and %q",
+				resp.Header.Get("RateLimit-Limit"), resp.Header.Get("RateLimit-Remaining"))
+		}
+		if want == http.StatusTooManyRequests && resp.Header.Get("Retry-After") == "" {
+			t.Error("Expected a Retry-After header on 429")
+		}
+	}
+
+	// acme has its own bucket and quota
+	for i := range 4 {
+		resp := post(fmt.Sprintf("e2e-ratelimit-acme-%d", i), apiKey)
+		want := http.StatusAccepted
+		if i == 3 {
+			want = http.StatusTooManyRequests
+		}
+		if resp.StatusCode != want {
+			t.Fatalf("Client request %d returned %d, want %d", i, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestE2EAPIKeyAuthAndSigning(t *testing.T) {
+	const apiKey = "test-api-key-0123456789"
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Adapters.Auth = "memory"
+		cfg.Auth = config.AuthConfig{
+			Enabled:            true,
+			APIKeys:            []config.APIKeyConfig{{Client: "acme", Key: apiKey}},
+			Signing:            "optional",
+			SignatureTolerance: time.Minute,
+		}
+	})
+
+	send := func(key, signature string, body []byte) int {
+		req, _ := http.NewRequest(http.MethodPost, a.server.URL+"/payments", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		if signature != "" {
+			req.Header.Set("X-Signature", signature)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /payments failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	body := func(id string) []byte {
+		data, _ := json.Marshal(domain.Payment{CorrelationId: id, Amount: 1})
+		return data
+	}
+
+	if status := a.postPayment("e2e-auth-anonymous", 1); status != http.StatusUnauthorized {
+		t.Fatalf("Expected anonymous payment to be rejected with 401, got %d", status)
+	}
+	if status := send(apiKey, "", body("e2e-auth-plain")); status != http.StatusAccepted {
+		t.Fatalf("Expected payment with API key to be accepted, got %d", status)
+	}
+
+	signed := body("e2e-auth-signed")
+	signature := domain.FormatRequestSignature(apiKey, time.Now(), http.MethodPost, "/payments", signed)
+	if status := send(apiKey, signature, signed); status != http.StatusAccepted {
+		t.Fatalf("Expected signed payment to be accepted, got %d", status)
+	}
+	if status := send(apiKey, signature, signed); status != http.StatusUnauthorized {
+		t.Errorf("Expected replayed signature to be rejected with 401, got %d", status)
+	}
+	if status := send(apiKey, signature, body("e2e-auth-tampered")); status != http.StatusUnauthorized {
+		t.Errorf("Expected signature over another body to be rejected with 401, got %d", status)
+	}
+	stale := body("e2e-auth-stale")
+	old := domain.FormatRequestSignature(apiKey, time.Now().Add(-2*time.Minute), http.MethodPost, "/payments", stale)
+	if status := send(apiKey, old, stale); status != http.StatusUnauthorized {
+		t.Errorf("Expected stale signature to be rejected with 401, got %d", status)
+	}
+
+	// Rotating without grace retires the configured key at once
+	var rotated struct {
+		Key    string        `json:"key"`
+		APIKey domain.APIKey `json:"apiKey"`
+	}
+	rotation := map[string]string{"client": "acme", "grace": "0s"}
+	if status := a.controlRequest(http.MethodPost, "/internal/api-keys/rotate", testControlToken, rotation, &rotated); status != http.StatusOK {
+		t.Fatalf("Expected rotation to succeed, got %d", status)
+	}
+	if rotated.Key == "" || rotated.APIKey.Client != "acme" || rotated.APIKey.ID != domain.APIKeyID(rotated.Key) {
+		t.Fatalf("Unexpected rotation response %+v", rotated)
+	}
+	if status := send(apiKey, "", body("e2e-auth-retired")); status != http.StatusUnauthorized {
+		t.Errorf("Expected retired key to be rejected with 401, got %d", status)
+	}
+	if status := send(rotated.Key, "", body("e2e-auth-rotated")); status != http.StatusAccepted {
+		t.Errorf("Expected rotated key to be accepted, got %d", status)
+	}
+
+	// The summary is protected by the same keys
+	resp, err := http.Get(a.server.URL + "/payments-summary")
+	if err != nil {
+		t.Fatalf("GET /payments-summary failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected anonymous summary to be rejected with 401, got %d", resp.StatusCode)
+	}
+	req, _ := http.NewRequest(http.MethodGet, a.server.URL+"/payments-summary", nil)
+	req.Header.Set("X-API-Key", rotated.Key)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatalf("GET /payments-summary failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected summary with API key to succeed, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2EWebhookDeliveryAndRedelivery(t *testing.T) {
+	const secret = "test-webhook-secret-0123456789"
+	const apiKey = "acme-key-0123456789abcdef"
+
+	// The receiver rejects the first attempt of every event
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	events := map[string]domain.PaymentEvent{} // by correlation ID, last accepted
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature, err := domain.ParseRequestSignature(r.Header.Get("X-Webhook-Signature"))
+		if err != nil || !hmac.Equal(signature.MAC, domain.SignWebhook(secret, signature.Timestamp, body)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		var event domain.PaymentEvent
+		_ = json.Unmarshal(body, &event)
+
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[event.ID]++
+		if attempts[event.ID] == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		events[event.Payment.CorrelationId] = event
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+	received := func(correlationID string) (domain.PaymentEvent, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		event, ok := events[correlationID]
+		return event, ok
+	}
+	waitFor := func(correlationID string) domain.PaymentEvent {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if event, ok := received(correlationID); ok {
+				return event
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("Timed out waiting for the webhook of %s", correlationID)
+		return domain.PaymentEvent{}
+	}
+
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Server.WorkerConcurrency = 1
+		cfg.Processor.Timeout = 3 * time.Second
+		cfg.Adapters.Auth = "memory"
+		cfg.Auth = config.AuthConfig{
+			Enabled:            true,
+			APIKeys:            []config.APIKeyConfig{{Client: "acme", Key: apiKey}},
+			Signing:            "off",
+			SignatureTolerance: time.Minute,
+		}
+		cfg.Adapters.Webhooks = "memory"
+		cfg.Webhooks = config.WebhookConfig{
+			Enabled:       true,
+			Secret:        secret,
+			CallbackHosts: map[string][]string{"acme": {"127.0.0.1"}},
+			// The receiver listens on loopback
+			AllowPrivateCallbacks: true,
+			MaxAttempts:           3,
+			Backoff:               20 * time.Millisecond,
+			MaxBackoff:            100 * time.Millisecond,
+			Timeout:               time.Second,
+			Retention:             time.Hour,
+		}
+	})
+	// Keep the only worker busy so the later payments stay queued
+	a.dflt.delay.Store(500)
+
+	post := func(correlationID, callbackURL string) int {
+		body, _ := json.Marshal(domain.Payment{CorrelationId: correlationID, Amount: 5, CallbackURL: callbackURL})
+		req, _ := http.NewRequest(http.MethodPost, a.server.URL+"/payments", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", apiKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /payments failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Only the hosts allowed for the client may receive its webhooks
+	if status := post("e2e-webhook-internal", "http://169.254.169.254/latest/meta-data"); status != http.StatusBadRequest {
+		t.Fatalf("Expected a callback to a host that is not allowed to be rejected with 400, got %d", status)
+	}
+	for i := range 5 {
+		if status := post(fmt.Sprintf("e2e-webhook-%d", i), receiver.URL+"/hooks"); status != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d", status)
+		}
+	}
+
+	var move struct {
+		Moved int `json:"moved"`
+	}
+	all := map[string]interface{}{"from": "main", "to": "dead"}
+	if status := a.controlRequest(http.MethodPost, "/internal/queue/move", testControlToken, all, &move); status != http.StatusOK || move.Moved == 0 {
+		t.Fatalf("Expected queued payments to be dead-lettered, got status %d moved %d", status, move.Moved)
+	}
+
+	completed := waitFor("e2e-webhook-0")
+	if completed.Type != domain.PaymentCompleted || completed.Channel != domain.DefaultProcessor {
+		t.Errorf("Expected a completed event from the default processor, got %+v", completed)
+	}
+	failed := waitFor("e2e-webhook-4")
+	if failed.Type != domain.PaymentFailed || !strings.Contains(failed.Reason, "tester") {
+		t.Errorf("Expected a failed event naming the operator, got %+v", failed)
+	}
+
+	var delivery domain.WebhookDelivery
+	if status := a.controlRequest(http.MethodGet, "/internal/webhooks/"+completed.ID, testControlToken, nil, &delivery); status != http.StatusOK {
+		t.Fatalf("Expected the delivery to be logged, got %d", status)
+	}
+	if delivery.Status != domain.WebhookDelivered || delivery.Attempts != 2 {
+		t.Errorf("Expected delivery on the second attempt, got %s after %d", delivery.Status, delivery.Attempts)
+	}
+
+	var list struct {
+		Deliveries []domain.WebhookDelivery `json:"deliveries"`
+	}
+	if status := a.controlRequest(http.MethodGet, "/internal/webhooks?limit=10", testControlToken, nil, &list); status != http.StatusOK {
+		t.Fatalf("Expected the delivery log, got %d", status)
+	}
+	// The payment held by the worker may still be processing
+	if len(list.Deliveries) < 2 {
+		t.Errorf("Expected at least 2 deliveries, got %d", len(list.Deliveries))
+	}
+
+	// A redelivery posts the same event again
+	if status := a.controlRequest(http.MethodPost, "/internal/webhooks/"+completed.ID+"/redeliver", testControlToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("Expected redelivery to be scheduled, got %d", status)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := attempts[completed.ID]
+		mu.Unlock()
+		if n == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected a third attempt after redelivery, got %d", n)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if status := a.controlRequest(http.MethodGet, "/internal/webhooks/unknown", testControlToken, nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown delivery, got %d", status)
+	}
+}
+
+func TestWebhookClientRestrictsCallerChosenURLs(t *testing.T) {
+	var received int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+
+	client := http_client.NewWebhookClient(time.Second, false)
+	event := domain.PaymentEvent{ID: "event-1", Type: domain.PaymentCompleted}
+	delivery := domain.NewWebhookDelivery(event, receiver.URL, time.Now())
+
+	// Configured URLs may point anywhere, including loopback
+	if status, err := client.Send(context.Background(), delivery, []byte("{}"), "t=1,v1=00"); err != nil || status != http.StatusNoContent {
+		t.Fatalf("Expected the configured URL to be delivered, got status %d: %v", status, err)
+	}
+
+	// Caller-chosen URLs must resolve to public addresses
+	delivery.Restricted = true
+	if _, err := client.Send(context.Background(), delivery, []byte("{}"), "t=1,v1=00"); err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("Expected the restricted delivery to loopback to be refused, got %v", err)
+	}
+	if received != 1 {
+		t.Errorf("Expected exactly one request at the receiver, got %d", received)
+	}
+}
+
+// sseEvent is one Server-Sent Event read from a stream
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// openStream reads GET /payments/stream until ctx is done, authenticated
+// with apiKey unless it is empty
+func openStream(t *testing.T, ctx context.Context, streamURL, apiKey string) <-chan sseEvent {
+	t.Helper()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /payments/stream failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		resp.Body.Close()
+		t.Fatalf("Expected an event stream, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	events := make(chan sseEvent, 64)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		var event sseEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event.Event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			case line == "":
+				events <- event
+				event = sseEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func TestE2EPaymentEventStream(t *testing.T) {
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Adapters.EventStream = "memory"
+		cfg.EventStream = config.EventStreamConfig{Enabled: true, SnapshotInterval: time.Second}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	all := openStream(t, ctx, a.server.URL+"/payments/stream", "")
+	fallbackOnly := openStream(t, ctx, a.server.URL+"/payments/stream?channel=fallback", "")
+
+	next := func(events <-chan sseEvent) sseEvent {
+		t.Helper()
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatal("Stream closed unexpectedly")
+			}
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a stream event")
+			return sseEvent{}
+		}
+	}
+
+	// Both streams open with a summary snapshot
+	if event := next(all); event.Event != "summary" || !strings.Contains(event.Data, `"default"`) {
+		t.Fatalf("Expected an initial summary, got %+v", event)
+	}
+	if event := next(fallbackOnly); event.Event != "summary" || strings.Contains(event.Data, `"default"`) {
+		t.Fatalf("Expected an initial summary of the fallback channel only, got %+v", event)
+	}
+
+	if status := a.postPayment("e2e-stream-1", 12.5); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+
+	// The worker may complete the payment before the API reports it accepted
+	seen := map[string]domain.PaymentEvent{}
+	for len(seen) < 2 {
+		event := next(all)
+		if event.Event == "summary" {
+			continue
+		}
+		var payment domain.PaymentEvent
+		if err := json.Unmarshal([]byte(event.Data), &payment); err != nil {
+			t.Fatalf("Failed to decode event %+v: %v", event, err)
+		}
+		if payment.Payment.CorrelationId != "e2e-stream-1" || string(payment.Type) != event.Event {
+			t.Fatalf("Unexpected event %+v", event)
+		}
+		seen[event.Event] = payment
+	}
+	if _, ok := seen[string(domain.PaymentAccepted)]; !ok {
+		t.Errorf("Expected a payment.accepted event, got %v", seen)
+	}
+	if completed := seen[string(domain.PaymentCompleted)]; completed.Channel != domain.DefaultProcessor {
+		t.Errorf("Expected a payment.completed event from the default processor, got %+v", completed)
+	}
+
+	// Snapshots keep coming and report the processed payment
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if event := next(all); event.Event == "summary" && strings.Contains(event.Data, `"totalRequests":1`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for a snapshot with the processed payment")
+		}
+	}
+
+	// The filtered stream only got snapshots
+	if event := next(fallbackOnly); event.Event != "summary" {
+		t.Errorf("Expected the fallback stream to skip default events, got %+v", event)
+	}
+}
+
+func TestE2EPaymentEventStreamPerClient(t *testing.T) {
+	const acmeKey = "acme-key-0123456789abcdef"
+	const otherKey = "other-key-0123456789abcdef"
+
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Adapters.Auth = "memory"
+		cfg.Auth = config.AuthConfig{
+			Enabled:            true,
+			APIKeys:            []config.APIKeyConfig{{Client: "acme", Key: acmeKey}, {Client: "other", Key: otherKey}},
+			Signing:            "off",
+			SignatureTolerance: time.Minute,
+		}
+		cfg.Webhooks.CallbackHosts = map[string][]string{"acme": {"hooks.acme.example"}}
+		cfg.Adapters.EventStream = "memory"
+		cfg.EventStream = config.EventStreamConfig{Enabled: true, SnapshotInterval: time.Hour}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	acme := openStream(t, ctx, a.server.URL+"/payments/stream", acmeKey)
+	other := openStream(t, ctx, a.server.URL+"/payments/stream", otherKey)
+
+	body, _ := json.Marshal(domain.Payment{CorrelationId: "e2e-stream-acme", Amount: 3, CallbackURL: "https://hooks.acme.example/payments"})
+	req, _ := http.NewRequest(http.MethodPost, a.server.URL+"/payments", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", acmeKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /payments failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", resp.StatusCode)
+	}
+
+	// Read the acme stream until the payment completed
+	for completed := false; !completed; {
+		select {
+		case event := <-acme:
+			if event.Event == "summary" {
+				t.Fatalf("Expected no summaries on a client stream, got %+v", event)
+			}
+			var payment domain.PaymentEvent
+			if err := json.Unmarshal([]byte(event.Data), &payment); err != nil {
+				t.Fatalf("Failed to decode event %+v: %v", event, err)
+			}
+			if payment.Payment.CorrelationId != "e2e-stream-acme" || payment.Payment.CallbackURL != "" {
+				t.Errorf("Expected the acme payment without its callback URL, got %+v", payment.Payment)
+			}
+			completed = payment.Type == domain.PaymentCompleted
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the events of the acme payment")
+		}
+	}
+
+	// The other client never sees it
+	select {
+	case event := <-other:
+		t.Errorf("Expected no events for the other client, got %+v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestE2EPaymentBatch(t *testing.T) {
+	a := startTestApp(t)
+
+	if status := a.postPayment("e2e-batch-existing", 1); status != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", status)
+	}
+
+	postBatch := func(contentType, body string) (int, []domain.BatchItemResult) {
+		t.Helper()
+		resp, err := http.Post(a.server.URL+"/payments/batch", contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST /payments/batch failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var out struct {
+			Results []domain.BatchItemResult `json:"results"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out.Results
+	}
+
+	status, results := postBatch("application/json", `[
+		{"correlationId": "e2e-batch-1", "amount": 10},
+		{"correlationId": "e2e-batch-1", "amount": 10},
+		{"correlationId": "e2e-batch-2", "amount": 0},
+		"not a payment",
+		{"correlationId": "e2e-batch-existing", "amount": 1},
+		{"correlationId": "e2e-batch-3", "amount": 5.5}
+	]`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	want := []domain.BatchItemStatus{
+		domain.BatchItemAccepted, domain.BatchItemDuplicate, domain.BatchItemInvalid,
+		domain.BatchItemInvalid, domain.BatchItemDuplicate, domain.BatchItemAccepted,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %+v", len(want), results)
+	}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("Item %d: expected %s, got %+v", i, want[i], result)
+		}
+	}
+	if results[2].Error == "" || results[3].Error == "" {
+		t.Errorf("Expected invalid items to carry an error, got %+v", results)
+	}
+
+	// NDJSON streams are accepted too
+	status, results = postBatch("application/x-ndjson",
+		"{\"correlationId\": \"e2e-batch-4\", \"amount\": 2}\n{\"correlationId\": \"e2e-batch-5\", \"amount\": 3}\n")
+	if status != http.StatusOK || len(results) != 2 ||
+		results[0].Status != domain.BatchItemAccepted || results[1].Status != domain.BatchItemAccepted {
+		t.Fatalf("Expected both NDJSON payments accepted, got %d %+v", status, results)
+	}
+
+	if status, _ := postBatch("application/json", `[{"correlationId": "e2e-batch-6"`); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for malformed JSON, got %d", status)
+	}
+
+	summary := a.waitForSummary(5, 0)
+	if summary.Default.TotalAmount != 21.5 {
+		t.Errorf("Expected total amount 21.5, got %v", summary.Default.TotalAmount)
+	}
+}
+
+func TestE2EGRPCPayments(t *testing.T) {
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.GRPC = config.GRPCConfig{Enabled: true, Port: "127.0.0.1:0"}
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go a.container.GRPCServer.Serve(lis)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial gRPC server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := paymentsv1.NewPaymentServiceClient(conn)
+	ctx := context.Background()
+
+	submit := func(correlationID string, amount float64) codes.Code {
+		t.Helper()
+		_, err := client.SubmitPayment(ctx, &paymentsv1.SubmitPaymentRequest{
+			Payment: &paymentsv1.Payment{CorrelationId: correlationID, Amount: amount},
+		})
+		return status.Code(err)
+	}
+	if code := submit("e2e-grpc-1", 10); code != codes.OK {
+		t.Fatalf("Expected OK, got %s", code)
+	}
+	if code := submit("e2e-grpc-1", 10); code != codes.AlreadyExists {
+		t.Errorf("Expected ALREADY_EXISTS for a duplicate, got %s", code)
+	}
+	if code := submit("e2e-grpc-2", 0); code != codes.InvalidArgument {
+		t.Errorf("Expected INVALID_ARGUMENT for a zero amount, got %s", code)
+	}
+
+	batch, err := client.SubmitPayments(ctx, &paymentsv1.SubmitPaymentsRequest{Payments: []*paymentsv1.Payment{
+		{CorrelationId: "e2e-grpc-3", Amount: 5},
+		{CorrelationId: "e2e-grpc-1", Amount: 10},
+		{CorrelationId: "e2e-grpc-4", Amount: 2.5, Currency: "USD"},
+	}})
+	if err != nil {
+		t.Fatalf("SubmitPayments failed: %v", err)
+	}
+	want := []paymentsv1.PaymentResult_Status{
+		paymentsv1.PaymentResult_STATUS_ACCEPTED,
+		paymentsv1.PaymentResult_STATUS_DUPLICATE,
+		paymentsv1.PaymentResult_STATUS_ACCEPTED,
+	}
+	for i, result := range batch.GetResults() {
+		if result.GetStatus() != want[i] {
+			t.Errorf("Item %d: expected %s, got %s", i, want[i], result.GetStatus())
+		}
+	}
+
+	// The summary matches the HTTP one once the USD payment is processed too
+	a.waitForSummary(2, 0)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		payment, err := client.GetPaymentStatus(ctx, &paymentsv1.GetPaymentStatusRequest{CorrelationId: "e2e-grpc-4"})
+		if err != nil {
+			t.Fatalf("GetPaymentStatus failed: %v", err)
+		}
+		if payment.GetStatus() == paymentsv1.GetPaymentStatusResponse_STATUS_PROCESSED {
+			if payment.GetChannel() != "default" || payment.GetPayment().GetCurrency() != "USD" {
+				t.Errorf("Expected e2e-grpc-4 processed in USD by default, got %v", payment)
+			}
+			break
+		}
+		if payment.GetStatus() != paymentsv1.GetPaymentStatusResponse_STATUS_PENDING || time.Now().After(deadline) {
+			t.Fatalf("Expected e2e-grpc-4 to go from pending to processed, got %v", payment)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	summary, err := client.GetSummary(ctx, &paymentsv1.GetSummaryRequest{})
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+	dflt := summary.GetChannels()["default"]
+	if dflt.GetTotalRequests() != 2 || dflt.GetTotalAmount() != 15 || dflt.GetByCurrency()["USD"].GetTotalRequests() != 1 {
+		t.Errorf("Unexpected default summary %v", dflt)
+	}
+	future := timestamppb.New(time.Now().Add(time.Hour))
+	if summary, err := client.GetSummary(ctx, &paymentsv1.GetSummaryRequest{From: future}); err != nil ||
+		summary.GetChannels()["default"].GetTotalRequests() != 0 {
+		t.Errorf("Expected an empty summary from the future, got %v %v", summary, err)
+	}
+
+	if _, err := client.GetPaymentStatus(ctx, &paymentsv1.GetPaymentStatusRequest{CorrelationId: "e2e-grpc-unknown"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NOT_FOUND for an unknown payment, got %v", err)
+	}
+}
+
+func TestE2EPaymentsExport(t *testing.T) {
+	a := startTestApp(t)
+
+	amounts := []float64{10, 2.5, 7.25}
+	for i, amount := range amounts {
+		if status := a.postPayment(fmt.Sprintf("e2e-export-%d", i), amount); status != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d", status)
+		}
+	}
+	summary := a.waitForSummary(len(amounts), 0)
+
+	get := func(query string) (*http.Response, []byte) {
+		t.Helper()
+		resp, err := http.Get(a.server.URL + "/payments/export?" + query)
+		if err != nil {
+			t.Fatalf("GET /payments/export failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, body
+	}
+
+	// The CSV lines add up to the summary
+	resp, body := get("")
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/csv") {
+		t.Fatalf("Expected a CSV export, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(rows) != len(amounts)+1 || rows[0][0] != "correlationId" {
+		t.Fatalf("Expected a header and %d rows, got %v", len(amounts), rows)
+	}
+	var total float64
+	for _, row := range rows[1:] {
+		amount, _ := strconv.ParseFloat(row[2], 64)
+		total += amount
+		if row[1] != "default" || row[3] != "BRL" {
+			t.Errorf("Unexpected row %v", row)
+		}
+	}
+	if total != summary.Default.TotalAmount {
+		t.Errorf("Expected the rows to add up to %v, got %v", summary.Default.TotalAmount, total)
+	}
+
+	// NDJSON, filtered by range and channel
+	from := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	if resp, body := get("format=ndjson&channel=default&from=" + url.QueryEscape(from)); resp.StatusCode != http.StatusOK ||
+		strings.Count(string(body), "\n") != len(amounts) || !strings.Contains(string(body), `"correlationId":"e2e-export-1"`) {
+		t.Errorf("Expected %d NDJSON lines, got %d %s", len(amounts), resp.StatusCode, body)
+	}
+	if resp, body := get("format=ndjson&channel=fallback"); resp.StatusCode != http.StatusOK || len(body) != 0 {
+		t.Errorf("Expected an empty export of the fallback channel, got %d %s", resp.StatusCode, body)
+	}
+
+	if resp, _ := get("format=xml"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown format, got %d", resp.StatusCode)
+	}
+	if resp, _ := get("from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an inverted range, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2EPaymentsExportPerClient(t *testing.T) {
+	keys := map[string]string{"acme": "acme-key-0123456789abcdef", "other": "other-key-0123456789abcdef"}
+	a := startTestAppWith(t, func(cfg *config.Config, dflt, fallback *fakeProcessor) {
+		cfg.Adapters.Auth = "memory"
+		cfg.Auth = config.AuthConfig{
+			Enabled:            true,
+			APIKeys:            []config.APIKeyConfig{{Client: "acme", Key: keys["acme"]}, {Client: "other", Key: keys["other"]}},
+			Signing:            "off",
+			SignatureTolerance: time.Minute,
+		}
+	})
+
+	do := func(method, path, client string, body []byte) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, a.server.URL+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", keys[client])
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	for _, client := range []string{"acme", "other"} {
+		body, _ := json.Marshal(domain.Payment{CorrelationId: "e2e-export-" + client, Amount: 4})
+		if status, _ := do(http.MethodPost, "/payments", client, body); status != http.StatusAccepted {
+			t.Fatalf("Expected status 202 for %s, got %d", client, status)
+		}
+	}
+
+	// Each client exports only its own payment, once it is processed
+	for client := range keys {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			status, body := do(http.MethodGet, "/payments/export?format=ndjson", client, nil)
+			if status != http.StatusOK {
+				t.Fatalf("Expected the export of %s, got %d", client, status)
+			}
+			if lines := strings.Count(body, "\n"); lines > 0 {
+				if lines != 1 || !strings.Contains(body, `"correlationId":"e2e-export-`+client+`"`) {
+					t.Errorf("Expected only the payment of %s, got %s", client, body)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for the export of %s", client)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
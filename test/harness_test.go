@@ -0,0 +1,303 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/app"
+	"github.com/lmtani/rinha-de-backend-2025/internal/config"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// testControlToken authenticates the test operator on the control endpoints
+const testControlToken = "test-control-token-0123456789"
+
+// testProcessorToken is the fake processors' admin token
+const testProcessorToken = "test-processor-token"
+
+// fakeProcessor is a local stand-in for a payment processor
+type fakeProcessor struct {
+	server  *httptest.Server
+	failing atomic.Bool
+	delay   atomic.Int64
+
+	mu         sync.Mutex
+	payments   map[string]int
+	currencies map[string]domain.Currency
+}
+
+func newFakeProcessor(t *testing.T) *fakeProcessor {
+	t.Helper()
+
+	p := &fakeProcessor{payments: make(map[string]int), currencies: make(map[string]domain.Currency)}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/admin/") {
+			p.serveAdmin(w, r)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/payments" {
+			http.NotFound(w, r)
+			return
+		}
+		if delay := p.delay.Load(); delay > 0 {
+			time.Sleep(time.Duration(delay) * time.Millisecond)
+		}
+		if p.failing.Load() {
+			http.Error(w, "processor unavailable", http.StatusInternalServerError)
+			return
+		}
+
+		var payment domain.Payment
+		if err := json.NewDecoder(r.Body).Decode(&payment); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		p.mu.Lock()
+		p.payments[payment.CorrelationId]++
+		p.currencies[payment.CorrelationId] = payment.Currency
+		p.mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+// serveAdmin implements the processor admin endpoints used by the admin app
+func (p *fakeProcessor) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Rinha-Token") != testProcessorToken {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Delay   int64 `json:"delay"`
+		Failure bool  `json:"failure"`
+	}
+	switch r.Method + " " + r.URL.Path {
+	case "GET /admin/payments-summary":
+		p.mu.Lock()
+		total := 0
+		for _, n := range p.payments {
+			total += n
+		}
+		p.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"totalRequests": total, "totalAmount": 0})
+	case "PUT /admin/configurations/delay":
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		p.delay.Store(body.Delay)
+		w.WriteHeader(http.StatusNoContent)
+	case "PUT /admin/configurations/failure":
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		p.failing.Store(body.Failure)
+		w.WriteHeader(http.StatusNoContent)
+	case "POST /admin/purge-payments":
+		p.mu.Lock()
+		p.payments = make(map[string]int)
+		p.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "All payments purged."})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// received returns how many times the processor accepted the given payment
+func (p *fakeProcessor) received(correlationID string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.payments[correlationID]
+}
+
+// currency returns the currency the processor last received for a payment
+func (p *fakeProcessor) currency(correlationID string) domain.Currency {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.currencies[correlationID]
+}
+
+// testApp is the full application container running in-process
+type testApp struct {
+	t         *testing.T
+	container *app.Container
+	server    *httptest.Server
+	dflt      *fakeProcessor
+	fallback  *fakeProcessor
+}
+
+// startTestApp boots the container with in-memory adapters, serving HTTP on an
+// ephemeral port and talking to local fake processors
+func startTestApp(t *testing.T) *testApp {
+	t.Helper()
+	return startTestAppWith(t, nil)
+}
+
+// startTestAppWith is startTestApp with a hook to adjust the configuration
+// before the container is built, and container options
+func startTestAppWith(t *testing.T, configure func(cfg *config.Config, dflt, fallback *fakeProcessor), opts ...app.Option) *testApp {
+	t.Helper()
+
+	dflt := newFakeProcessor(t)
+	fallback := newFakeProcessor(t)
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			InstanceID:        "test-instance",
+			WorkerConcurrency: 2,
+		},
+		Processor: config.ProcessorConfig{
+			DefaultURL:      dflt.server.URL,
+			FallbackURL:     fallback.server.URL,
+			Timeout:         time.Second,
+			QueueBufferSize: 100,
+			CircuitBreaker: config.CircuitBreakerConfig{
+				MaxRequests:  1,
+				Interval:     time.Second,
+				Timeout:      100 * time.Millisecond,
+				FailureRatio: 0.5,
+				MinRequests:  3,
+			},
+		},
+		Adapters: config.AdaptersConfig{
+			Repository: "memory",
+			Queue:      "memory",
+			Store:      "memory",
+			Settings:   "memory",
+		},
+		Control: config.ControlConfig{
+			Tokens: map[string]string{"tester": testControlToken},
+		},
+	}
+
+	if configure != nil {
+		configure(cfg, dflt, fallback)
+	}
+
+	container, err := app.NewContainer(cfg, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create container: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := container.Start(ctx); err != nil {
+		t.Fatalf("Failed to start container: %v", err)
+	}
+
+	server := httptest.NewServer(container.HTTPServer.Handler())
+
+	t.Cleanup(func() {
+		server.Close()
+		cancel()
+		container.Stop()
+	})
+
+	return &testApp{
+		t:         t,
+		container: container,
+		server:    server,
+		dflt:      dflt,
+		fallback:  fallback,
+	}
+}
+
+// postPayment submits a payment and returns the response status code
+func (a *testApp) postPayment(correlationID string, amount float64) int {
+	a.t.Helper()
+
+	body, _ := json.Marshal(domain.Payment{CorrelationId: correlationID, Amount: amount})
+	resp, err := http.Post(a.server.URL+"/payments", "application/json", bytes.NewReader(body))
+	if err != nil {
+		a.t.Fatalf("POST /payments failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// summary fetches /payments-summary, optionally bounded by from and to
+func (a *testApp) summary(from, to *time.Time) domain.PaymentsSummary {
+	a.t.Helper()
+
+	query := url.Values{}
+	if from != nil {
+		query.Set("from", from.UTC().Format(time.RFC3339Nano))
+	}
+	if to != nil {
+		query.Set("to", to.UTC().Format(time.RFC3339Nano))
+	}
+
+	resp, err := http.Get(a.server.URL + "/payments-summary?" + query.Encode())
+	if err != nil {
+		a.t.Fatalf("GET /payments-summary failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		a.t.Fatalf("GET /payments-summary returned %d", resp.StatusCode)
+	}
+
+	var summary domain.PaymentsSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		a.t.Fatalf("Failed to decode summary: %v", err)
+	}
+	return summary
+}
+
+// waitForSummary polls the summary until it matches the expected request counts
+func (a *testApp) waitForSummary(wantDefault, wantFallback int) domain.PaymentsSummary {
+	a.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		summary := a.summary(nil, nil)
+		if summary.Default.TotalRequests == wantDefault && summary.Fallback.TotalRequests == wantFallback {
+			return summary
+		}
+		if time.Now().After(deadline) {
+			a.t.Fatalf("Timed out waiting for summary default=%d fallback=%d, got default=%d fallback=%d",
+				wantDefault, wantFallback, summary.Default.TotalRequests, summary.Fallback.TotalRequests)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// controlRequest sends an authenticated request to a control endpoint and decodes the JSON response
+func (a *testApp) controlRequest(method, path, token string, body interface{}, out interface{}) int {
+	a.t.Helper()
+
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+
+	req, err := http.NewRequest(method, a.server.URL+path, bytes.NewReader(data))
+	if err != nil {
+		a.t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		a.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			a.t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/loadgen"
+)
+
+func TestLoadgenParseStages(t *testing.T) {
+	got, err := loadgen.ParseStages(" 10s:50, 30s:500.5 ,,10s:0")
+	if err != nil {
+		t.Fatalf("ParseStages() error = %v", err)
+	}
+	want := []loadgen.Stage{
+		{Duration: 10 * time.Second, Target: 50},
+		{Duration: 30 * time.Second, Target: 500.5},
+		{Duration: 10 * time.Second, Target: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStages() = %+v, want %+v", got, want)
+	}
+	if total := loadgen.TotalDuration(got); total != 50*time.Second {
+		t.Errorf("TotalDuration() = %s, want 50s", total)
+	}
+
+	for _, spec := range []string{"", " , ", "10s", "10:50", "0s:50", "-5s:50", "10s:fast", "10s:-1"} {
+		if _, err := loadgen.ParseStages(spec); err == nil {
+			t.Errorf("Expected ParseStages(%q) to fail", spec)
+		}
+	}
+}
+
+func TestLoadgenParseEvent(t *testing.T) {
+	processors := []string{"default", "fallback", "extra"}
+	delay, failure := 800, true
+
+	tests := []struct {
+		spec    string
+		want    loadgen.ProcessorEvent
+		wantErr string
+	}{
+		{spec: "10s:default:failure=true", want: loadgen.ProcessorEvent{At: 10 * time.Second, Processor: "default", Failure: &failure}},
+		{spec: "0s:fallback:delay=800", want: loadgen.ProcessorEvent{At: 0, Processor: "fallback", Delay: &delay}},
+		{spec: "1m30s:extra:delay=800", want: loadgen.ProcessorEvent{At: 90 * time.Second, Processor: "extra", Delay: &delay}},
+		{spec: "10s:other:failure=true", wantErr: "unknown event processor"},
+		{spec: "10s:default", wantErr: "expected offset:processor:key=value"},
+		{spec: "soon:default:failure=true", wantErr: "invalid event offset"},
+		{spec: "-1s:default:failure=true", wantErr: "invalid event offset"},
+		{spec: "10s:default:failure", wantErr: "expected key=value"},
+		{spec: "10s:default:failure=maybe", wantErr: "invalid failure"},
+		{spec: "10s:default:delay=-5", wantErr: "invalid delay"},
+		{spec: "10s:default:purge=true", wantErr: "unknown event action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := loadgen.ParseEvent(tt.spec, processors)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error mentioning %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEvent() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEvent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadgenRateAt(t *testing.T) {
+	stages := []loadgen.Stage{
+		{Duration: 10 * time.Second, Target: 100},
+		{Duration: 20 * time.Second, Target: 100},
+		{Duration: 10 * time.Second, Target: 0},
+	}
+
+	tests := []struct {
+		elapsed  time.Duration
+		want     float64
+		finished bool
+	}{
+		// Ramps up from zero, holds, then ramps down
+		{elapsed: 0, want: 0},
+		{elapsed: 2500 * time.Millisecond, want: 25},
+		{elapsed: 10 * time.Second, want: 100},
+		{elapsed: 29 * time.Second, want: 100},
+		{elapsed: 35 * time.Second, want: 50},
+		{elapsed: 40 * time.Second, finished: true},
+		{elapsed: time.Hour, finished: true},
+	}
+
+	for _, tt := range tests {
+		rate, ok := loadgen.RateAt(stages, tt.elapsed)
+		if ok == tt.finished {
+			t.Errorf("RateAt(%s) running = %v, want %v", tt.elapsed, ok, !tt.finished)
+			continue
+		}
+		if math.Abs(rate-tt.want) > 1e-9 {
+			t.Errorf("RateAt(%s) = %v, want %v", tt.elapsed, rate, tt.want)
+		}
+	}
+}
+
+func TestLoadgenLatencyPercentiles(t *testing.T) {
+	if stats := loadgen.NewLatencyStats(nil); stats != (loadgen.LatencyStats{}) {
+		t.Errorf("Expected empty stats without latencies, got %+v", stats)
+	}
+
+	// 1ms to 100ms in reverse order
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(100-i) * time.Millisecond
+	}
+
+	want := loadgen.LatencyStats{
+		Count: 100,
+		Min:   time.Millisecond,
+		P50:   50 * time.Millisecond,
+		P90:   90 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}
+	if stats := loadgen.NewLatencyStats(latencies); stats != want {
+		t.Errorf("NewLatencyStats() = %+v, want %+v", stats, want)
+	}
+	if latencies[0] != 100*time.Millisecond {
+		t.Error("Expected NewLatencyStats() to leave its input unsorted")
+	}
+
+	// Nearest rank with a single sample
+	single := loadgen.NewLatencyStats([]time.Duration{7 * time.Millisecond})
+	if single.P50 != 7*time.Millisecond || single.P99 != 7*time.Millisecond {
+		t.Errorf("Expected every percentile of one sample to be it, got %+v", single)
+	}
+}
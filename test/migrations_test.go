@@ -0,0 +1,27 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/postgres_repository"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := postgres_repository.Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Migrations() returned nothing")
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d_%s has version %d, want %d", m.Version, m.Name, m.Version, i+1)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s needs both up and down SQL", m.Version, m.Name)
+		}
+	}
+}
@@ -15,12 +15,12 @@ func TestPaymentFlow(t *testing.T) {
 	queue := in_memory_repository.NewInMemoryQueue(10)
 	store := in_memory_repository.NewInMemoryStore()
 
-	requestUC := usecase.NewRequestPaymentUseCase(queue, store)
-	auditUC := usecase.NewAuditPaymentsUseCase(repository)
+	requestUC := usecase.NewRequestPaymentUseCase(queue, store, nil, nil)
+	auditUC := usecase.NewAuditPaymentsUseCase(repository, store, nil)
 
 	payment := domain.Payment{
 		CorrelationId: "test-123",
-		Amount:        "100.50",
+		Amount:        100.50,
 	}
 
 	ctx := context.Background()
@@ -42,7 +42,7 @@ func TestPaymentFlow(t *testing.T) {
 	}
 
 	// Add payment to repository for audit test
-	err = repository.Add(payment.CorrelationId, domain.DefaultProcessor, 100.50)
+	err = repository.Add(payment, domain.DefaultProcessor)
 	if err != nil {
 		t.Fatalf("Failed to add payment to repository: %v", err)
 	}
@@ -72,14 +72,14 @@ func TestPaymentValidation(t *testing.T) {
 			name: "valid payment",
 			payment: domain.Payment{
 				CorrelationId: "test-123",
-				Amount:        "100.50",
+				Amount:        100.50,
 			},
 			wantErr: false,
 		},
 		{
 			name: "missing correlation ID",
 			payment: domain.Payment{
-				Amount: "100.50",
+				Amount: 100.50,
 			},
 			wantErr: true,
 		},
@@ -91,18 +91,37 @@ func TestPaymentValidation(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "invalid amount",
+			name: "negative amount",
 			payment: domain.Payment{
 				CorrelationId: "test-123",
-				Amount:        "invalid",
+				Amount:        -100.50,
 			},
 			wantErr: true,
 		},
 		{
-			name: "negative amount",
+			name: "valid currency",
+			payment: domain.Payment{
+				CorrelationId: "test-123",
+				Amount:        100.50,
+				Currency:      "USD",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown currency",
+			payment: domain.Payment{
+				CorrelationId: "test-123",
+				Amount:        100.50,
+				Currency:      "usd",
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative callback URL",
 			payment: domain.Payment{
 				CorrelationId: "test-123",
-				Amount:        "-100.50",
+				Amount:        100.50,
+				CallbackURL:   "/hooks/payments",
 			},
 			wantErr: true,
 		},
@@ -0,0 +1,294 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/postgres_repository"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+// postgresQueueTest opens queues on the database of DATABASE_URL, with
+// correlation IDs of their own so runs do not see each other's payments
+type postgresQueueTest struct {
+	t      *testing.T
+	url    string
+	prefix string
+	pool   *pgxpool.Pool
+}
+
+func newPostgresQueueTest(t *testing.T) *postgresQueueTest {
+	t.Helper()
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	// Applies the migrations before the raw pool is used
+	repository, err := postgres_repository.NewPostgresRepository(databaseURL, true)
+	if err != nil {
+		t.Fatalf("NewPostgresRepository() error = %v", err)
+	}
+	repository.Close()
+
+	pool, err := pgxpool.New(context.Background(), databaseURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to the database: %v", err)
+	}
+	p := &postgresQueueTest{
+		t:      t,
+		url:    databaseURL,
+		prefix: fmt.Sprintf("pgqueue-%d-", time.Now().UnixNano()),
+		pool:   pool,
+	}
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), "DELETE FROM payment_queue WHERE correlation_id LIKE $1", p.prefix+"%")
+		pool.Exec(context.Background(), "DELETE FROM payments WHERE correlation_id LIKE $1", p.prefix+"%")
+		pool.Close()
+	})
+	return p
+}
+
+// queue opens the queue as consumer with a lease of a second
+func (p *postgresQueueTest) queue(consumer string) *postgres_repository.PostgresQueue {
+	p.t.Helper()
+
+	q, err := postgres_repository.NewPostgresQueue(p.url, false, p.prefix+consumer, time.Second)
+	if err != nil {
+		p.t.Fatalf("NewPostgresQueue() error = %v", err)
+	}
+	p.t.Cleanup(func() { q.Close() })
+	return q
+}
+
+// payment returns a payment with a correlation ID of this test
+func (p *postgresQueueTest) payment(name string) domain.Payment {
+	return domain.Payment{CorrelationId: p.prefix + name, Amount: 1}
+}
+
+// queued returns how many queue rows the payments have
+func (p *postgresQueueTest) queued(payments ...domain.Payment) int {
+	p.t.Helper()
+
+	ids := make([]string, len(payments))
+	for i, payment := range payments {
+		ids[i] = payment.CorrelationId
+	}
+	var count int
+	if err := p.pool.QueryRow(context.Background(),
+		"SELECT COUNT(*) FROM payment_queue WHERE correlation_id = ANY($1)", ids).Scan(&count); err != nil {
+		p.t.Fatalf("Failed to count queue rows: %v", err)
+	}
+	return count
+}
+
+// recorded reports whether the payment has a payment record
+func (p *postgresQueueTest) recorded(payment domain.Payment) bool {
+	p.t.Helper()
+
+	var count int
+	if err := p.pool.QueryRow(context.Background(),
+		"SELECT COUNT(*) FROM payments WHERE correlation_id = $1", payment.CorrelationId).Scan(&count); err != nil {
+		p.t.Fatalf("Failed to count payment records: %v", err)
+	}
+	return count > 0
+}
+
+// steal leases the row of a payment to another consumer
+func (p *postgresQueueTest) steal(payment domain.Payment) {
+	p.t.Helper()
+
+	if _, err := p.pool.Exec(context.Background(),
+		"UPDATE payment_queue SET leased_by = 'someone-else' WHERE correlation_id = $1", payment.CorrelationId); err != nil {
+		p.t.Fatalf("Failed to steal the lease: %v", err)
+	}
+}
+
+func TestPostgresQueueRedeliversAfterLeaseExpiry(t *testing.T) {
+	p := newPostgresQueueTest(t)
+	payment := p.payment("redeliver")
+
+	first := p.queue("first")
+	if err := first.Send(payment); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	receivePayment(t, first.Receive(), payment.CorrelationId, 5*time.Second)
+
+	// The first consumer dies without completing the payment, so its lease
+	// is no longer renewed
+	first.Close()
+
+	second := p.queue("second")
+	received := receivePayment(t, second.Receive(), payment.CorrelationId, 5*time.Second)
+	if err := second.Ack(received); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if queued := p.queued(payment); queued != 0 {
+		t.Errorf("Expected the row to be deleted after Ack, %d left", queued)
+	}
+}
+
+func TestPostgresQueueKeepsLeaseWhileInFlight(t *testing.T) {
+	p := newPostgresQueueTest(t)
+	payment := p.payment("slow")
+
+	first := p.queue("first")
+	if err := first.Send(payment); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	received := receivePayment(t, first.Receive(), payment.CorrelationId, 5*time.Second)
+
+	// Held for three leases, the payment is not handed to another consumer
+	second := p.queue("second")
+	payments := second.Receive()
+	deadline := time.After(3 * time.Second)
+	for waiting := true; waiting; {
+		select {
+		case other := <-payments:
+			if other.CorrelationId == payment.CorrelationId {
+				t.Fatal("Expected the renewed lease to keep the payment from another consumer")
+			}
+		case <-deadline:
+			waiting = false
+		}
+	}
+
+	if err := first.Ack(received); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+}
+
+func TestPostgresQueueAckReportsLostLease(t *testing.T) {
+	p := newPostgresQueueTest(t)
+	payment := p.payment("lost")
+
+	q := p.queue("only")
+	if err := q.Send(payment); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	received := receivePayment(t, q.Receive(), payment.CorrelationId, 5*time.Second)
+
+	p.steal(payment)
+	if err := q.Ack(received); err == nil || !strings.Contains(err.Error(), "lease") {
+		t.Errorf("Expected Ack() to report the lost lease, got %v", err)
+	}
+	if queued := p.queued(payment); queued != 1 {
+		t.Errorf("Expected the row of the new consumer to stay, got %d", queued)
+	}
+}
+
+func TestPostgresQueueRetriesOnTheSameRow(t *testing.T) {
+	p := newPostgresQueueTest(t)
+	payment := p.payment("retry")
+
+	q := p.queue("retrying")
+	if err := q.Send(payment); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	payments := q.Receive()
+
+	// Each failed attempt sends the payment again, then acknowledges it
+	for attempt := 1; attempt <= 2; attempt++ {
+		received := receivePayment(t, payments, payment.CorrelationId, 5*time.Second)
+		if err := q.Send(received); err != nil {
+			t.Fatalf("Send() of attempt %d error = %v", attempt, err)
+		}
+		if err := q.Ack(received); err != nil {
+			t.Fatalf("Ack() of attempt %d error = %v", attempt, err)
+		}
+	}
+	received := receivePayment(t, payments, payment.CorrelationId, 5*time.Second)
+
+	var rows, attempts int
+	if err := p.pool.QueryRow(context.Background(),
+		"SELECT COUNT(*), COALESCE(MAX(attempts), 0) FROM payment_queue WHERE correlation_id = $1",
+		payment.CorrelationId).Scan(&rows, &attempts); err != nil {
+		t.Fatalf("Failed to read the queue row: %v", err)
+	}
+	if rows != 1 || attempts != 3 {
+		t.Errorf("Expected one row claimed 3 times, got %d rows and %d attempts", rows, attempts)
+	}
+
+	if err := q.Ack(received); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if queued := p.queued(payment); queued != 0 {
+		t.Errorf("Expected the row to be deleted after Ack, %d left", queued)
+	}
+}
+
+func TestPostgresQueueSendBatchIsAtomic(t *testing.T) {
+	p := newPostgresQueueTest(t)
+	q := p.queue("batch")
+
+	// The correlation ID column holds 100 characters, so the last payment
+	// fails the insert of the whole batch
+	batch := []domain.Payment{p.payment("batch-1"), p.payment("batch-2"), p.payment(strings.Repeat("x", 100))}
+	if err := q.SendBatch(batch); err == nil {
+		t.Fatal("Expected SendBatch() to fail for a correlation ID that does not fit")
+	}
+	if queued := p.queued(batch...); queued != 0 {
+		t.Fatalf("Expected no row of the failed batch, got %d", queued)
+	}
+
+	batch = batch[:2]
+	if err := q.SendBatch(batch); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if queued := p.queued(batch...); queued != len(batch) {
+		t.Errorf("Expected %d rows, got %d", len(batch), queued)
+	}
+}
+
+func TestPostgresCompletingRepository(t *testing.T) {
+	p := newPostgresQueueTest(t)
+	q := p.queue("completing")
+	repository, err := postgres_repository.NewPostgresRepository(p.url, false)
+	if err != nil {
+		t.Fatalf("NewPostgresRepository() error = %v", err)
+	}
+	t.Cleanup(repository.Close)
+	completing := postgres_repository.NewCompletingRepository(repository, q)
+
+	completed, lost := p.payment("completed"), p.payment("lost")
+	if err := q.SendBatch([]domain.Payment{completed, lost}); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	payments := q.Receive()
+
+	// Recording the payment deletes its queue row in the same transaction
+	received := receivePayment(t, payments, completed.CorrelationId, 5*time.Second)
+	if err := completing.Add(received, domain.DefaultProcessor); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if !p.recorded(completed) {
+		t.Error("Expected the payment to be recorded")
+	}
+	if queued := p.queued(completed); queued != 0 {
+		t.Errorf("Expected the queue row to be deleted, %d left", queued)
+	}
+	// Ack after Add has nothing left to do
+	if err := q.Ack(received); err != nil {
+		t.Errorf("Ack() after Add error = %v", err)
+	}
+
+	// After a lost lease the payment is still recorded, and the row is left
+	// to the new consumer
+	received = receivePayment(t, payments, lost.CorrelationId, 5*time.Second)
+	p.steal(lost)
+	if err := completing.Add(received, domain.DefaultProcessor); err != nil {
+		t.Fatalf("Add() after the lease was lost error = %v", err)
+	}
+	if !p.recorded(lost) {
+		t.Error("Expected the payment to be recorded")
+	}
+	if queued := p.queued(lost); queued != 1 {
+		t.Errorf("Expected the queue row to stay, got %d", queued)
+	}
+}
@@ -0,0 +1,219 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/redis_repository"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// receivePayment waits for the payment with correlationID, skipping others
+// left in a shared queue by earlier runs
+func receivePayment(t *testing.T, payments <-chan domain.Payment, correlationID string, timeout time.Duration) domain.Payment {
+	t.Helper()
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case payment, ok := <-payments:
+			if !ok {
+				t.Fatalf("Queue closed while waiting for %s", correlationID)
+			}
+			if payment.CorrelationId == correlationID {
+				return payment
+			}
+		case <-deadline:
+			t.Fatalf("Timed out waiting for %s", correlationID)
+			return domain.Payment{}
+		}
+	}
+}
+
+// streamQueueTest holds a stream and group of its own on the Redis of REDIS_URL
+type streamQueueTest struct {
+	t      *testing.T
+	url    string
+	stream string
+	client *redis.Client
+}
+
+func newStreamQueueTest(t *testing.T) *streamQueueTest {
+	t.Helper()
+
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		t.Skip("REDIS_URL is not set")
+	}
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		t.Fatalf("Invalid REDIS_URL: %v", err)
+	}
+
+	s := &streamQueueTest{
+		t:      t,
+		url:    redisURL,
+		stream: fmt.Sprintf("test-payments-%d", time.Now().UnixNano()),
+		client: redis.NewClient(options),
+	}
+	t.Cleanup(func() {
+		s.client.Del(context.Background(), s.stream)
+		s.client.Close()
+	})
+	return s
+}
+
+// queue opens the stream as consumer
+func (s *streamQueueTest) queue(consumer string, claimIdle time.Duration, maxLen int64) *redis_repository.RedisStreamQueue {
+	s.t.Helper()
+
+	q, err := redis_repository.NewRedisStreamQueue(s.url, s.stream, "test-group", consumer, claimIdle, maxLen)
+	if err != nil {
+		s.t.Fatalf("NewRedisStreamQueue() error = %v", err)
+	}
+	s.t.Cleanup(func() { q.Close() })
+	return q
+}
+
+// pending returns how many messages of the group are not acknowledged
+func (s *streamQueueTest) pending() int64 {
+	s.t.Helper()
+
+	pending, err := s.client.XPending(context.Background(), s.stream, "test-group").Result()
+	if err != nil {
+		s.t.Fatalf("XPENDING failed: %v", err)
+	}
+	return pending.Count
+}
+
+func TestRedisStreamQueueRedeliversAfterClaimIdle(t *testing.T) {
+	s := newStreamQueueTest(t)
+
+	first := s.queue("first", time.Second, 1000)
+	if err := first.Send(domain.Payment{CorrelationId: "stream-redeliver", Amount: 1}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	receivePayment(t, first.Receive(), "stream-redeliver", 5*time.Second)
+
+	// The first consumer dies without acknowledging the payment
+	first.Close()
+	if pending := s.pending(); pending != 1 {
+		t.Fatalf("Expected 1 pending payment, got %d", pending)
+	}
+
+	second := s.queue("second", time.Second, 1000)
+	payment := receivePayment(t, second.Receive(), "stream-redeliver", 5*time.Second)
+	if err := second.Ack(payment); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if pending := s.pending(); pending != 0 {
+		t.Errorf("Expected no pending payments after Ack, got %d", pending)
+	}
+}
+
+func TestRedisStreamQueueSendBatchIsAtomic(t *testing.T) {
+	s := newStreamQueueTest(t)
+	q := s.queue("batch", time.Minute, 100000)
+
+	// Single payments are sent while the batch goes in
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ctx.Err() == nil; i++ {
+			_ = q.Send(domain.Payment{CorrelationId: fmt.Sprintf("stream-single-%d", i), Amount: 1})
+		}
+	}()
+
+	batch := make([]domain.Payment, 200)
+	for i := range batch {
+		batch[i] = domain.Payment{CorrelationId: fmt.Sprintf("stream-batch-%d", i), Amount: 1}
+	}
+	time.Sleep(20 * time.Millisecond)
+	err := q.SendBatch(batch)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+
+	messages, err := s.client.XRange(context.Background(), s.stream, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRANGE failed: %v", err)
+	}
+	var positions []int
+	for i, message := range messages {
+		raw, _ := message.Values["payment"].(string)
+		var payment domain.Payment
+		if err := json.Unmarshal([]byte(raw), &payment); err != nil {
+			t.Fatalf("Failed to decode message %s: %v", message.ID, err)
+		}
+		if strings.HasPrefix(payment.CorrelationId, "stream-batch-") {
+			positions = append(positions, i)
+		}
+	}
+
+	// The batch is stored in order with no other payment in between
+	if len(positions) != len(batch) {
+		t.Fatalf("Expected %d batch payments in the stream, got %d", len(batch), len(positions))
+	}
+	if positions[len(positions)-1]-positions[0] != len(batch)-1 {
+		t.Errorf("Expected the batch to be contiguous, found it between positions %d and %d", positions[0], positions[len(positions)-1])
+	}
+}
+
+func TestRedisStreamQueueTrimsOnlyAcknowledged(t *testing.T) {
+	s := newStreamQueueTest(t)
+	q := s.queue("trim", time.Second, 10)
+
+	const total, acked = 300, 250
+	batch := make([]domain.Payment, total)
+	for i := range batch {
+		batch[i] = domain.Payment{CorrelationId: fmt.Sprintf("stream-trim-%d", i), Amount: 1}
+	}
+	if err := q.SendBatch(batch); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	messages, err := s.client.XRange(context.Background(), s.stream, "-", "+").Result()
+	if err != nil || len(messages) != total {
+		t.Fatalf("Expected %d messages, got %d: %v", total, len(messages), err)
+	}
+
+	payments := q.Receive()
+	for i := range acked {
+		payment := receivePayment(t, payments, fmt.Sprintf("stream-trim-%d", i), 5*time.Second)
+		if err := q.Ack(payment); err != nil {
+			t.Fatalf("Ack() error = %v", err)
+		}
+	}
+
+	// Acknowledged entries go, while the unacknowledged and unread ones stay
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		length, err := s.client.XLen(context.Background(), s.stream).Result()
+		if err != nil {
+			t.Fatalf("XLEN failed: %v", err)
+		}
+		if length < total {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the stream to be trimmed, still %d entries", length)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	for _, message := range messages[acked:] {
+		if found, err := s.client.XRange(context.Background(), s.stream, message.ID, message.ID).Result(); err != nil || len(found) != 1 {
+			t.Fatalf("Expected unacknowledged entry %s to stay, got %v: %v", message.ID, found, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/in_memory_repository"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+	"github.com/lmtani/rinha-de-backend-2025/internal/usecase"
+)
+
+// flakyQueue fails every Send while failing is set
+type flakyQueue struct {
+	*in_memory_repository.InMemoryQueue
+	failing bool
+}
+
+func (q *flakyQueue) Send(payment domain.Payment) error {
+	if q.failing {
+		return errors.New("queue unavailable")
+	}
+	return q.InMemoryQueue.Send(payment)
+}
+
+func (q *flakyQueue) SendBatch(payments []domain.Payment) error {
+	if q.failing {
+		return errors.New("queue unavailable")
+	}
+	return q.InMemoryQueue.SendBatch(payments)
+}
+
+// failingStore cannot remove correlation IDs
+type failingStore struct {
+	*in_memory_repository.InMemoryStore
+}
+
+func (s failingStore) Remove(string) error {
+	return errors.New("store unavailable")
+}
+
+func TestRequestPaymentReleasesIDWhenQueueFails(t *testing.T) {
+	queue := &flakyQueue{InMemoryQueue: in_memory_repository.NewInMemoryQueue(10), failing: true}
+	store := in_memory_repository.NewInMemoryStore()
+	uc := usecase.NewRequestPaymentUseCase(queue, store, nil, nil)
+	payment := domain.Payment{CorrelationId: "accept-retry-1", Amount: 10}
+
+	if err := uc.Execute(context.Background(), payment); err == nil {
+		t.Fatal("Execute() with a failing queue succeeded, want error")
+	}
+	if store.Exists(payment.CorrelationId) {
+		t.Fatal("correlation ID is still stored after the queue failed")
+	}
+
+	// The client retry is accepted once the queue is back
+	queue.failing = false
+	if err := uc.Execute(context.Background(), payment); err != nil {
+		t.Fatalf("Execute() retry error = %v", err)
+	}
+	if err := uc.Execute(context.Background(), payment); err == nil {
+		t.Error("Execute() after a successful retry succeeded, want duplicate error")
+	}
+}
+
+func TestRequestPaymentBatchReleasesIDsWhenQueueFails(t *testing.T) {
+	queue := &flakyQueue{InMemoryQueue: in_memory_repository.NewInMemoryQueue(10), failing: true}
+	store := in_memory_repository.NewInMemoryStore()
+	uc := usecase.NewRequestPaymentUseCase(queue, store, nil, nil)
+	batch := []domain.Payment{{CorrelationId: "batch-retry-1", Amount: 10}, {CorrelationId: "batch-retry-2", Amount: 10}}
+
+	results, err := uc.ExecuteBatch(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("ExecuteBatch() error = %v", err)
+	}
+	for _, result := range results {
+		if result.Status != domain.BatchItemFailed {
+			t.Errorf("Expected %s to fail, got %s", result.CorrelationId, result.Status)
+		}
+		if store.Exists(result.CorrelationId) {
+			t.Errorf("correlation ID %s is still stored after the queue failed", result.CorrelationId)
+		}
+	}
+
+	// The client retry is accepted once the queue is back
+	queue.failing = false
+	results, err = uc.ExecuteBatch(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("ExecuteBatch() retry error = %v", err)
+	}
+	for _, result := range results {
+		if result.Status != domain.BatchItemAccepted {
+			t.Errorf("Expected %s to be accepted on retry, got %s", result.CorrelationId, result.Status)
+		}
+	}
+}
+
+func TestRequestPaymentRestrictsCallbackURLs(t *testing.T) {
+	store := in_memory_repository.NewInMemoryStore()
+	callbacks := domain.CallbackPolicy{"acme": {"hooks.acme.example"}}
+	uc := usecase.NewRequestPaymentUseCase(in_memory_repository.NewInMemoryQueue(10), store, nil, callbacks)
+
+	tests := []struct {
+		name     string
+		client   string
+		callback string
+		wantErr  bool
+	}{
+		{name: "anonymous", callback: "https://hooks.acme.example/payments", wantErr: true},
+		{name: "allowed host", client: "acme", callback: "https://HOOKS.acme.example:8443/payments"},
+		{name: "other host", client: "acme", callback: "http://127.0.0.1/payments", wantErr: true},
+		{name: "client without hosts", client: "other", callback: "https://hooks.acme.example/payments", wantErr: true},
+		{name: "no callback", client: "other"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := domain.Payment{
+				CorrelationId: fmt.Sprintf("callback-%d", i),
+				Amount:        10,
+				Client:        tt.client,
+				CallbackURL:   tt.callback,
+			}
+			err := uc.Execute(context.Background(), payment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && store.Exists(payment.CorrelationId) {
+				t.Error("rejected payment reserved its correlation ID")
+			}
+		})
+	}
+}
+
+func TestRequestPaymentReportsFailedRelease(t *testing.T) {
+	queue := &flakyQueue{InMemoryQueue: in_memory_repository.NewInMemoryQueue(10), failing: true}
+	uc := usecase.NewRequestPaymentUseCase(queue, failingStore{in_memory_repository.NewInMemoryStore()}, nil, nil)
+
+	err := uc.Execute(context.Background(), domain.Payment{CorrelationId: "accept-retry-2", Amount: 10})
+	if err == nil || err.Error() != "queue unavailable\nstore unavailable" {
+		t.Errorf("Execute() error = %v, want both the queue and the store errors", err)
+	}
+}
@@ -0,0 +1,187 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lmtani/rinha-de-backend-2025/internal/adapter/postgres_repository"
+	"github.com/lmtani/rinha-de-backend-2025/internal/domain"
+)
+
+func TestWholeSecondBounds(t *testing.T) {
+	at := func(value string) *time.Time {
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			t.Fatalf("bad timestamp %q: %v", value, err)
+		}
+		return &parsed
+	}
+
+	tests := []struct {
+		name          string
+		from, to      *time.Time
+		wantLo        *time.Time
+		wantHi        *time.Time
+		noWholeSecond bool
+	}{
+		{name: "unbounded"},
+		{
+			name:   "whole seconds",
+			from:   at("2025-07-10T12:00:00Z"),
+			to:     at("2025-07-10T12:00:05Z"),
+			wantLo: at("2025-07-10T12:00:00Z"),
+			// The last second only has its first microsecond in the range
+			wantHi: at("2025-07-10T12:00:05Z"),
+		},
+		{
+			name:   "partial edges",
+			from:   at("2025-07-10T12:00:00.250Z"),
+			to:     at("2025-07-10T12:00:05.750Z"),
+			wantLo: at("2025-07-10T12:00:01Z"),
+			wantHi: at("2025-07-10T12:00:05Z"),
+		},
+		{
+			name:   "to on the last microsecond of a second",
+			from:   at("2025-07-10T12:00:00Z"),
+			to:     at("2025-07-10T12:00:04.999999Z"),
+			wantLo: at("2025-07-10T12:00:00Z"),
+			wantHi: at("2025-07-10T12:00:05Z"),
+		},
+		{
+			name:   "nanoseconds are truncated to microseconds",
+			from:   at("2025-07-10T12:00:00.000000500Z"),
+			to:     at("2025-07-10T12:00:04.999999999Z"),
+			wantLo: at("2025-07-10T12:00:00Z"),
+			wantHi: at("2025-07-10T12:00:05Z"),
+		},
+		{
+			name:   "from in another zone",
+			from:   at("2025-07-10T09:00:00.5-03:00"),
+			wantLo: at("2025-07-10T12:00:01Z"),
+		},
+		{
+			name:   "only to",
+			to:     at("2025-07-10T12:00:05.5Z"),
+			wantHi: at("2025-07-10T12:00:05Z"),
+		},
+		{
+			name:          "inside one second",
+			from:          at("2025-07-10T12:00:00.100Z"),
+			to:            at("2025-07-10T12:00:00.900Z"),
+			wantLo:        at("2025-07-10T12:00:01Z"),
+			wantHi:        at("2025-07-10T12:00:00Z"),
+			noWholeSecond: true,
+		},
+		{
+			name:          "across one boundary",
+			from:          at("2025-07-10T12:00:00.900Z"),
+			to:            at("2025-07-10T12:00:01.100Z"),
+			wantLo:        at("2025-07-10T12:00:01Z"),
+			wantHi:        at("2025-07-10T12:00:01Z"),
+			noWholeSecond: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi := domain.WholeSecondBounds(tt.from, tt.to)
+			if !sameInstant(lo, tt.wantLo) || !sameInstant(hi, tt.wantHi) {
+				t.Fatalf("WholeSecondBounds() = %v, %v, want %v, %v", lo, hi, tt.wantLo, tt.wantHi)
+			}
+			if lo != nil && lo.Location() != time.UTC {
+				t.Errorf("Expected lo in UTC, got %v", lo.Location())
+			}
+			noWholeSecond := lo != nil && hi != nil && !lo.Before(*hi)
+			if noWholeSecond != tt.noWholeSecond {
+				t.Errorf("Expected no whole second to be %v, got %v", tt.noWholeSecond, noWholeSecond)
+			}
+		})
+	}
+}
+
+func sameInstant(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// TestPostgresSummaryMatchesRawPayments compares the rollup summary with the
+// raw payments over sub-second ranges. It needs DATABASE_URL.
+func TestPostgresSummaryMatchesRawPayments(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	repository, err := postgres_repository.NewPostgresRepository(databaseURL, true)
+	if err != nil {
+		t.Fatalf("NewPostgresRepository() error = %v", err)
+	}
+	t.Cleanup(repository.Close)
+	pool, err := pgxpool.New(context.Background(), databaseURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to Postgres: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	// Spread payments over a few seconds so ranges cross bucket boundaries
+	start := time.Now().UTC()
+	for i := range 12 {
+		payment := domain.Payment{CorrelationId: fmt.Sprintf("rollup-%d-%d", start.UnixNano(), i), Amount: float64(i) + 0.25}
+		if err := repository.Add(payment, domain.DefaultProcessor); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		time.Sleep(230 * time.Millisecond)
+	}
+	end := time.Now().UTC()
+
+	rows, _ := pool.Query(context.Background(),
+		"SELECT created_at FROM payments WHERE created_at >= $1 AND created_at <= $2 ORDER BY created_at", start, end)
+	recorded, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+	if err != nil || len(recorded) < 12 {
+		t.Fatalf("Expected the recorded payments, got %d: %v", len(recorded), err)
+	}
+
+	// Ranges starting and ending exactly on, just before and just after
+	// recorded payments, within one second and across several
+	var ranges [][2]time.Time
+	for i := 0; i+1 < len(recorded); i += 3 {
+		for _, j := range []int{i + 1, min(i+4, len(recorded)-1), len(recorded) - 1} {
+			from, to := recorded[i], recorded[j]
+			ranges = append(ranges,
+				[2]time.Time{from, to},
+				[2]time.Time{from.Add(time.Microsecond), to.Add(-time.Microsecond)},
+				[2]time.Time{from.Add(-time.Microsecond), to.Add(time.Microsecond)},
+			)
+		}
+	}
+	ranges = append(ranges, [2]time.Time{start.Truncate(time.Second), end.Truncate(time.Second).Add(time.Second)})
+
+	for _, r := range ranges {
+		from, to := r[0], r[1]
+		var wantRequests int
+		var wantAmount float64
+		if err := pool.QueryRow(context.Background(), `
+			SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM payments
+			WHERE channel = $1 AND currency = $2 AND created_at >= $3 AND created_at <= $4`,
+			domain.DefaultProcessor.String(), string(domain.DefaultCurrency), from, to).Scan(&wantRequests, &wantAmount); err != nil {
+			t.Fatalf("Failed to sum the raw payments: %v", err)
+		}
+
+		summary, err := repository.GetSummaryInRange(&from, &to)
+		if err != nil {
+			t.Fatalf("GetSummaryInRange() error = %v", err)
+		}
+		if got := summary.Default; got.TotalRequests != wantRequests || math.Abs(got.TotalAmount-wantAmount) >= 0.005 {
+			t.Errorf("Range %s to %s: summary %d/%.2f, raw payments %d/%.2f",
+				from.Format(time.RFC3339Nano), to.Format(time.RFC3339Nano), got.TotalRequests, got.TotalAmount, wantRequests, wantAmount)
+		}
+	}
+}